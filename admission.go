@@ -0,0 +1,66 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+	"strings"
+)
+
+// operatorCosts are the complexity costs of the operators that are more expensive than a plain comparison
+var operatorCosts = map[string]int{
+	"like":       3,
+	"ilike":      3,
+	"similar":    5,
+	"nearby":     5,
+	"withinbbox": 5,
+}
+
+// Complexity returns a score estimating how expensive the query is to execute, used by the admission hook
+// Every filter, nested group and sort field costs 1, pattern and spatial operators cost more, long IN lists cost 1 more
+// per 100 values, searched fields cost 3 each, and deep offsets cost 1 more per 1000 skipped rows
+func (q *BunQL) Complexity() int {
+	score := groupComplexity(q.Filters)
+	score += len(q.Sort)
+
+	if q.Search != nil {
+		score += 3 * len(q.Search.Fields)
+	}
+
+	if q.Cursor == nil && q.Pagination != nil {
+		score += q.Pagination.Offset() / 1000
+	}
+	return score
+}
+
+// groupComplexity returns the complexity score of a filter group and its nested groups
+func groupComplexity(group dto.FilterGroup) int {
+	score := 0
+	for _, filter := range group.Filters {
+		cost, ok := operatorCosts[strings.ToLower(filter.Operator)]
+		if !ok {
+			cost = 1
+		}
+		score += cost + len(flattenValue(filter.Value))/100
+	}
+
+	for _, nestedGroup := range group.Groups {
+		score += 1 + groupComplexity(nestedGroup)
+	}
+	return score
+}
+
+// WithAdmissionHook sets the function deciding whether the query may be executed, called by ExecutePage and
+// ExecuteCursorPage with the caller identity (see WithIdentity) and the complexity of the query
+// Returning an error rejects the query, e.g. to throttle heavy filters per API key; the error is returned as is
+func (q *BunQL) WithAdmissionHook(hook func(ctx context.Context, caller string, complexity int) error) *BunQL {
+	q.AdmissionHook = hook
+	return q
+}
+
+// admit asks the admission hook, if any, whether the query may be executed
+func (q *BunQL) admit(ctx context.Context) error {
+	if q.AdmissionHook == nil {
+		return nil
+	}
+	return q.AdmissionHook(ctx, IdentityFromContext(ctx), q.Complexity())
+}
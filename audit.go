@@ -0,0 +1,88 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+	"strings"
+)
+
+// identityKey is the context key of the caller identity
+type identityKey struct{}
+
+// AuditEntry is the record of a query passed to the audit hook
+type AuditEntry struct {
+	Identity   string          // Caller identity, as set with WithIdentity; empty if unknown
+	Filters    dto.FilterGroup // Normalized filters: lowercase logic and operators, sensitive values redacted
+	Sort       []dto.SortField // Normalized sort: lowercase directions
+	Pagination *dto.Pagination // Requested page, nil if not paginated
+	Rows       int             // Number of rows returned
+	Total      int             // Total number of matching rows, -1 if not counted (e.g. with cursor pagination)
+	Err        error           // Error that failed the query, if any; its message is redacted if sensitive values are filtered
+}
+
+// WithIdentity returns a context carrying the identity of the caller, recorded in the audit entries
+// It is typically called by the authentication middleware
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller identity set with WithIdentity, or an empty string
+func IdentityFromContext(ctx context.Context) string {
+	identity, _ := ctx.Value(identityKey{}).(string)
+	return identity
+}
+
+// WithAuditHook sets the function receiving an audit entry every time the query is executed
+// with ExecutePage or ExecuteCursorPage, so who queried what through list endpoints can be recorded
+func (q *BunQL) WithAuditHook(hook func(ctx context.Context, entry AuditEntry)) *BunQL {
+	q.AuditHook = hook
+	return q
+}
+
+// audit passes the audit entry of an execution to the audit hook, if any
+func (q *BunQL) audit(ctx context.Context, rows, total int, err error) {
+	if q.AuditHook == nil {
+		return
+	}
+
+	sortFields := make([]dto.SortField, len(q.Sort))
+	for i, sort := range q.Sort {
+		sortFields[i] = sort
+		sortFields[i].Direction = sort.Direction.Normalized()
+	}
+
+	var paging *dto.Pagination
+	if q.Pagination != nil {
+		p := *q.Pagination
+		paging = &p
+	}
+
+	q.AuditHook(ctx, AuditEntry{
+		Identity:   IdentityFromContext(ctx),
+		Filters:    normalizeFilterGroup(q.RedactedFilters()),
+		Sort:       sortFields,
+		Pagination: paging,
+		Rows:       rows,
+		Total:      total,
+		Err:        q.redactError(err),
+	})
+}
+
+// normalizeFilterGroup returns a copy of a filter group with lowercase logic and operators
+func normalizeFilterGroup(group dto.FilterGroup) dto.FilterGroup {
+	normalized := dto.FilterGroup{
+		Filters: make([]dto.Filter, len(group.Filters)),
+		Groups:  make([]dto.FilterGroup, len(group.Groups)),
+		Scope:   group.Scope,
+	}
+	normalized.Logic, _ = dto.ParseLogic(group.Logic)
+
+	for i, filter := range group.Filters {
+		normalized.Filters[i] = filter
+		normalized.Filters[i].Operator = strings.ToLower(filter.Operator)
+	}
+	for i, nestedGroup := range group.Groups {
+		normalized.Groups[i] = normalizeFilterGroup(nestedGroup)
+	}
+	return normalized
+}
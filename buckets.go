@@ -0,0 +1,287 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"time"
+)
+
+// BucketInterval is the width of the time buckets rows are counted in
+type BucketInterval string
+
+const (
+	BucketHour  BucketInterval = "hour"
+	BucketDay   BucketInterval = "day"
+	BucketWeek  BucketInterval = "week" // Weeks start on Monday
+	BucketMonth BucketInterval = "month"
+	BucketYear  BucketInterval = "year"
+)
+
+// MaxFilledBuckets is the number of buckets Fill returns at most, so that a wide range asked for by a client doesn't
+// allocate hundreds of thousands of hourly buckets
+const MaxFilledBuckets = 10000
+
+// ErrTooManyBuckets is returned by Fill when the range holds more than MaxFilledBuckets buckets
+var ErrTooManyBuckets = errors.New("too many buckets to fill")
+
+// bucketLayout is the layout of the bucket starts read from the database
+const bucketLayout = "2006-01-02T15:04:05"
+
+// TimeBucket groups rows by the hour, day, week, month or year of a time field, e.g. for "signups per day" charts
+// The field is configured by the server and must never come from user input
+type TimeBucket struct {
+	Field    string         // Time field the rows are grouped by, e.g. "created_at"
+	Interval BucketInterval // Width of the buckets
+	Timezone string         // IANA time zone the buckets start in, e.g. "Europe/Paris"; UTC if empty
+}
+
+// Bucket is the number of rows in a time bucket
+type Bucket struct {
+	Start time.Time `json:"start"` // Start of the bucket, in the time zone of the TimeBucket
+	Count int       `json:"count"`
+}
+
+// TimeSeries is the number of rows per time bucket, with the metadata needed to draw a chart of it
+// Buckets without rows are left out; Fill adds them with a zero count
+type TimeSeries struct {
+	Interval BucketInterval `json:"interval"`
+	Timezone string         `json:"timezone"`
+	From     time.Time      `json:"from"` // Start of the first bucket with rows
+	To       time.Time      `json:"to"`   // Start of the last bucket with rows
+	Buckets  []Bucket       `json:"buckets"`
+}
+
+// CountByTime counts the rows matched by the filters and search of the BunQL per time bucket, in a single grouped
+// query: date_trunc on Postgres, DATE_FORMAT on MySQL, strftime on SQLite and DATEADD on SQL Server. Rows whose field
+// is NULL are left out. Time zones other than UTC need Postgres, or MySQL with its time zone tables loaded
+func (q *BunQL) CountByTime(ctx context.Context, query *bun.SelectQuery, bucket TimeBucket) (*TimeSeries, error) {
+	location, err := bucket.location()
+	if err != nil {
+		return nil, err
+	}
+	expr, args, err := bucket.startExpr(q.dialectName(query))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := q.deadline(ctx)
+	defer cancel()
+
+	// Only the filters apply, through the middleware like for a count query
+	starts := q.chain(applyCount)(context.WithValue(ctx, countKey{}, true), q, query).
+		ColumnExpr(expr+" AS bucket_start", args...).
+		Where("? IS NOT NULL", bun.Ident(bucket.Field))
+
+	series := &TimeSeries{Interval: bucket.Interval, Timezone: location.String(), Buckets: []Bucket{}}
+	err = budgeted(ctx, func(ctx context.Context) error {
+		rows, err := q.onReplica(query.DB().NewSelect().Conn(query.GetConn())).
+			TableExpr("(?) AS buckets", starts).
+			ColumnExpr("bucket_start").
+			ColumnExpr("COUNT(*)").
+			GroupExpr("bucket_start").
+			OrderExpr("bucket_start ASC").
+			Rows(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count rows per %s: %w", bucket.Interval, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var start string
+			var count int
+			if err := rows.Scan(&start, &count); err != nil {
+				return fmt.Errorf("failed to read bucket: %w", err)
+			}
+			t, err := time.ParseInLocation(bucketLayout, start, location)
+			if err != nil {
+				return fmt.Errorf("failed to read bucket start '%s': %w", start, err)
+			}
+			series.Buckets = append(series.Buckets, Bucket{Start: t, Count: count})
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read buckets: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if n := len(series.Buckets); n > 0 {
+		series.From = series.Buckets[0].Start
+		series.To = series.Buckets[n-1].Start
+	}
+	return series, nil
+}
+
+// Fill returns the buckets of the series from the bucket of from to the bucket of to, adding the buckets without
+// rows with a zero count. A zero from or to stands for the first or last bucket with rows
+// It fails with ErrTooManyBuckets if the range holds more than MaxFilledBuckets buckets
+func (s *TimeSeries) Fill(from, to time.Time) ([]Bucket, error) {
+	bucket := TimeBucket{Interval: s.Interval}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	if from.IsZero() {
+		from = s.From
+	}
+	if to.IsZero() {
+		to = s.To
+	}
+	if from.IsZero() || to.IsZero() {
+		return []Bucket{}, nil
+	}
+
+	counts := make(map[int64]int, len(s.Buckets))
+	for _, b := range s.Buckets {
+		counts[b.Start.Unix()] = b.Count
+	}
+
+	filled := []Bucket{}
+	end := bucket.truncate(to.In(location))
+	for start := bucket.truncate(from.In(location)); !start.After(end); start = bucket.next(start) {
+		if len(filled) == MaxFilledBuckets {
+			return nil, fmt.Errorf("%w: more than %d %s buckets from %s to %s", ErrTooManyBuckets, MaxFilledBuckets,
+				s.Interval, from.Format(time.RFC3339), to.Format(time.RFC3339))
+		}
+		filled = append(filled, Bucket{Start: start, Count: counts[start.Unix()]})
+	}
+	return filled, nil
+}
+
+// location returns the time zone of the buckets
+func (b TimeBucket) location() (*time.Location, error) {
+	if b.Timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s': %w", b.Timezone, err)
+	}
+	return location, nil
+}
+
+// isUTC reports whether the buckets start in UTC
+func (b TimeBucket) isUTC() bool {
+	return b.Timezone == "" || b.Timezone == "UTC"
+}
+
+// startExpr returns the SQL expression of the start of the bucket of a row, formatted with bucketLayout
+func (b TimeBucket) startExpr(d dialect.Name) (string, []interface{}, error) {
+	field := bun.Ident(b.Field)
+
+	switch d {
+	case dialect.PG:
+		value := "?"
+		args := []interface{}{b.Interval, field}
+		if !b.isUTC() {
+			value = "? AT TIME ZONE ?"
+			args = append(args, b.Timezone)
+		}
+		switch b.Interval {
+		case BucketHour, BucketDay, BucketWeek, BucketMonth, BucketYear:
+			return "to_char(date_trunc(?, " + value + `), 'YYYY-MM-DD"T"HH24:MI:SS')`, args, nil
+		}
+
+	case dialect.MySQL:
+		value := "?"
+		args := []interface{}{field}
+		if !b.isUTC() {
+			value = "CONVERT_TZ(?, '+00:00', ?)"
+			args = append(args, b.Timezone)
+		}
+		switch b.Interval {
+		case BucketHour:
+			return "DATE_FORMAT(" + value + ", '%Y-%m-%dT%H:00:00')", args, nil
+		case BucketDay:
+			return "DATE_FORMAT(" + value + ", '%Y-%m-%dT00:00:00')", args, nil
+		case BucketWeek:
+			return "DATE_FORMAT(DATE_SUB(" + value + ", INTERVAL WEEKDAY(" + value + ") DAY), '%Y-%m-%dT00:00:00')",
+				append(args, args...), nil
+		case BucketMonth:
+			return "DATE_FORMAT(" + value + ", '%Y-%m-01T00:00:00')", args, nil
+		case BucketYear:
+			return "DATE_FORMAT(" + value + ", '%Y-01-01T00:00:00')", args, nil
+		}
+
+	case dialect.SQLite:
+		if !b.isUTC() {
+			return "", nil, fmt.Errorf("time zone '%s' is not supported on sqlite", b.Timezone)
+		}
+		args := []interface{}{field}
+		switch b.Interval {
+		case BucketHour:
+			return "strftime('%Y-%m-%dT%H:00:00', ?)", args, nil
+		case BucketDay:
+			return "strftime('%Y-%m-%dT00:00:00', ?)", args, nil
+		case BucketWeek:
+			// Moving to the next Sunday, or staying on a Sunday, then back 6 days gives the Monday of the week
+			return "strftime('%Y-%m-%dT00:00:00', ?, 'weekday 0', '-6 days')", args, nil
+		case BucketMonth:
+			return "strftime('%Y-%m-01T00:00:00', ?)", args, nil
+		case BucketYear:
+			return "strftime('%Y-01-01T00:00:00', ?)", args, nil
+		}
+
+	case dialect.MSSQL:
+		if !b.isUTC() {
+			return "", nil, fmt.Errorf("time zone '%s' is not supported on mssql", b.Timezone)
+		}
+		args := []interface{}{field}
+		// Day 0 is Monday the 1st of January 1900, so the differences from it truncate to the start of the bucket
+		switch b.Interval {
+		case BucketHour:
+			return "CONVERT(varchar(19), DATEADD(hour, DATEDIFF(hour, 0, ?), 0), 126)", args, nil
+		case BucketDay:
+			return "CONVERT(varchar(19), DATEADD(day, DATEDIFF(day, 0, ?), 0), 126)", args, nil
+		case BucketWeek:
+			return "CONVERT(varchar(19), DATEADD(day, DATEDIFF(day, 0, ?) / 7 * 7, 0), 126)", args, nil
+		case BucketMonth:
+			return "CONVERT(varchar(19), DATEADD(month, DATEDIFF(month, 0, ?), 0), 126)", args, nil
+		case BucketYear:
+			return "CONVERT(varchar(19), DATEADD(year, DATEDIFF(year, 0, ?), 0), 126)", args, nil
+		}
+
+	default:
+		return "", nil, fmt.Errorf("time buckets are not supported on %s", d)
+	}
+
+	return "", nil, fmt.Errorf("invalid bucket interval '%s'", b.Interval)
+}
+
+// truncate returns the start of the bucket of a time, in its location
+func (b TimeBucket) truncate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch b.Interval {
+	case BucketHour:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case BucketWeek:
+		return time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case BucketMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case BucketYear:
+		return time.Date(year, 1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns the start of the bucket following the one starting at start
+func (b TimeBucket) next(start time.Time) time.Time {
+	switch b.Interval {
+	case BucketHour:
+		return b.truncate(start.Add(time.Hour))
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	case BucketYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,153 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is wrapped by the errors of the queries the budget of the request could not afford
+var ErrBudgetExceeded = errors.New("query budget exceeded")
+
+// QueryBudget bounds the number of statements and the total database time of the queries spawned from one request:
+// pages and their counts, unions, percentiles and time buckets. It is carried by the context of the request
+// When the budget only affords the main query of a page, the count query is skipped and the total is -1, which
+// Partial reports. Queries the budget cannot afford at all fail with an error wrapping ErrBudgetExceeded, and a
+// statement running out of database time is canceled. A QueryBudget is safe for concurrent use
+type QueryBudget struct {
+	MaxStatements int           // Maximum number of statements; unbounded if zero
+	MaxDuration   time.Duration // Maximum total time spent in the database; unbounded if zero
+
+	mu         sync.Mutex
+	statements int
+	spent      time.Duration
+	partial    bool
+}
+
+// NewQueryBudget creates a budget of statements and database time, zero meaning unbounded
+func NewQueryBudget(maxStatements int, maxDuration time.Duration) *QueryBudget {
+	return &QueryBudget{MaxStatements: maxStatements, MaxDuration: maxDuration}
+}
+
+// budgetKey is the context key of the query budget of a request
+type budgetKey struct{}
+
+// WithQueryBudget returns a context carrying the query budget of a request
+func WithQueryBudget(ctx context.Context, budget *QueryBudget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// QueryBudgetFromContext returns the query budget carried by the context, or nil
+func QueryBudgetFromContext(ctx context.Context) *QueryBudget {
+	budget, _ := ctx.Value(budgetKey{}).(*QueryBudget)
+	return budget
+}
+
+// Statements returns the number of statements executed
+func (b *QueryBudget) Statements() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.statements
+}
+
+// Spent returns the total time spent in the database
+func (b *QueryBudget) Spent() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent
+}
+
+// Partial reports whether queries were skipped because the budget was exceeded, the results being incomplete
+func (b *QueryBudget) Partial() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.partial
+}
+
+// reserve reserves up to n statements, returning how many the budget affords, at least one, or an error wrapping
+// ErrBudgetExceeded if it affords none
+func (b *QueryBudget) reserve(n int) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.MaxDuration > 0 && b.spent >= b.MaxDuration {
+		b.partial = true
+		return 0, fmt.Errorf("%w: %s of database time spent", ErrBudgetExceeded, b.spent.Round(time.Millisecond))
+	}
+	if b.MaxStatements > 0 {
+		n = min(n, b.MaxStatements-b.statements)
+		if n <= 0 {
+			b.partial = true
+			return 0, fmt.Errorf("%w: %d statements executed", ErrBudgetExceeded, b.statements)
+		}
+	}
+	b.statements += n
+	return n, nil
+}
+
+// deadline bounds a context by the database time left in the budget
+func (b *QueryBudget) deadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.MaxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, b.MaxDuration-b.spent)
+}
+
+// record adds the time a statement spent in the database, returning its error wrapped with ErrBudgetExceeded if it
+// was canceled for running out of database time
+func (b *QueryBudget) record(elapsed time.Duration, err error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent += elapsed
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && b.MaxDuration > 0 && b.spent >= b.MaxDuration {
+		b.partial = true
+		return fmt.Errorf("%w: %w", ErrBudgetExceeded, err)
+	}
+	return err
+}
+
+// markPartial records that queries were skipped
+func (b *QueryBudget) markPartial() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.partial = true
+}
+
+// budgeted runs a statement within the query budget of the context, if any
+func budgeted(ctx context.Context, statement func(ctx context.Context) error) error {
+	budget := QueryBudgetFromContext(ctx)
+	if budget == nil {
+		return statement(ctx)
+	}
+	if _, err := budget.reserve(1); err != nil {
+		return err
+	}
+
+	ctx, cancel := budget.deadline(ctx)
+	defer cancel()
+	start := time.Now()
+	err := statement(ctx)
+	return budget.record(time.Since(start), err)
+}
+
+// budgetedCount records the database time of a count query in the budget
+func budgetedCount(budget *QueryBudget, count func(context.Context) (int, error)) func(context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		start := time.Now()
+		total, err := count(ctx)
+		return total, budget.record(time.Since(start), err)
+	}
+}
+
+// budgetedScan records the database time of a main query in the budget
+func budgetedScan(budget *QueryBudget, scan func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := scan(ctx)
+		return budget.record(time.Since(start), err)
+	}
+}
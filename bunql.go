@@ -2,15 +2,26 @@ package bunql
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/fxnoob/bunql/cursor"
 	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/expr"
 	"github.com/fxnoob/bunql/filter"
 	"github.com/fxnoob/bunql/pagination"
+	"github.com/fxnoob/bunql/search"
 	"github.com/fxnoob/bunql/sorting"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"maps"
 	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Filter is a re-export of dto.Filter to make it accessible directly from the bunql package
@@ -19,12 +30,59 @@ type Filter = dto.Filter
 // PaginationMetadataOutput is an alias for dto.GetPaginationMetadataOutput
 type PaginationMetadataOutput = dto.GetPaginationMetadataOutput
 
+// CursorPaginationMetadataOutput is an alias for dto.CursorPaginationMetadataOutput
+type CursorPaginationMetadataOutput = dto.CursorPaginationMetadataOutput
+
+// ErrInvalidPage is returned when the requested page is not a positive integer
+var ErrInvalidPage = errors.New("page must be a positive integer")
+
+// ErrInvalidPageSize is returned when the requested page size is not a positive integer
+var ErrInvalidPageSize = errors.New("page size must be a positive integer")
+
+// BunQL holds the filters, sort and pagination of a query, and the configuration they are applied with
+// A configured BunQL can be shared by goroutines as a template: Apply, ApplyWithCount and the Execute functions only
+// read it, and the With methods of its clones copy the maps and slices they change instead of writing to the ones
+// they share with the template. Each request then works on its own Clone, or on the BunQL returned by ParseRequest
 type BunQL struct {
 	Filters             dto.FilterGroup
 	Sort                []dto.SortField
 	Pagination          *dto.Pagination
 	AllowedFilterFields []string
 	AllowedSortFields   []string
+	Timeout             time.Duration
+	Cursor              *cursor.Cursor
+	Pinned              *dto.PinnedRows
+	Search              *dto.Search
+	FilterOptions       filter.Options
+	Fields              map[string]dto.FieldConfig
+	MaxPageSize         int
+	MaxRows             int
+	ClampPagination     bool
+	FilterParamLogic    string
+	Middleware          []Middleware
+	DeniedFieldHook     func(ctx context.Context, denied DeniedField)
+	IndexedFields       []string
+	WarningHook         func(ctx context.Context, warning Warning)
+	AuditHook           func(ctx context.Context, entry AuditEntry)
+	AdmissionHook       func(ctx context.Context, caller string, complexity int) error
+	Hint                *Hint
+	WindowColumns       []WindowColumn
+	Deduplicator        *Deduplicator
+	CircuitBreaker      *CircuitBreaker
+	SortAliases         map[string][]dto.SortField
+	ContextFilters      []ContextFilter
+	Replica             *bun.DB
+	ResultCache         ResultCache
+	ResultCacheTTL      time.Duration
+	Statements          *StatementCache
+	FilterScopes        map[string][]string
+	AddSortColumns      bool
+	CountStrategy       CountStrategy
+	DeniedFilterFields  []string
+	DeniedSortFields    []string
+	SelectedFields      []string
+	ComputedFields      map[string]ComputedField
+	ExecutionLimiter    *ExecutionLimiter
 }
 
 // New creates a new BunQL instance
@@ -57,66 +115,461 @@ func NewWithAllowedFields(allowedFilterFields, allowedSortFields []string) *BunQ
 	}
 }
 
+// ForDB creates a new BunQL instance generating SQL for the dialect of the database
+func ForDB(db bun.IDB) *BunQL {
+	return New().WithDialect(db.Dialect().Name())
+}
+
+// Clone returns a copy of the BunQL, e.g. of a template shared by the handlers of a server, which the With methods
+// of the copy leave untouched
+func (q *BunQL) Clone() *BunQL {
+	clone := *q
+	return &clone
+}
+
+// WithDialect sets the dialect the SQL is generated for, which selects the date handling and the features
+// (similarity, spatial operators, row comparisons, etc.) used; by default the dialect of each query is used
+func (q *BunQL) WithDialect(d dialect.Name) *BunQL {
+	q.FilterOptions.Dialect = d
+	return q
+}
+
 // WithFilters adds filter to the query
 func (q *BunQL) WithFilters(filters dto.FilterGroup) *BunQL {
 	q.Filters = filters
 	return q
 }
 
+// AddFilter adds a condition that the rows must match on top of the current filters, see AddGroup
+func (q *BunQL) AddFilter(field, op string, value interface{}) error {
+	f, err := newFilter(dto.Filter{Field: field, Operator: op, Value: value})
+	if err != nil {
+		return err
+	}
+	if err := validateFilterConfigs(dto.FilterGroup{Filters: []dto.Filter{f}}, q.Fields, q.FilterOptions.DateLocale); err != nil {
+		return err
+	}
+
+	filters := q.andFilters()
+	filters.Filters = append(slices.Clip(filters.Filters), f)
+	q.Filters = filters
+	return nil
+}
+
+// AddFilterIf adds a condition like AddFilter, unless the value is empty, e.g. an optional request parameter that was
+// not provided: nil, zero, "", false, the zero time or an empty list
+// Pointers are only empty when nil, and are dereferenced, so that a *bool set to false still filters on false
+func (q *BunQL) AddFilterIf(field, op string, value interface{}) error {
+	value, ok := providedValue(value)
+	if !ok {
+		return nil
+	}
+	return q.AddFilter(field, op, value)
+}
+
+// ApplyWhen calls apply with the BunQL if the condition holds, to add filters or sort fields conditionally while
+// building the query
+func (q *BunQL) ApplyWhen(condition bool, apply func(q *BunQL) error) error {
+	if !condition {
+		return nil
+	}
+	return apply(q)
+}
+
+// providedValue returns the value, dereferenced if it is a pointer, and whether it is provided, i.e. not empty
+func providedValue(value interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		return v.Elem().Interface(), true
+	}
+
+	switch {
+	case !v.IsValid():
+		return nil, false
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Map:
+		return value, v.Len() > 0
+	default:
+		return value, !v.IsZero()
+	}
+}
+
+// AddGroup adds a group of conditions joined by the logic ("and" or "or") that the rows must match on top of the
+// current filters, e.g. server-side conditions layered over the parsed filters of a client
+// The filters are validated like parsed ones, and against the field configuration but not the allowed fields, which
+// restrict the client; nothing is added if any of them is invalid
+func (q *BunQL) AddGroup(logic string, filters ...dto.Filter) error {
+	groupLogic, ok := dto.ParseLogic(logic)
+	if !ok {
+		return fmt.Errorf("invalid filter group logic '%s': must be 'and' or 'or'", logic)
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+
+	group := dto.FilterGroup{Logic: groupLogic, Filters: make([]dto.Filter, len(filters)), Groups: []dto.FilterGroup{}}
+	for i, f := range filters {
+		var err error
+		if group.Filters[i], err = newFilter(f); err != nil {
+			return err
+		}
+	}
+	if err := validateFilterConfigs(group, q.Fields, q.FilterOptions.DateLocale); err != nil {
+		return err
+	}
+
+	andFilters := q.andFilters()
+	andFilters.Groups = append(slices.Clip(andFilters.Groups), group)
+	q.Filters = andFilters
+	return nil
+}
+
+// andFilters returns the current filters as an unscoped group whose members are joined with AND, nesting the current
+// group if it is an OR or scoped group, so that the members added to it restrict the rows instead of matching more of
+// them, and are not subject to the scope
+func (q *BunQL) andFilters() dto.FilterGroup {
+	if len(q.Filters.Filters) == 0 && len(q.Filters.Groups) == 0 {
+		return dto.FilterGroup{Logic: dto.LogicAnd, Filters: []dto.Filter{}, Groups: []dto.FilterGroup{}}
+	}
+	if !q.Filters.IsOr() && q.Filters.Scope == "" {
+		filters := q.Filters
+		filters.Logic = dto.LogicAnd
+		return filters
+	}
+	return dto.FilterGroup{Logic: dto.LogicAnd, Filters: []dto.Filter{}, Groups: []dto.FilterGroup{q.Filters}}
+}
+
+// newFilter validates a filter added in code and returns it with its operator resolved
+func newFilter(f dto.Filter) (dto.Filter, error) {
+	group, err := filter.ParseFilterParam(f.Field, f.Operator, f.Value, dto.LogicAnd)
+	if err != nil {
+		return dto.Filter{}, err
+	}
+	group.Filters[0].CaseSensitive = f.CaseSensitive
+	return group.Filters[0], nil
+}
+
 // WithSort adds sorting to the query
 func (q *BunQL) WithSort(sort []dto.SortField) *BunQL {
 	q.Sort = sort
 	return q
 }
 
+// OrderBy appends a sort field after the current ones, see OrderByMany
+func (q *BunQL) OrderBy(field string, direction dto.Direction) error {
+	return q.OrderByMany(dto.SortField{Field: field, Direction: direction})
+}
+
+// OrderByMany appends sort fields after the current ones, unlike WithSort which replaces them
+// The fields are validated against the allowed and denied sort fields, and directions must be empty (ascending) or
+// valid; nothing is appended if any of them is invalid
+func (q *BunQL) OrderByMany(sort ...dto.SortField) error {
+	appended := make([]dto.SortField, len(sort))
+	for i, s := range sort {
+		if !sorting.IsFieldName(s.Field) {
+			return fmt.Errorf("invalid sort field '%s'", s.Field)
+		}
+		direction, ok := dto.ParseDirection(string(s.Direction))
+		if !ok && s.Direction != "" {
+			return fmt.Errorf("invalid sort direction '%s' for field '%s'", s.Direction, s.Field)
+		}
+		appended[i] = s
+		appended[i].Direction = direction
+	}
+	if err := q.validateSort(appended); err != nil {
+		return err
+	}
+
+	q.Sort = append(slices.Clip(q.Sort), appended...)
+	return nil
+}
+
+// WithSortAlias makes a sort field expand to an ordered list of columns, e.g. "name" to last_name and first_name
+// Sorting on the alias descending inverts the direction of every column. The columns are configured by the server,
+// and the alias, not its columns, is checked against the allowed sort fields
+func (q *BunQL) WithSortAlias(alias string, columns ...dto.SortField) *BunQL {
+	aliases := maps.Clone(q.SortAliases)
+	if aliases == nil {
+		aliases = map[string][]dto.SortField{}
+	}
+	aliases[alias] = columns
+	q.SortAliases = aliases
+	return q
+}
+
 // WithPagination adds pagination to the query
 func (q *BunQL) WithPagination(pagination *dto.Pagination) *BunQL {
 	q.Pagination = pagination
 	return q
 }
 
-// Apply applies all filter, sorting, and pagination to the query
+// WithFieldConfig sets the configuration of a field, such as its collation or accent folding,
+// used when filtering and sorting on the field
+func (q *BunQL) WithFieldConfig(field string, cfg dto.FieldConfig) *BunQL {
+	fields := maps.Clone(q.Fields)
+	if fields == nil {
+		fields = map[string]dto.FieldConfig{}
+	}
+	fields[field] = cfg
+	q.Fields = fields
+	return q
+}
+
+// filterOptions returns the options used to apply the filters
+func (q *BunQL) filterOptions() filter.Options {
+	opts := q.FilterOptions
+	opts.Fields = q.Fields
+	opts.Expressions = q.computedExpressions()
+	return opts
+}
+
+// sortOptions returns the options used to apply the sort
+func (q *BunQL) sortOptions() sorting.Options {
+	return sorting.Options{
+		Dialect:     q.FilterOptions.Dialect,
+		Fields:      q.Fields,
+		Expressions: q.computedExpressions(),
+	}
+}
+
+// WithLikeMatch sets how like values without wildcards are matched on the fields without a match mode of their own,
+// e.g. dto.MatchPrefix so that like can use an index; dto.MatchContains by default
+func (q *BunQL) WithLikeMatch(mode dto.MatchMode) *BunQL {
+	q.FilterOptions.LikeMatch = mode
+	return q
+}
+
+// WithDateLocale sets how date strings in filter values are read, e.g. filter.Locales["de"] for 31.01.2024
+// By default numeric dates are read month first (01/31/2024)
+func (q *BunQL) WithDateLocale(locale filter.DateLocale) *BunQL {
+	q.FilterOptions.DateLocale = locale
+	return q
+}
+
+// WithSimilarityThreshold sets the minimum similarity matched by the similar operator on Postgres
+func (q *BunQL) WithSimilarityThreshold(threshold float64) *BunQL {
+	q.FilterOptions.SimilarityThreshold = threshold
+	return q
+}
+
+// WithOperatorTemplate overrides the SQL generated for an operator on a dialect, e.g. "? ILIKE ?" for like on Postgres
+// See filter.Templates for the placeholders of the template
+func (q *BunQL) WithOperatorTemplate(d dialect.Name, op, template string) *BunQL {
+	templates := maps.Clone(q.FilterOptions.Templates)
+	if templates == nil {
+		templates = filter.Templates{}
+	}
+	templates[d] = maps.Clone(templates[d])
+	if templates[d] == nil {
+		templates[d] = map[string]string{}
+	}
+	templates[d][strings.ToLower(op)] = template
+	q.FilterOptions.Templates = templates
+	return q
+}
+
+// WithSearch restricts the results to the rows where any of the fields contains the term, ignoring case
+// While searching, the virtual sort field "relevance" orders the results by how well they match the term
+func (q *BunQL) WithSearch(term string, fields ...string) *BunQL {
+	q.Search = &dto.Search{
+		Term:   term,
+		Fields: fields,
+	}
+	return q
+}
+
+// WithPinnedIDs sorts the rows whose column is one of ids before all the others, e.g. featured or sticky records
+// The remaining rows, and the pinned rows among themselves, still follow the requested sort
+// Pinned rows are ignored with cursor pagination, since they would break the keyset order
+func (q *BunQL) WithPinnedIDs(column string, ids ...interface{}) *BunQL {
+	q.Pinned = &dto.PinnedRows{
+		Column: column,
+		IDs:    ids,
+	}
+	return q
+}
+
+// WithCursor switches the query to keyset pagination, starting after the given cursor
+// Pages are fetched with the configured page size and without an offset
+func (q *BunQL) WithCursor(c *cursor.Cursor) *BunQL {
+	q.Cursor = c
+	return q
+}
+
+// WithTimeout bounds the execution time of the query
+// A zero or negative timeout disables the limit
+func (q *BunQL) WithTimeout(timeout time.Duration) *BunQL {
+	q.Timeout = timeout
+	return q
+}
+
+// WithClampPagination sets whether out of range pages and page sizes are clamped instead of rejected
+// When clamping, pages and page sizes below 1 are treated as not provided
+func (q *BunQL) WithClampPagination(clamp bool) *BunQL {
+	q.ClampPagination = clamp
+	return q
+}
+
+// WithFilterParamLogic sets the logic ("and" or "or") combining repeated filter parameters of a request
+// It defaults to "and"
+func (q *BunQL) WithFilterParamLogic(logic string) *BunQL {
+	q.FilterParamLogic = logic
+	return q
+}
+
+// WithDeniedFields denies filtering and sorting on the fields, e.g. password_hash, whether or not they are allowed
+// Fields may be patterns like the allowed fields. The denied fields take precedence over the allowed ones, so that
+// "everything except" endpoints leave the allowed fields empty and those narrowing a pattern list both
+func (q *BunQL) WithDeniedFields(filterFields, sortFields []string) *BunQL {
+	q.DeniedFilterFields = filterFields
+	q.DeniedSortFields = sortFields
+	return q
+}
+
+// WithMaxRows caps the number of rows returned by the query, with or without pagination
+// A zero or negative value disables the cap
+func (q *BunQL) WithMaxRows(maxRows int) *BunQL {
+	q.MaxRows = maxRows
+	return q
+}
+
+// deadline derives a context bounded by the configured timeout
+func (q *BunQL) deadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if q.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, q.Timeout)
+}
+
+// Apply applies all filter, sorting, and pagination to the query, through the middleware if any
 func (q *BunQL) Apply(ctx context.Context, query *bun.SelectQuery) *bun.SelectQuery {
+	query = q.chain(applyQuery)(ctx, q, query)
+	if tracksDebug(ctx) {
+		q.recordDebug(ctx, redactedSQL(query), "")
+	}
+	return query
+}
+
+// applyQuery is the Applier applying the BunQL to a query, at the end of the middleware chain
+func applyQuery(ctx context.Context, ql *BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+	return ql.apply(ctx, query)
+}
+
+// applyCount is the Applier applying the filters of the BunQL to a count query, at the end of the middleware chain
+func applyCount(ctx context.Context, ql *BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+	if len(ql.Filters.Filters) > 0 || len(ql.Filters.Groups) > 0 {
+		query = ql.applyFilters(query)
+	}
+	query = ql.applyContextFilters(ctx, query)
+	if ql.Search != nil {
+		query = search.ApplySearch(query, ql.Search)
+	}
+	return query
+}
+
+// apply applies all filter, sorting, and pagination to the query
+func (q *BunQL) apply(ctx context.Context, query *bun.SelectQuery) *bun.SelectQuery {
+	// Report filters and sorts that would scan the table
+	q.warnUnindexedFields(ctx)
+
+	// Apply index hints
+	query = q.applyIndexHints(query)
+
+	// Select the requested fields
+	if len(q.SelectedFields) > 0 {
+		query = query.Column(q.SelectedFields...)
+	}
+
 	// Apply filter
 	if len(q.Filters.Filters) > 0 || len(q.Filters.Groups) > 0 {
-		query = filter.ApplyFilterGroup(query, q.Filters)
+		query = q.applyFilters(query)
 	}
 
-	// Apply sorting
+	// Apply the filters read from the context
+	query = q.applyContextFilters(ctx, query)
+
+	// Apply search
+	if q.Search != nil {
+		query = search.ApplySearch(query, q.Search)
+	}
+
+	// Add the columns computed with window functions
+	query = q.applyWindowColumns(query)
+
+	// Apply pinned rows ahead of the requested sort
+	if q.Pinned != nil && q.Cursor == nil {
+		query = sorting.ApplyPinned(query, q.Pinned)
+	}
+
+	// Apply sorting, broken by the primary key when paginated and reversed when walking backward from a cursor
 	if len(q.Sort) > 0 {
-		query = sorting.ApplySort(query, q.Sort)
+		sortFields := sorting.ExpandAliases(q.Sort, q.SortAliases)
+		if q.Cursor != nil || q.Pagination != nil {
+			sortFields = tieBreak(query, sortFields)
+		}
+		if q.Cursor != nil && q.Cursor.Backward {
+			sortFields = cursor.Invert(sortFields)
+		}
+		query = q.checkSortSelected(ctx, query, sortFields)
+		query = q.applySort(query, sortFields)
 	}
 
 	// Apply pagination
-	if q.Pagination != nil {
+	if q.Cursor != nil {
+		query = cursor.ApplyCursorWithOptions(query, q.Cursor, q.sortOptions())
+		if q.Pagination != nil && q.Pagination.Limit() > 0 {
+			query = query.Limit(q.Pagination.Limit())
+		}
+	} else if q.Pagination != nil {
 		query = pagination.ApplyPagination(query, q.Pagination)
 	}
 
-	// Print the query to console
-	fmt.Println("Query:", query)
+	// Cap the number of rows when the pagination doesn't already limit them below the cap
+	if q.MaxRows > 0 && (q.Pagination == nil || q.Pagination.PageSize <= 0 || q.Pagination.PageSize > q.MaxRows) {
+		query = query.Limit(q.MaxRows)
+	}
+
+	return query
+}
 
+// applySort applies the sort fields, with their aliases already expanded, to the query, resolving the virtual relevance
+// field. The relevance field is skipped when no search is configured
+func (q *BunQL) applySort(query *bun.SelectQuery, sortFields []dto.SortField) *bun.SelectQuery {
+	for _, sort := range sortFields {
+		if sort.Field == search.RelevanceField {
+			query = search.ApplyRelevanceSort(query, q.Search, string(sort.Direction))
+			continue
+		}
+		query = sorting.ApplySortWithOptions(query, []dto.SortField{sort}, q.sortOptions())
+	}
 	return query
 }
 
 // ApplyWithCount applies all filter, sorting, and pagination to the query and returns both the query and a count query
 func (q *BunQL) ApplyWithCount(ctx context.Context, query *bun.SelectQuery) (*bun.SelectQuery, *bun.SelectQuery) {
 	// Apply the filters, sorting, and pagination to the main query
-	mainQuery := q.Apply(ctx, query)
+	// The count query is rendered from the same query, so the SQL of the main query is recorded before
+	mainQuery := q.chain(applyQuery)(ctx, q, query)
+	var mainSQL string
+	if tracksDebug(ctx) {
+		mainSQL = redactedSQL(mainQuery)
+	}
 
 	// For the count query, only apply the filters
-	countQuery := query
-	if len(q.Filters.Filters) > 0 || len(q.Filters.Groups) > 0 {
-		countQuery = filter.ApplyFilterGroup(countQuery, q.Filters)
-	}
+	countQuery := q.chain(applyCount)(context.WithValue(ctx, countKey{}, true), q, query)
+	countQuery = q.countBy(countQuery)
 
-	// Print the queries to console
-	fmt.Println("Main Query:", mainQuery)
-	fmt.Println("Count Query:", countQuery)
+	if tracksDebug(ctx) {
+		q.recordDebug(ctx, mainSQL, redactedSQL(countQuery))
+	}
 
 	return mainQuery, countQuery
 }
 
 // ParseFromParams creates a BunQL instance from JSON/query parameters
+// A zero page or page size means it was not provided, while negative ones are rejected with ErrInvalidPage or ErrInvalidPageSize
 func ParseFromParams(filterParam, sortParam string, page, pageSize int) (*BunQL, error) {
 	return ParseFromParamsWithAllowedFields(filterParam, sortParam, page, pageSize, nil, nil)
 }
@@ -124,65 +577,168 @@ func ParseFromParams(filterParam, sortParam string, page, pageSize int) (*BunQL,
 // ParseFromParamsWithAllowedFields creates a BunQL instance from JSON/query parameters with allowed fields for filtering and sorting
 func ParseFromParamsWithAllowedFields(filterParam, sortParam string, page, pageSize int, allowedFilterFields, allowedSortFields []string) (*BunQL, error) {
 	ql := NewWithAllowedFields(allowedFilterFields, allowedSortFields)
+	if err := ql.parseParams(context.Background(), []string{filterParam}, sortParam, page, pageSize); err != nil {
+		return nil, err
+	}
+	return ql, nil
+}
 
+// parseParams parses the filter, sort and pagination parameters and validates them against the configuration
+// The filter parameters are combined with the FilterParamLogic
+func (q *BunQL) parseParams(ctx context.Context, filterParams []string, sortParam string, page, pageSize int) error {
 	// Parse filter if provided
-	if filterParam != "" {
-		filters, err := filter.ParseFilters(filterParam)
+	if slices.ContainsFunc(filterParams, func(param string) bool { return param != "" }) {
+		filterParams, err := parseFilterParams(filterParams)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		filters, err := filter.ParseFilterList(filterParams, q.FilterParamLogic)
+		if err != nil {
+			return err
 		}
 
-		// Validate filter fields if allowed fields are specified
-		if len(ql.AllowedFilterFields) > 0 {
-			if err := validateFilterFields(filters, ql.AllowedFilterFields); err != nil {
-				return nil, err
-			}
+		q.reportDeniedFilters(ctx, filters)
+		if err := q.validateFilters(filters); err != nil {
+			return err
 		}
 
-		ql.WithFilters(filters)
+		q.WithFilters(filters)
 	}
 
 	// Parse sorting if provided
 	if sortParam != "" {
 		sort, err := sorting.ParseSort(sortParam)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		// Validate sort fields if allowed fields are specified
-		if len(ql.AllowedSortFields) > 0 {
-			if err := validateSortFields(sort, ql.AllowedSortFields); err != nil {
-				return nil, err
-			}
+		q.reportDeniedSort(ctx, sort)
+		if err := q.validateSort(sort); err != nil {
+			return err
 		}
 
-		ql.WithSort(sort)
+		q.WithSort(sort)
+	}
+
+	// Reject negative pagination, or treat it as not provided when clamping
+	if page < 0 {
+		if !q.ClampPagination {
+			return fmt.Errorf("invalid page %d: %w", page, ErrInvalidPage)
+		}
+		page = 0
+	}
+	if pageSize < 0 {
+		if !q.ClampPagination {
+			return fmt.Errorf("invalid page size %d: %w", pageSize, ErrInvalidPageSize)
+		}
+		pageSize = 0
 	}
 
 	// Set up pagination if provided
 	if page > 0 || pageSize > 0 {
-		paging := &dto.Pagination{
-			Page:     page,
-			PageSize: pageSize,
-		}
-		ql.WithPagination(paging)
+		paging := dto.Pagination{Page: page, PageSize: pageSize}.Normalize(dto.Pagination{PageSize: q.MaxPageSize}, q.MaxPageSize)
+		q.WithPagination(&paging)
 	}
 
-	return ql, nil
+	if err := q.validateWindowColumns(); err != nil {
+		return err
+	}
+
+	// Run the validation of the plugins on the parsed query
+	return q.validatePlugins()
+}
+
+// validateFilters validates filters against the allowed fields and the field configuration
+func (q *BunQL) validateFilters(filters dto.FilterGroup) error {
+	// Validate filter fields against the allowed and denied fields
+	if err := validateFilterFields(filters, q.allowsFilterField); err != nil {
+		return err
+	}
+
+	// Validate the fields of the scoped groups
+	if err := q.validateFilterScopes(filters); err != nil {
+		return err
+	}
+
+	// Validate operators and value types of the configured fields
+	return validateFilterConfigs(filters, q.Fields, q.FilterOptions.DateLocale)
+}
+
+// validateSort validates sort fields against the allowed fields
+func (q *BunQL) validateSort(sort []dto.SortField) error {
+	// Validate sort fields against the allowed and denied fields
+	return validateSortFields(sort, q.allowsSortField)
+}
+
+// allowsFilterField reports whether a field can be filtered on: denied fields never can, and other fields can if
+// there are no allowed fields or they are among them
+func (q *BunQL) allowsFilterField(field string) bool {
+	if deniesField(q.DeniedFilterFields, field) {
+		return false
+	}
+	return len(q.AllowedFilterFields) == 0 || allowsField(q.AllowedFilterFields, field)
 }
 
-// validateFilterFields validates that all filter fields are in the list of allowed fields
-func validateFilterFields(group dto.FilterGroup, allowedFields []string) error {
+// allowsSortField reports whether a field can be sorted on, like allowsFilterField
+func (q *BunQL) allowsSortField(field string) bool {
+	if deniesField(q.DeniedSortFields, field) {
+		return false
+	}
+	return len(q.AllowedSortFields) == 0 || allowsField(q.AllowedSortFields, field)
+}
+
+// allowsSelectedField reports whether a field can be selected: fields denied for filtering or sorting never can, and
+// other fields can if they can be filtered or sorted on
+func (q *BunQL) allowsSelectedField(field string) bool {
+	if deniesField(q.DeniedFilterFields, field) || deniesField(q.DeniedSortFields, field) {
+		return false
+	}
+	return q.allowsFilterField(field) || q.allowsSortField(field)
+}
+
+// validateFilterFields validates that all filter fields are allowed
+func validateFilterFields(group dto.FilterGroup, allows func(field string) bool) error {
 	// Validate all direct filters in this group
 	for _, filter := range group.Filters {
-		if !contains(allowedFields, filter.Field) {
+		if !allows(filter.Field) {
 			return fmt.Errorf("filter field '%s' is not allowed", filter.Field)
 		}
 	}
 
 	// Validate all nested filter groups
 	for _, nestedGroup := range group.Groups {
-		if err := validateFilterFields(nestedGroup, allowedFields); err != nil {
+		if err := validateFilterFields(nestedGroup, allows); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateFilterConfigs validates that the filters on configured fields use an allowed operator and a value of the field type
+// Time values may be dates in the date locale
+func validateFilterConfigs(group dto.FilterGroup, fields map[string]dto.FieldConfig, locale filter.DateLocale) error {
+	for _, filter := range group.Filters {
+		cfg, ok := fields[filter.Field]
+		if !ok {
+			continue
+		}
+
+		if len(cfg.Operators) > 0 && !containsFold(cfg.Operators, filter.Operator) {
+			return fmt.Errorf("operator '%s' is not allowed on field '%s'", filter.Operator, filter.Field)
+		}
+		if cfg.Type != "" && !hasFieldType(filter.Value, cfg.Type, locale) {
+			return fmt.Errorf("filter field '%s' requires %s values", filter.Field, cfg.Type)
+		}
+		if cfg.Type == dto.FieldDecimal {
+			if err := validateDecimalDigits(filter, cfg); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, nestedGroup := range group.Groups {
+		if err := validateFilterConfigs(nestedGroup, fields, locale); err != nil {
 			return err
 		}
 	}
@@ -190,12 +746,91 @@ func validateFilterFields(group dto.FilterGroup, allowedFields []string) error {
 	return nil
 }
 
-// validateSortFields validates that all sort fields are in the list of allowed fields
-func validateSortFields(sortFields []dto.SortField, allowedFields []string) error {
+// hasFieldType reports whether a filter value, or every element of a list value, has the given type
+// NULL and object values, such as the value of the geospatial operators, are not checked, and time strings are read
+// like the date filters read them in the date locale
+func hasFieldType(value interface{}, fieldType dto.FieldType, locale filter.DateLocale) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		if _, ok := value.([]byte); !ok {
+			for i := 0; i < v.Len(); i++ {
+				if !hasFieldType(v.Index(i).Interface(), fieldType, locale) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	switch fieldType {
+	case dto.FieldString:
+		_, ok := value.(string)
+		return ok
+	case dto.FieldNumber:
+		if _, ok := value.(json.Number); ok {
+			return true
+		}
+		return v.CanInt() || v.CanUint() || v.CanFloat()
+	case dto.FieldBoolean:
+		_, ok := value.(bool)
+		return ok
+	case dto.FieldDecimal:
+		_, ok := expr.Decimal(value)
+		return ok
+	case dto.FieldTime:
+		switch t := value.(type) {
+		case time.Time:
+			return true
+		case string:
+			return locale.IsDate(t)
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// validateDecimalDigits validates that the values of a filter on a decimal field fit the precision and scale of the field
+func validateDecimalDigits(filter dto.Filter, cfg dto.FieldConfig) error {
+	values := []interface{}{filter.Value}
+	if v := reflect.ValueOf(filter.Value); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		values = make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Interface()
+		}
+	}
+
+	for _, value := range values {
+		decimal, ok := expr.Decimal(value)
+		if !ok {
+			continue
+		}
+		precision, scale := expr.DecimalDigits(decimal)
+		if cfg.Scale > 0 && scale > cfg.Scale {
+			return fmt.Errorf("filter field '%s' allows at most %d decimal places", filter.Field, cfg.Scale)
+		}
+		if cfg.Precision > 0 && precision > cfg.Precision {
+			return fmt.Errorf("filter field '%s' allows at most %d digits", filter.Field, cfg.Precision)
+		}
+	}
+	return nil
+}
+
+// validateSortFields validates that all sort fields are allowed, and the values their NULLs are sorted as
+func validateSortFields(sortFields []dto.SortField, allows func(field string) bool) error {
 	for _, sort := range sortFields {
-		if !contains(allowedFields, sort.Field) {
+		if sort.Field == search.RelevanceField {
+			continue
+		}
+		if !allows(sort.Field) {
 			return fmt.Errorf("sort field '%s' is not allowed", sort.Field)
 		}
+		if err := sorting.ValidateNullsAs(sort); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -211,12 +846,99 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+// allowsField checks if a field is allowed by a list of allowed fields, which may hold patterns whose * stands for
+// any run of letters, digits, underscores and dots, e.g. "address_*" for the columns of a wide table or "profile.*"
+// for the columns of a joined relation
+func allowsField(allowed []string, field string) bool {
+	for _, pattern := range allowed {
+		if pattern == field || strings.Contains(pattern, "*") && matchesFieldPattern(pattern, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// deniesField reports whether a field matches one of the denied fields, ignoring case and table qualifiers, so that
+// EMAIL and u.email are denied along with email
+func deniesField(denied []string, field string) bool {
+	if len(denied) == 0 {
+		return false
+	}
+	patterns := make([]string, len(denied))
+	for i, pattern := range denied {
+		patterns[i] = strings.ToLower(pattern)
+	}
+
+	field = strings.ToLower(field)
+	for {
+		if allowsField(patterns, field) {
+			return true
+		}
+		var ok bool
+		if _, field, ok = strings.Cut(field, "."); !ok {
+			return false
+		}
+	}
+}
+
+// matchesFieldPattern reports whether a field matches a pattern of allowed fields
+func matchesFieldPattern(pattern, field string) bool {
+	parts := strings.Split(pattern, "*")
+	rest, ok := strings.CutPrefix(field, parts[0])
+	if !ok {
+		return false
+	}
+	// The wildcards only stand for the characters of names and paths, so that they can't let odd fields through
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 || !isFieldPath(rest[:i]) {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	last := parts[len(parts)-1]
+	return strings.HasSuffix(rest, last) && isFieldPath(rest[:len(rest)-len(last)])
+}
+
+// isFieldPath reports whether a string is made only of letters, digits, underscores and dots
+func isFieldPath(s string) bool {
+	for _, r := range s {
+		if r != '_' && r != '.' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold checks if a string is in a slice of strings, ignoring case
+func containsFold(slice []string, item string) bool {
+	for _, s := range slice {
+		if strings.EqualFold(s, item) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetPaginationMetadata calculates pagination metadata and generates prev/next URLs
+// A negative total count, such as the -1 of a page whose count was skipped by a query budget, is unknown: the totals
+// are then -1 and omitted from the JSON, and the next page is only known with GetPaginationMetadataForPage
 func GetPaginationMetadata(p *dto.Pagination, totalCount int, baseURI string) PaginationMetadataOutput {
+	return GetPaginationMetadataForPage(p, totalCount, 0, baseURI)
+}
+
+// GetPaginationMetadataForPage calculates the pagination metadata of a page of pageItems rows
+// When the total count is unknown, a full page is taken as followed by a next one, which may turn out to be empty
+func GetPaginationMetadataForPage(p *dto.Pagination, totalCount, pageItems int, baseURI string) PaginationMetadataOutput {
+	counted := totalCount >= 0
 	if p == nil || p.PageSize <= 0 {
+		if !counted {
+			return PaginationMetadataOutput{Total: -1, TotalItem: -1, CountMode: dto.CountUnknown}
+		}
 		return PaginationMetadataOutput{
 			Total:     1,
 			TotalItem: totalCount,
+			CountMode: dto.CountExact,
 		}
 	}
 
@@ -230,82 +952,28 @@ func GetPaginationMetadata(p *dto.Pagination, totalCount int, baseURI string) Pa
 		currentPage = 1
 	}
 
-	// Parse the baseURI to extract any existing query parameters
-	var baseURL string
-	var queryParams map[string][]string
-
-	// Check if the baseURI contains a query string
-	parts := strings.Split(baseURI, "?")
-	baseURL = parts[0]
+	hasNext := currentPage < total
+	if !counted {
+		total, totalCount = -1, -1
+		hasNext = pageItems >= p.PageSize
+	}
 
-	// If there's a query string, parse it
-	if len(parts) > 1 && parts[1] != "" {
-		parsedURL, err := url.Parse(baseURI)
-		if err == nil {
-			queryParams = parsedURL.Query()
-		} else {
-			queryParams = make(map[string][]string)
-		}
-	} else {
-		queryParams = make(map[string][]string)
+	// Split the baseURI to extract any existing query parameters, dropping the fragment
+	baseURL, rawQuery, _ := strings.Cut(baseURI, "?")
+	rawQuery, _, _ = strings.Cut(rawQuery, "#")
+	queryParams, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		queryParams = url.Values{}
 	}
 
 	// Generate prev and next URLs
 	var prevURL, nextURL *string
-
 	if currentPage > 1 {
-		// Create a copy of the query parameters for the prev URL
-		prevParams := make(map[string][]string)
-		for k, v := range queryParams {
-			prevParams[k] = v
-		}
-		prevParams["page"] = []string{fmt.Sprintf("%d", currentPage-1)}
-		prevParams["pageSize"] = []string{fmt.Sprintf("%d", p.PageSize)}
-
-		// Build the query string
-		var queryStr string
-		first := true
-		for k, values := range prevParams {
-			for _, v := range values {
-				if first {
-					queryStr += "?"
-					first = false
-				} else {
-					queryStr += "&"
-				}
-				queryStr += url.QueryEscape(k) + "=" + url.QueryEscape(v)
-			}
-		}
-
-		prevURLStr := baseURL + queryStr
+		prevURLStr := pageURL(baseURL, queryParams, currentPage-1, p.PageSize)
 		prevURL = &prevURLStr
 	}
-
-	if currentPage < total {
-		// Create a copy of the query parameters for the next URL
-		nextParams := make(map[string][]string)
-		for k, v := range queryParams {
-			nextParams[k] = v
-		}
-		nextParams["page"] = []string{fmt.Sprintf("%d", currentPage+1)}
-		nextParams["pageSize"] = []string{fmt.Sprintf("%d", p.PageSize)}
-
-		// Build the query string
-		var queryStr string
-		first := true
-		for k, values := range nextParams {
-			for _, v := range values {
-				if first {
-					queryStr += "?"
-					first = false
-				} else {
-					queryStr += "&"
-				}
-				queryStr += url.QueryEscape(k) + "=" + url.QueryEscape(v)
-			}
-		}
-
-		nextURLStr := baseURL + queryStr
+	if hasNext {
+		nextURLStr := pageURL(baseURL, queryParams, currentPage+1, p.PageSize)
 		nextURL = &nextURLStr
 	}
 
@@ -315,19 +983,34 @@ func GetPaginationMetadata(p *dto.Pagination, totalCount int, baseURI string) Pa
 		Prev:      prevURL,
 		Next:      nextURL,
 		TotalItem: totalCount,
+		HasPrev:   prevURL != nil,
+		HasNext:   hasNext,
+		CountMode: dto.CountExact,
+	}
+	if !counted {
+		result.CountMode = dto.CountUnknown
 	}
 
 	return result
 }
 
+// pageURL returns the URL of a page, keeping the other query parameters of the base URL
+func pageURL(baseURL string, queryParams url.Values, page, pageSize int) string {
+	params := make(url.Values, len(queryParams)+2)
+	for k, v := range queryParams {
+		params[k] = v
+	}
+	params["page"] = []string{strconv.Itoa(page)}
+	params["pageSize"] = []string{strconv.Itoa(pageSize)}
+	return baseURL + "?" + params.Encode()
+}
+
 // ParseSortParams creates a sort JSON string from sortby and sortDirection parameters
 // sortby is the field name to sort by
 // sortDirection is the sort direction, which can be "asc" or "desc" (defaults to "asc" if invalid)
 func ParseSortParams(sortby, sortDirection string) string {
 	// Default to "asc" if sortDirection is not valid
-	if sortDirection != "asc" && sortDirection != "desc" {
-		sortDirection = "asc"
-	}
+	direction, _ := dto.ParseDirection(sortDirection)
 
 	// Return empty string if sortby is empty
 	if sortby == "" {
@@ -335,7 +1018,7 @@ func ParseSortParams(sortby, sortDirection string) string {
 	}
 
 	// Create the sort JSON string
-	return fmt.Sprintf(`[{"field": "%s", "dir": "%s"}]`, sortby, sortDirection)
+	return fmt.Sprintf(`[{"field": "%s", "dir": "%s"}]`, sortby, direction)
 }
 
 // ParseFilterParams creates a filter JSON string from field, operator, and value parameters
@@ -364,10 +1047,7 @@ func ParseFilterParams(field, operator string, value interface{}, logic string)
 // logic is the logic to use for the filter group ("and" or "or", defaults to "and" if empty or invalid)
 func ParseMultipleFilterParams(filters []Filter, logic string) (string, error) {
 	// Default to "and" logic if not specified or invalid
-	logic = strings.ToLower(logic)
-	if logic != "and" && logic != "or" {
-		logic = "and"
-	}
+	logic, _ = dto.ParseLogic(logic)
 
 	// Create a filter group
 	filterGroup := dto.FilterGroup{
@@ -398,18 +1078,257 @@ func ParseMultipleFilterParams(filters []Filter, logic string) (string, error) {
 }
 
 // ExecuteWithCount executes both the main query and the count query, and returns the results along with the total count
+// The queries run concurrently under a child context: as soon as one fails, the other is canceled and the error of the
+// failed query is returned. If ctx is canceled or its deadline exceeded, the returned error wraps ctx.Err(), e.g.
+// context.Canceled, rather than the errors of the interrupted queries. Queries bound to a transaction or to a single
+// connection run one after the other, the count query first
 func ExecuteWithCount[T any](ctx context.Context, query, countQuery *bun.SelectQuery) ([]T, int, error) {
-	// Execute the count query
-	count, err := countQuery.Count(ctx)
+	var results []T
+	count, err := executeBoth(ctx, isSingleConn(query) || isSingleConn(countQuery), countQuery.Count,
+		func(ctx context.Context) error { return query.Scan(ctx, &results) })
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to execute count query: %w", err)
+		return nil, 0, err
+	}
+	return results, count, nil
+}
+
+// querySQL returns the SQL of a query, or the error failing the query, which String panics with
+func querySQL(query *bun.SelectQuery) string {
+	sql, err := query.AppendQuery(query.DB().Formatter(), nil)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return string(sql)
+}
+
+// isSingleConn reports whether a query runs on a transaction or a connection, which cannot run two queries at once
+func isSingleConn(query *bun.SelectQuery) bool {
+	switch baseConn(query.GetConn()).(type) {
+	case bun.Tx, *bun.Tx, bun.Conn, *bun.Conn, *sql.Tx, *sql.Conn:
+		return true
+	default:
+		return false
+	}
+}
+
+// executeBoth executes a count query and a main query, concurrently unless sequential is set, canceling the other
+// one when one fails
+func executeBoth(ctx context.Context, sequential bool, count func(context.Context) (int, error), scan func(context.Context) error) (int, error) {
+	queryCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Within a query budget, the count query is skipped when the budget only affords the main query
+	if budget := QueryBudgetFromContext(ctx); budget != nil {
+		n, err := budget.reserve(2)
+		if err != nil {
+			return 0, err
+		}
+		var cancelBudget context.CancelFunc
+		queryCtx, cancelBudget = budget.deadline(queryCtx)
+		defer cancelBudget()
+
+		count, scan = budgetedCount(budget, count), budgetedScan(budget, scan)
+		if n == 1 {
+			budget.markPartial()
+			if err := scan(queryCtx); err != nil {
+				return 0, fmt.Errorf("failed to execute main query: %w", err)
+			}
+			return -1, nil
+		}
+	}
+
+	var total int
+	var countErr, scanErr error
+	if sequential {
+		if total, countErr = count(queryCtx); countErr == nil {
+			scanErr = scan(queryCtx)
+		}
+	} else {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if total, countErr = count(queryCtx); countErr != nil {
+				cancel(countErr)
+			}
+		}()
+		if scanErr = scan(queryCtx); scanErr != nil {
+			cancel(scanErr)
+		}
+		<-done
+	}
+
+	switch {
+	case countErr == nil && scanErr == nil:
+		return total, nil
+	case ctx.Err() != nil:
+		return 0, fmt.Errorf("query execution canceled: %w", ctx.Err())
+	case countErr != nil && (scanErr == nil || context.Cause(queryCtx) == countErr):
+		// The first query to fail canceled the other one, whose error is only a consequence
+		return 0, fmt.Errorf("failed to execute count query: %w", countErr)
+	default:
+		return 0, fmt.Errorf("failed to execute main query: %w", scanErr)
+	}
+}
+
+// ExecutePage applies the BunQL to the query, executes it along with its count query and returns the results and the total count
+// When a timeout is configured both queries share a context deadline, and on Postgres a statement timeout is also set
+// so the database aborts the statement even if the driver does not honor context cancellation
+func ExecutePage[T any](ctx context.Context, ql *BunQL, query *bun.SelectQuery) ([]T, int, error) {
+	results, count, err := executePage[T](ctx, ql, query)
+	ql.audit(ctx, len(results), count, err)
+	ql.afterExecute(ctx, len(results), count, err)
+	return results, count, err
+}
+
+// executePage executes a page and its count query, bounded by the configured timeout
+func executePage[T any](ctx context.Context, ql *BunQL, query *bun.SelectQuery) ([]T, int, error) {
+	ctx = ql.ContextWithSummary(ctx)
+	if err := ql.admit(ctx); err != nil {
+		return nil, 0, err
+	}
+	if err := ql.beforeExecute(ctx); err != nil {
+		return nil, 0, err
+	}
+	release, err := ql.ExecutionLimiter.acquire(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+
+	if ql.CircuitBreaker != nil {
+		return guard(ql.CircuitBreaker, func() ([]T, int, error) {
+			return executeApplied[T](ctx, ql, query)
+		})
+	}
+	return executeApplied[T](ctx, ql, query)
+}
+
+// executeApplied applies the BunQL to the query and executes the page and its count query
+func executeApplied[T any](ctx context.Context, ql *BunQL, query *bun.SelectQuery) ([]T, int, error) {
+	ctx, cancel := ql.deadline(ctx)
+	defer cancel()
+
+	mainQuery, countQuery := ql.ApplyWithCount(ctx, query)
+	mainQuery, countQuery = ql.onReplica(mainQuery), ql.onReplica(countQuery)
+
+	execute := func(ctx context.Context) ([]T, int, error) {
+		var results []T
+		var count int
+		var err error
+		if ql.Deduplicator != nil {
+			results, count, err = deduplicate(ctx, ql, mainQuery, countQuery, func(ctx context.Context) ([]T, int, error) {
+				return executeQueries[T](ctx, ql, query, mainQuery, countQuery)
+			})
+		} else {
+			results, count, err = executeQueries[T](ctx, ql, query, mainQuery, countQuery)
+		}
+		if err == nil {
+			if count < 0 {
+				recordCountMode(ctx, dto.CountUnknown)
+			} else {
+				recordCountMode(ctx, dto.CountExact)
+			}
+		}
+		return results, count, err
+	}
+	if ql.ResultCache != nil {
+		return cached(ctx, ql, mainQuery, countQuery, execute)
+	}
+	return execute(ctx)
+}
+
+// executeQueries executes the main and count queries of a page
+func executeQueries[T any](ctx context.Context, ql *BunQL, query, mainQuery, countQuery *bun.SelectQuery) ([]T, int, error) {
+	if ql.Timeout <= 0 || query.DB() == nil || query.Dialect().Name() != dialect.PG {
+		return executeHinted[T](ctx, ql, mainQuery, countQuery)
+	}
+
+	// SET LOCAL only lasts until the end of the transaction, so the pooled connection is left untouched
+	var results []T
+	var count int
+	// The transaction runs on the database the queries were routed to
+	db := query.DB()
+	if ql.Replica != nil && mainQuery.GetConn() == bun.IConn(ql.Replica.DB) {
+		db = ql.Replica
+	}
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		timeoutSQL := fmt.Sprintf("SET LOCAL statement_timeout = %d", ql.Timeout.Milliseconds())
+		if _, err := tx.ExecContext(ctx, timeoutSQL); err != nil {
+			return fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+
+		var err error
+		results, count, err = executeHinted[T](ctx, ql, mainQuery.Conn(tx), countQuery.Conn(tx))
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
+
+// executeHinted executes the main query with the statement hints of the BunQL and the count query
+func executeHinted[T any](ctx context.Context, ql *BunQL, query, countQuery *bun.SelectQuery) ([]T, int, error) {
+	query, countQuery = ql.onPrepared(query), ql.onPrepared(countQuery)
+	// The count query may have left the connection of its distinct or group count for a transaction or a replica
+	countQuery = ql.countBy(countQuery)
+	if _, ok := ql.statementHint(ql.dialectName(query)); !ok {
+		return ExecuteWithCount[T](ctx, query, countQuery)
+	}
+
+	var results []T
+	count, err := executeBoth(ctx, isSingleConn(query) || isSingleConn(countQuery), countQuery.Count,
+		func(ctx context.Context) error { return ql.Hinted(query).Scan(ctx, &results) })
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, count, nil
+}
+
+// CountResult holds the outcome of a count query executed in the background
+type CountResult struct {
+	Count int
+	Err   error
+}
+
+// ExecuteWithDeferredCount executes the main query and returns its results without waiting for the count query,
+// which runs in the background. The returned channel receives exactly one CountResult and is then closed.
+// The count query runs with ctx, so pass a context that outlives the request if the count is delivered later
+// (e.g. over a websocket); the channel is buffered, so the goroutine never leaks if the result is not read
+// On a transaction or a connection, which can't run two queries at once, the count query starts once the main query
+// is done, and the caller must not use the transaction or connection until the channel delivers the count
+// When the main query fails there, the count query is skipped and the channel delivers the error of the main query
+func ExecuteWithDeferredCount[T any](ctx context.Context, query, countQuery *bun.SelectQuery) ([]T, <-chan CountResult, error) {
+	countCh := make(chan CountResult, 1)
+	count := func() {
+		defer close(countCh)
+
+		count, err := countQuery.Count(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to execute count query: %w", err)
+		}
+		countCh <- CountResult{Count: count, Err: err}
+	}
+
+	sequential := isSingleConn(query) || isSingleConn(countQuery)
+	if !sequential {
+		go count()
 	}
 
 	// Execute the main query
 	var results []T
 	if err := query.Scan(ctx, &results); err != nil {
-		return nil, 0, fmt.Errorf("failed to execute main query: %w", err)
+		err = fmt.Errorf("failed to execute main query: %w", err)
+		if sequential {
+			countCh <- CountResult{Err: err}
+			close(countCh)
+		}
+		return nil, countCh, err
+	}
+	if sequential {
+		go count()
 	}
 
-	return results, count, nil
+	return results, countCh, nil
 }
@@ -0,0 +1,92 @@
+package bunqltest
+
+import (
+	"context"
+	"fmt"
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"strings"
+)
+
+// TestingT is the part of testing.T used by the assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertFiltersBound applies the filter group to the query with the BunQL, its field configurations, operator
+// templates and middleware included, and reports an error for every string value of the group found in the SQL
+// text outside of a literal, i.e. interpolated rather than bound as an argument of the query's prepared statement
+// Values made only of letters, digits, underscores and dots can't change the statement and aren't checked, so the
+// assertion is best run with values such as "x' OR '1'='1". It reports whether every value was bound
+func AssertFiltersBound(t TestingT, ql *bunql.BunQL, query *bun.SelectQuery, group dto.FilterGroup) bool {
+	t.Helper()
+
+	compiled := *ql
+	compiled.Filters = group
+	query = compiled.Apply(context.Background(), query)
+
+	// A query failing to render fails the test, rather than panicking like query.String()
+	sql, err := query.AppendQuery(query.DB().Formatter(), nil)
+	if err != nil {
+		t.Fatalf("failed to render the query: %v", err)
+		return false
+	}
+	rendered := string(sql)
+	_, args, err := bunql.Parameterized(query)
+	if err != nil {
+		t.Errorf("failed to compile filters: %v", err)
+		return false
+	}
+
+	bound := true
+	for _, value := range stringValues(group) {
+		if isPlainValue(value) {
+			continue
+		}
+		// A bound value appears in the SQL text only within the literals its arguments are read from
+		var inLiterals int
+		for _, arg := range args {
+			literal := query.Dialect().AppendString(nil, fmt.Sprint(arg))
+			inLiterals += strings.Count(string(literal), value)
+		}
+		if strings.Count(rendered, value) > inLiterals {
+			t.Errorf("filter value %q is interpolated into the SQL text: %s", value, rendered)
+			bound = false
+		}
+	}
+	return bound
+}
+
+// stringValues returns the string values of the filters of a group and its subgroups, list elements included
+func stringValues(group dto.FilterGroup) []string {
+	var values []string
+	for _, f := range group.Filters {
+		if s, ok := f.StringValue(); ok {
+			values = append(values, s)
+		}
+		if list, ok := f.ListValue(); ok {
+			for _, element := range list {
+				if s, ok := element.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	for _, g := range group.Groups {
+		values = append(values, stringValues(g)...)
+	}
+	return values
+}
+
+// isPlainValue reports whether a value is made only of letters, digits, underscores and dots
+func isPlainValue(value string) bool {
+	for _, r := range value {
+		if r != '_' && r != '.' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package bunqltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"testing"
+)
+
+// recordingT records the errors reported by the assertions
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertFiltersBound(t *testing.T) {
+	db := newTestDB(t)
+	payload := "x' OR '1'='1"
+	group := dto.FilterGroup{Logic: "and", Filters: []dto.Filter{
+		{Field: "name", Operator: "eq", Value: payload},
+		{Field: "name", Operator: "like", Value: "50% -- off"},
+		{Field: "email", Operator: "in", Value: []interface{}{"a@b.c", payload}},
+		{Field: "age", Operator: "gt", Value: 21},
+	}, Groups: []dto.FilterGroup{{Logic: "or", Filters: []dto.Filter{
+		{Field: "bio", Operator: "contains", Value: "1); DROP TABLE users; --"},
+	}}}}
+
+	assert.True(t, AssertFiltersBound(t, bunql.New(), db.NewSelect().Table("users"), group))
+	assert.True(t, AssertFiltersBound(t, bunql.New().WithFieldConfig("name", dto.FieldConfig{CaseSensitive: new(bool)}),
+		db.NewSelect().Table("users"), group))
+
+	// A middleware interpolating a value into the SQL text is reported
+	unsafe := bunql.New().WithMiddleware(func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			value, _ := ql.Filters.Filters[0].StringValue()
+			return next(ctx, ql, query.Where("nickname = '"+value+"'"))
+		}
+	})
+	recorder := &recordingT{}
+	assert.False(t, AssertFiltersBound(recorder, unsafe, db.NewSelect().Table("users"), group))
+	assert.Len(t, recorder.errors, 2)
+	assert.Contains(t, recorder.errors[0], payload)
+
+	// A query failing to render fails the test instead of panicking
+	recorder = &recordingT{}
+	assert.False(t, AssertFiltersBound(recorder, bunql.New(), db.NewSelect().Table("users").Err(errors.New("bad query")), group))
+	assert.Equal(t, []string{"failed to render the query: bad query"}, recorder.errors)
+}
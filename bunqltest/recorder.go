@@ -0,0 +1,141 @@
+// Package bunqltest provides helpers for testing code using bunql without a database
+package bunqltest
+
+import (
+	"context"
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"strings"
+	"sync"
+)
+
+// Record is what a BunQL applied to a query
+type Record struct {
+	Filters    dto.FilterGroup
+	Sort       []dto.SortField
+	Pagination *dto.Pagination
+	Search     *dto.Search
+	Cursor     *cursor.Cursor
+	MaxRows    int
+	Count      bool // Whether the query was the count query of ApplyWithCount
+}
+
+// HasFilter reports whether a filter on the field with the operator was applied, in any group
+func (r Record) HasFilter(field, operator string) bool {
+	_, ok := findFilter(r.Filters, field, operator)
+	return ok
+}
+
+// FilterValue returns the value of the first filter on the field with the operator, in any group
+func (r Record) FilterValue(field, operator string) (interface{}, bool) {
+	return findFilter(r.Filters, field, operator)
+}
+
+// HasSort reports whether the results were sorted on the field in the direction
+func (r Record) HasSort(field, direction string) bool {
+	for _, sort := range r.Sort {
+		if sort.Field == field && sort.Direction.Normalized() == dto.Direction(direction).Normalized() {
+			return true
+		}
+	}
+	return false
+}
+
+// Page returns the page and page size applied, both zero without pagination
+func (r Record) Page() (page, pageSize int) {
+	if r.Pagination == nil {
+		return 0, 0
+	}
+	return r.Pagination.Page, r.Pagination.PageSize
+}
+
+// findFilter returns the value of the first filter of the group or its subgroups on the field with the operator
+func findFilter(group dto.FilterGroup, field, operator string) (interface{}, bool) {
+	for _, f := range group.Filters {
+		if f.Field == field && strings.EqualFold(f.Operator, operator) {
+			return f.Value, true
+		}
+	}
+	for _, g := range group.Groups {
+		if value, ok := findFilter(g, field, operator); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Recorder records what BunQLs apply to queries, so that handlers can be unit-tested without a database
+// Its middleware is added with WithMiddleware, or to every BunQL by registering the recorder with bunql.Use
+// By default the queries are left unchanged, set Passthrough to also apply the BunQLs
+type Recorder struct {
+	Passthrough bool
+
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewRecorder creates a Recorder leaving the queries unchanged
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Name implements bunql.Plugin
+func (r *Recorder) Name() string {
+	return "bunqltest.Recorder"
+}
+
+// Middleware returns the middleware recording the BunQLs applied, implementing bunql.ApplyPlugin
+func (r *Recorder) Middleware() bunql.Middleware {
+	return func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			r.record(ctx, ql)
+			if r.Passthrough {
+				return next(ctx, ql, query)
+			}
+			return query
+		}
+	}
+}
+
+// record appends the record of a BunQL applied
+func (r *Recorder) record(ctx context.Context, ql *bunql.BunQL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, Record{
+		Filters:    ql.Filters,
+		Sort:       append([]dto.SortField{}, ql.Sort...),
+		Pagination: ql.Pagination,
+		Search:     ql.Search,
+		Cursor:     ql.Cursor,
+		MaxRows:    ql.MaxRows,
+		Count:      bunql.IsCount(ctx),
+	})
+}
+
+// Records returns the records, in the order the BunQLs were applied
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record{}, r.records...)
+}
+
+// Last returns the last record of a main query, i.e. not the count query of ApplyWithCount
+func (r *Recorder) Last() (Record, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if !r.records[i].Count {
+			return r.records[i], true
+		}
+	}
+	return Record{}, false
+}
+
+// Reset forgets the records
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
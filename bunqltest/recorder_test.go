@@ -0,0 +1,80 @@
+package bunqltest
+
+import (
+	"context"
+	"database/sql"
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"testing"
+)
+
+// newTestDB returns a database used to build queries, which are never executed
+func newTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { sqldb.Close() })
+	return bun.NewDB(sqldb, sqlitedialect.New())
+}
+
+func TestRecorder(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	ql, err := bunql.ParseFromParams(
+		`{"logic":"and","filters":[{"field":"age","operator":"gt","value":21}],"groups":[{"logic":"or","filters":[{"field":"name","operator":"eq","value":"John"}]}]}`,
+		`[{"field":"age","dir":"desc"}]`, 2, 10)
+	require.NoError(t, err)
+
+	recorder := NewRecorder()
+	query, countQuery := ql.WithMiddleware(recorder.Middleware()).ApplyWithCount(ctx, db.NewSelect().Table("users"))
+
+	// The queries are left unchanged
+	assert.Equal(t, `SELECT * FROM "users"`, query.String())
+	assert.Equal(t, `SELECT * FROM "users"`, countQuery.String())
+
+	records := recorder.Records()
+	require.Len(t, records, 2)
+	assert.False(t, records[0].Count)
+	assert.True(t, records[1].Count)
+
+	last, ok := recorder.Last()
+	require.True(t, ok)
+	assert.True(t, last.HasFilter("age", "gt"))
+	assert.True(t, last.HasFilter("name", "eq"))
+	assert.False(t, last.HasFilter("name", "neq"))
+	value, ok := last.FilterValue("name", "eq")
+	require.True(t, ok)
+	assert.Equal(t, "John", value)
+	assert.True(t, last.HasSort("age", "DESC"))
+	assert.False(t, last.HasSort("age", "asc"))
+	page, pageSize := last.Page()
+	assert.Equal(t, 2, page)
+	assert.Equal(t, 10, pageSize)
+
+	recorder.Reset()
+	assert.Empty(t, recorder.Records())
+	_, ok = recorder.Last()
+	assert.False(t, ok)
+}
+
+func TestRecorderPassthrough(t *testing.T) {
+	db := newTestDB(t)
+
+	recorder := NewRecorder()
+	recorder.Passthrough = true
+	ql := bunql.New().WithPagination(nil).WithMiddleware(recorder.Middleware())
+	ql.Filters.Filters = append(ql.Filters.Filters, bunql.Filter{Field: "age", Operator: "gt", Value: 21})
+
+	query := ql.Apply(context.Background(), db.NewSelect().Table("users"))
+	assert.Contains(t, query.String(), `"age" > 21`)
+
+	records := recorder.Records()
+	require.Len(t, records, 1)
+	page, pageSize := records[0].Page()
+	assert.Zero(t, page)
+	assert.Zero(t, pageSize)
+}
@@ -0,0 +1,91 @@
+package bunql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"strconv"
+	"time"
+)
+
+// ResultCache stores serialized pages for ExecutePage, e.g. in Redis or Memcached, so that hot and rarely changing
+// listings such as public catalogs are served without querying the database. Implementations must be safe for
+// concurrent use
+type ResultCache interface {
+	// Get returns the value stored under the key, and whether there is one
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a value under the key for the given time to live
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// WithResultCache makes ExecutePage serve pages from the cache, storing the pages it executes for the time to live
+// Pages are cached under the hash of their result type and of the SQL of their queries, so pages of different
+// filters, sorts or tenants never share an entry, and are invalidated per table with InvalidateResults. Cache errors
+// don't fail the execution: the page is then read from the database
+func (q *BunQL) WithResultCache(cache ResultCache, ttl time.Duration) *BunQL {
+	q.ResultCache = cache
+	q.ResultCacheTTL = ttl
+	return q
+}
+
+// InvalidateResults invalidates the pages cached for the tables, e.g. after writing to them, by moving the tables
+// to a new generation: the keys of their pages change, and the old entries expire with their time to live
+func InvalidateResults(ctx context.Context, cache ResultCache, tables ...string) error {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	for _, table := range tables {
+		if err := cache.Set(ctx, generationKey(table), []byte(generation), 0); err != nil {
+			return fmt.Errorf("failed to invalidate the results of table '%s': %w", table, err)
+		}
+	}
+	return nil
+}
+
+// generationKey returns the cache key of the generation of the pages of a table
+func generationKey(table string) string {
+	return "bunql:generation:" + table
+}
+
+// cachedPage is the serialized form of a cached page
+type cachedPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+// cached serves a page from the result cache of the BunQL, or executes it and caches it
+// Pages whose count was skipped by the query budget are not cached, and neither are the pages read on a transaction
+// or a connection, which may see uncommitted rows
+func cached[T any](ctx context.Context, ql *BunQL, mainQuery, countQuery *bun.SelectQuery, execute func(ctx context.Context) ([]T, int, error)) ([]T, int, error) {
+	if isSingleConn(mainQuery) || isSingleConn(countQuery) {
+		return execute(ctx)
+	}
+	model, ok := mainQuery.GetModel().(bun.TableModel)
+	if !ok {
+		return execute(ctx)
+	}
+	table := model.Table().Name
+
+	generation, _, err := ql.ResultCache.Get(ctx, generationKey(table))
+	if err != nil {
+		return execute(ctx)
+	}
+	key := fmt.Sprintf("bunql:results:%s:%s:%s", table, generation, pageKey[T](mainQuery, countQuery))
+
+	if data, ok, err := ql.ResultCache.Get(ctx, key); err == nil && ok {
+		var page cachedPage[T]
+		if json.Unmarshal(data, &page) == nil {
+			recordCountMode(ctx, dto.CountCached)
+			return page.Items, page.Total, nil
+		}
+	}
+
+	results, count, err := execute(ctx)
+	if err != nil || count < 0 {
+		return results, count, err
+	}
+	if data, err := json.Marshal(cachedPage[T]{Items: results, Total: count}); err == nil {
+		_ = ql.ResultCache.Set(ctx, key, data, ql.ResultCacheTTL)
+	}
+	return results, count, nil
+}
@@ -0,0 +1,238 @@
+package bunql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is wrapped by the errors of the executions rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// probeRetryAfter is how long the executions rejected while the probes of a half-open breaker run are told to wait,
+// at most the open timeout: the circuit closes as soon as a probe succeeds
+const probeRetryAfter = time.Second
+
+// CircuitOpenError is returned by ExecutePage when the circuit breaker rejects the execution, the database having
+// recently failed or been slow. It wraps ErrCircuitOpen
+type CircuitOpenError struct {
+	RetryAfter time.Duration // Time until the breaker lets a probe execution through, e.g. for a Retry-After header
+}
+
+// Error returns the message of the error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCircuitOpen, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap returns ErrCircuitOpen
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Executions go through
+	CircuitOpen                         // Executions are rejected
+	CircuitHalfOpen                     // Probe executions go through to check whether the database recovered
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerConfig configures a circuit breaker; zero fields take the default values
+type CircuitBreakerConfig struct {
+	// FailureRate is the rate of failed executions over a window that opens the circuit, 0.5 by default
+	FailureRate float64
+	// SlowThreshold makes the executions slower than it count as failures; latency is not considered if zero
+	SlowThreshold time.Duration
+	// MinRequests is the number of executions a window needs before its failure rate is considered, 10 by default
+	MinRequests int
+	// Window is the period the failure rate is measured over, 10 seconds by default
+	Window time.Duration
+	// OpenTimeout is how long the circuit stays open before letting probe executions through, 30 seconds by default
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of probe executions let through at once while half-open, 1 by default
+	HalfOpenProbes int
+	// OnStateChange is called when the state of the breaker changes, e.g. to log it or export a metric
+	OnStateChange func(from, to CircuitState)
+	// IsFailure reports whether the error of an execution counts as a failure. By default only timeouts and connection
+	// errors do, while the errors of the query itself, such as a missing column, count as successful executions
+	IsFailure func(err error) bool
+}
+
+// CircuitBreaker makes the executions fail fast while the database is struggling, instead of piling on
+// It opens when the rate of failed or slow executions over a window exceeds the threshold, rejects the executions
+// with a CircuitOpenError while open, then lets probe executions through: the circuit closes on a successful probe
+// and opens again on a failed one. Executions canceled by their caller, or cut short by the query budget of the
+// request, are not counted. A CircuitBreaker is safe for concurrent use and is meant to be shared by the BunQLs
+// querying a database
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureRate <= 0 {
+		config.FailureRate = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isConnectionFailure
+	}
+	return &CircuitBreaker{config: config, windowStart: time.Now()}
+}
+
+// WithCircuitBreaker makes ExecutePage go through the circuit breaker
+func (q *BunQL) WithCircuitBreaker(b *CircuitBreaker) *BunQL {
+	q.CircuitBreaker = b
+	return q
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether an execution may go through, returning a CircuitOpenError if not
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+
+	if b.state == CircuitOpen {
+		if wait := b.config.OpenTimeout - time.Since(b.openedAt); wait > 0 {
+			b.mu.Unlock()
+			return &CircuitOpenError{RetryAfter: wait}
+		}
+		b.state = CircuitHalfOpen
+		b.probes = 0
+	}
+	if b.state == CircuitHalfOpen {
+		if b.probes >= b.config.HalfOpenProbes {
+			b.mu.Unlock()
+			return &CircuitOpenError{RetryAfter: min(b.config.OpenTimeout, probeRetryAfter)}
+		}
+		b.probes++
+	}
+
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return nil
+}
+
+// record counts the outcome of an execution, opening or closing the circuit
+func (b *CircuitBreaker) record(err error, latency time.Duration) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrBudgetExceeded) {
+		b.mu.Lock()
+		if b.state == CircuitHalfOpen {
+			b.probes--
+		}
+		b.mu.Unlock()
+		return
+	}
+	failed := (err != nil && b.config.IsFailure(err)) || (b.config.SlowThreshold > 0 && latency > b.config.SlowThreshold)
+
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case CircuitHalfOpen:
+		if failed {
+			b.open()
+		} else {
+			b.close()
+		}
+	case CircuitClosed:
+		if time.Since(b.windowStart) >= b.config.Window {
+			b.windowStart, b.requests, b.failures = time.Now(), 0, 0
+		}
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRate {
+			b.open()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// isConnectionFailure reports whether an error is a timeout or a connection error, the errors of a database that is
+// struggling or unreachable
+func isConnectionFailure(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// open opens the circuit, the lock being held
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.openedAt = time.Now()
+}
+
+// close closes the circuit and starts a new window, the lock being held
+func (b *CircuitBreaker) close() {
+	b.state = CircuitClosed
+	b.windowStart, b.requests, b.failures = time.Now(), 0, 0
+}
+
+// notify calls the state change hook if the state changed
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(from, to)
+	}
+}
+
+// guard runs an execution through the circuit breaker
+func guard[T any](b *CircuitBreaker, execute func() ([]T, int, error)) ([]T, int, error) {
+	if err := b.allow(); err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
+	results, count, err := execute()
+	b.record(err, time.Since(start))
+	return results, count, err
+}
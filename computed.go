@@ -0,0 +1,93 @@
+package bunql
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
+	"github.com/uptrace/bun"
+	"maps"
+)
+
+// ComputedField is a field standing for an expression the base query selects under its name, e.g. total for
+// SUM(i.price) AS total, so that clients filter and sort on it like on a column. The expression is configured by the
+// server and must never be built from user input
+type ComputedField struct {
+	Expression string // SQL expression of the field, e.g. "SUM(i.price)"
+	Aggregate  bool   // Whether the expression aggregates the rows of a group, so that it is filtered in HAVING
+}
+
+// WithComputedField declares a field computed by the base query, filtered on its expression in WHERE, or in HAVING
+// if it is an aggregate, and sorted on its alias. Like columns, computed fields must be allowed to be filtered and
+// sorted on. Filters joined with OR to filters on aggregates go to HAVING with them, so the columns they compare must
+// be grouped by. Cursor pagination can't page over aggregates
+func (q *BunQL) WithComputedField(name string, field ComputedField) *BunQL {
+	fields := maps.Clone(q.ComputedFields)
+	if fields == nil {
+		fields = map[string]ComputedField{}
+	}
+	fields[name] = field
+	q.ComputedFields = fields
+	return q
+}
+
+// computedExpressions returns the expressions of the computed fields, or nil if there are none
+func (q *BunQL) computedExpressions() map[string]string {
+	if len(q.ComputedFields) == 0 {
+		return nil
+	}
+	expressions := make(map[string]string, len(q.ComputedFields))
+	for name, field := range q.ComputedFields {
+		expressions[name] = field.Expression
+	}
+	return expressions
+}
+
+// applyFilters applies the filters of the query, those on aggregates in HAVING and the others in WHERE
+func (q *BunQL) applyFilters(query *bun.SelectQuery) *bun.SelectQuery {
+	where, having := q.splitAggregateFilters(q.Filters)
+	query = filter.ApplyFilterGroupWithOptions(query, where, q.filterOptions())
+	return filter.ApplyHavingGroupWithOptions(query, having, q.filterOptions())
+}
+
+// splitAggregateFilters splits the members of an AND group into those on aggregates, applied in HAVING, and the others
+// An OR group is filtered in HAVING as a whole as soon as one of its members is on an aggregate
+func (q *BunQL) splitAggregateFilters(group dto.FilterGroup) (where, having dto.FilterGroup) {
+	if !q.hasAggregateFilter(group) {
+		return group, dto.FilterGroup{}
+	}
+	if group.IsOr() {
+		return dto.FilterGroup{}, group
+	}
+
+	where = dto.FilterGroup{Logic: group.Logic, Scope: group.Scope}
+	having = dto.FilterGroup{Logic: group.Logic, Scope: group.Scope}
+	for _, f := range group.Filters {
+		if q.ComputedFields[f.Field].Aggregate {
+			having.Filters = append(having.Filters, f)
+		} else {
+			where.Filters = append(where.Filters, f)
+		}
+	}
+	for _, nested := range group.Groups {
+		if q.hasAggregateFilter(nested) {
+			having.Groups = append(having.Groups, nested)
+		} else {
+			where.Groups = append(where.Groups, nested)
+		}
+	}
+	return where, having
+}
+
+// hasAggregateFilter reports whether a group or one of its nested groups filters on an aggregate
+func (q *BunQL) hasAggregateFilter(group dto.FilterGroup) bool {
+	for _, f := range group.Filters {
+		if q.ComputedFields[f.Field].Aggregate {
+			return true
+		}
+	}
+	for _, nested := range group.Groups {
+		if q.hasAggregateFilter(nested) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
+	"github.com/uptrace/bun"
+	"slices"
+)
+
+// ContextFilter materializes a filter from a value of the request context at Apply time, e.g. the organization ID
+// or the locale set by authentication middleware, so that handlers don't have to thread the value themselves
+type ContextFilter struct {
+	Key      interface{} // Context key the value is read from
+	Field    string      // Field filtered on
+	Operator string      // Operator of the filter; eq if empty
+	Optional bool        // Whether the filter is skipped when the context has no value; otherwise nothing matches
+}
+
+// WithContextFilters adds filters whose values are read from the context of each Apply, for both the main and the
+// count queries. They are configured by the server, so they are not checked against the allowed filter fields
+func (q *BunQL) WithContextFilters(filters ...ContextFilter) *BunQL {
+	q.ContextFilters = append(slices.Clip(q.ContextFilters), filters...)
+	return q
+}
+
+// applyContextFilters applies the filters whose values are read from the context
+// A missing value of a required filter matches nothing, so that a tenant filter can't be skipped by mistake
+func (q *BunQL) applyContextFilters(ctx context.Context, query *bun.SelectQuery) *bun.SelectQuery {
+	for _, cf := range q.ContextFilters {
+		value := ctx.Value(cf.Key)
+		if value == nil {
+			if !cf.Optional {
+				query = query.Where("1 = 0")
+			}
+			continue
+		}
+
+		op := cf.Operator
+		if op == "" {
+			op = "eq"
+		}
+		query = filter.ApplyFilterWithOptions(query, dto.Filter{Field: cf.Field, Operator: op, Value: value}, q.filterOptions())
+	}
+	return query
+}
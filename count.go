@@ -0,0 +1,127 @@
+package bunql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/uptrace/bun"
+	"strings"
+)
+
+// CountStrategy is how the count query of ApplyWithCount counts the rows matching the filters
+type CountStrategy int
+
+const (
+	// CountAuto counts the distinct primary keys of the model when the query joins other tables, and the rows otherwise
+	CountAuto CountStrategy = iota
+	// CountRows counts the rows with COUNT(*)
+	CountRows
+	// CountDistinct counts the distinct primary keys of the model, so that rows multiplied by joins are counted once
+	CountDistinct
+	// CountGroups counts the rows of an aggregate query: the groups of its GROUP BY, or its single row without one
+	CountGroups
+)
+
+// WithCountStrategy sets how the count query of ApplyWithCount counts the rows, CountAuto by default
+// Distinct counts are COUNT(DISTINCT pk), or a count over a subquery selecting the distinct keys of a composite
+// primary key. They need a model with a primary key selected from its table, and fall back to COUNT(*) otherwise, as
+// do grouped and DISTINCT queries. Group counts are counts over a subquery aggregating the rows, and CountAuto uses
+// them when an aggregate computed field is declared; queries with a GROUP BY are counted by group anyway, bun
+// wrapping them in a CTE. Query hooks still see the count query as COUNT(*)
+func (q *BunQL) WithCountStrategy(strategy CountStrategy) *BunQL {
+	q.CountStrategy = strategy
+	return q
+}
+
+// distinctCountConn executes the count queries of a model as counts of its distinct primary keys
+type distinctCountConn struct {
+	bun.IConn
+	// from is the table of the model, as the count queries select from it
+	from string
+	// keys are the qualified primary key columns of the model
+	keys []string
+}
+
+// groupCountConn executes the count queries of aggregate queries as counts of their groups
+type groupCountConn struct {
+	bun.IConn
+}
+
+// countBy makes the count query count the groups of an aggregate query, or the distinct primary keys of its model,
+// if the count strategy calls for it
+func (q *BunQL) countBy(query *bun.SelectQuery) *bun.SelectQuery {
+	conn := baseConn(query.GetConn())
+	if conn == nil || q.CountStrategy == CountRows {
+		return query
+	}
+	if q.CountStrategy == CountGroups || (q.CountStrategy == CountAuto && q.hasAggregateField()) {
+		return query.Conn(groupCountConn{IConn: conn})
+	}
+	return q.countDistinct(query, conn)
+}
+
+// hasAggregateField reports whether an aggregate computed field is declared
+func (q *BunQL) hasAggregateField() bool {
+	for _, field := range q.ComputedFields {
+		if field.Aggregate {
+			return true
+		}
+	}
+	return false
+}
+
+// countDistinct makes the count query count the distinct primary keys of its model
+func (q *BunQL) countDistinct(query *bun.SelectQuery, conn bun.IConn) *bun.SelectQuery {
+	model, ok := query.GetModel().(bun.TableModel)
+	if !ok || len(model.Table().PKs) == 0 {
+		return query
+	}
+	table := model.Table()
+
+	from := string(table.SQLNameForSelects)
+	if table.SQLAlias != table.SQLNameForSelects {
+		from += " AS " + string(table.SQLAlias)
+	}
+	sql := querySQL(query)
+	if !strings.Contains(sql, " FROM "+from) || (q.CountStrategy == CountAuto && !strings.Contains(sql, " JOIN ")) {
+		return query
+	}
+
+	keys := make([]string, 0, len(table.PKs))
+	for _, pk := range table.PKs {
+		keys = append(keys, string(table.SQLAlias)+"."+string(pk.SQLName))
+	}
+	return query.Conn(distinctCountConn{IConn: conn, from: from, keys: keys})
+}
+
+// baseConn returns the connection a query runs on, without the distinct or group count of its count query
+func baseConn(conn bun.IConn) bun.IConn {
+	switch c := conn.(type) {
+	case distinctCountConn:
+		return c.IConn
+	case groupCountConn:
+		return c.IConn
+	}
+	return conn
+}
+
+// QueryRowContext executes the count query of the model as a count of its distinct primary keys
+func (c distinctCountConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	rest, ok := strings.CutPrefix(query, "SELECT count(*) FROM ")
+	if ok && strings.HasPrefix(rest, c.from) {
+		if len(c.keys) == 1 {
+			query = "SELECT count(DISTINCT " + c.keys[0] + ") FROM " + rest
+		} else {
+			query = "SELECT count(*) FROM (SELECT DISTINCT " + strings.Join(c.keys, ", ") + " FROM " + rest + ") AS _count_rows"
+		}
+	}
+	return c.IConn.QueryRowContext(ctx, query, args...)
+}
+
+// QueryRowContext executes the count query of an aggregate query as a count of its groups, aggregating its rows in
+// a subquery, whose GROUP BY and HAVING are kept
+func (c groupCountConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if rest, ok := strings.CutPrefix(query, "SELECT count(*) FROM "); ok {
+		query = "SELECT count(*) FROM (SELECT count(*) AS _count_group FROM " + rest + ") AS _count_groups"
+	}
+	return c.IConn.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,43 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+	"sync"
+)
+
+// countModeKey is the context key of the count mode tracked for the pages executed with a context
+type countModeKey struct{}
+
+// countModeRecord is the count mode of the last page executed with a context
+type countModeRecord struct {
+	mu   sync.Mutex
+	mode dto.CountMode
+}
+
+// TrackCountMode returns a context recording how ExecutePage obtains the total counts of the pages executed with it,
+// counted, served from the result cache or skipped by the query budget, read with CountModeFromContext
+func TrackCountMode(ctx context.Context) context.Context {
+	return context.WithValue(ctx, countModeKey{}, &countModeRecord{})
+}
+
+// CountModeFromContext returns how the total count of the last page executed with the context was obtained, or an
+// empty mode if the context doesn't track it or no page was executed
+func CountModeFromContext(ctx context.Context) dto.CountMode {
+	record, ok := ctx.Value(countModeKey{}).(*countModeRecord)
+	if !ok {
+		return ""
+	}
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	return record.mode
+}
+
+// recordCountMode records the count mode of a page executed with the context, if it is tracked
+func recordCountMode(ctx context.Context, mode dto.CountMode) {
+	if record, ok := ctx.Value(countModeKey{}).(*countModeRecord); ok {
+		record.mu.Lock()
+		defer record.mu.Unlock()
+		record.mode = mode
+	}
+}
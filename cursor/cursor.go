@@ -0,0 +1,260 @@
+package cursor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/sorting"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a cursor token is malformed or its signature does not match
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a row in a keyset-paginated result set
+type Cursor struct {
+	Sort     []dto.SortField `json:"sort"`               // Sort the cursor was created for
+	Values   []interface{}   `json:"values"`             // Values of the sort fields in the row the cursor points at
+	Backward bool            `json:"backward,omitempty"` // Whether the cursor selects the rows before the row instead of after it
+}
+
+// cursorJSON is the JSON form of a cursor, whose value types tell how the values are read back
+type cursorJSON struct {
+	Sort       []dto.SortField `json:"sort"`
+	Values     []interface{}   `json:"values"`
+	ValueTypes []string        `json:"valueTypes,omitempty"`
+	Backward   bool            `json:"backward,omitempty"`
+}
+
+// MarshalJSON marshals the cursor, marking the times among its values with dto.ValueTime
+func (c Cursor) MarshalJSON() ([]byte, error) {
+	out := cursorJSON{Sort: c.Sort, Values: c.Values, Backward: c.Backward}
+	for i, value := range c.Values {
+		if _, ok := value.(time.Time); ok {
+			if out.ValueTypes == nil {
+				out.ValueTypes = make([]string, len(c.Values))
+			}
+			out.ValueTypes[i] = dto.ValueTime
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON unmarshals the cursor, reading integers as int64 so large keys keep their precision, other numbers
+// as float64 and the values marked with dto.ValueTime as times, so they compare with the column they were read from
+func (c *Cursor) UnmarshalJSON(data []byte) error {
+	var in cursorJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&in); err != nil {
+		return err
+	}
+	if in.ValueTypes != nil && len(in.ValueTypes) != len(in.Values) {
+		return errors.New("cursor value types don't match its values")
+	}
+
+	for i, sort := range in.Sort {
+		if n, ok := sort.NullsAs.(json.Number); ok {
+			in.Sort[i].NullsAs = number(n)
+		}
+	}
+	for i, value := range in.Values {
+		switch v := value.(type) {
+		case json.Number:
+			in.Values[i] = number(v)
+		case string:
+			if in.ValueTypes != nil && in.ValueTypes[i] == dto.ValueTime {
+				t, err := time.Parse(time.RFC3339Nano, v)
+				if err != nil {
+					return errors.New("cursor time value is not an RFC 3339 string")
+				}
+				in.Values[i] = t
+			}
+		}
+	}
+
+	*c = Cursor{Sort: in.Sort, Values: in.Values, Backward: in.Backward}
+	return nil
+}
+
+// number converts a JSON number to an int64, keeping large identifiers exact, or to a float64
+func number(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+// Encode serializes the cursor into an opaque token signed with secret
+func Encode(c Cursor, secret []byte) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("cursor secret cannot be empty")
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(sign(payload, secret)), nil
+}
+
+// Decode parses a token created by Encode and verifies its signature
+func Decode(token string, secret []byte) (*Cursor, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cursor secret cannot be empty")
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	encoding := base64.RawURLEncoding
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !hmac.Equal(signature, sign(payload, secret)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if len(c.Values) != len(c.Sort) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// Matches reports whether the cursor was created for the given sort, NULLs sorted as the same value
+func (c *Cursor) Matches(sortFields []dto.SortField) bool {
+	if len(c.Sort) != len(sortFields) {
+		return false
+	}
+	for i := range sortFields {
+		if c.Sort[i].Field != sortFields[i].Field || c.Sort[i].Direction.Normalized() != sortFields[i].Direction.Normalized() {
+			return false
+		}
+		// Numbers are int64s in decoded cursors and may be float64s in parsed sorts
+		if (c.Sort[i].NullsAs == nil) != (sortFields[i].NullsAs == nil) ||
+			fmt.Sprint(c.Sort[i].NullsAs) != fmt.Sprint(sortFields[i].NullsAs) {
+			return false
+		}
+	}
+	return true
+}
+
+// Invert returns the sort fields with every direction reversed
+func Invert(sortFields []dto.SortField) []dto.SortField {
+	inverted := make([]dto.SortField, len(sortFields))
+	for i, sort := range sortFields {
+		sort.Direction = sort.Direction.Inverted()
+		inverted[i] = sort
+	}
+	return inverted
+}
+
+// ApplyCursor restricts the query to the rows that come after the cursor in the cursor's sort order,
+// or before it for a backward cursor. A backward cursor must be combined with the inverted sort (see Invert)
+// so that the rows closest to the cursor come first
+// When every sort field has the same direction and the dialect supports it, a single row-value comparison
+// such as (a, b) > (?, ?) is emitted; otherwise the comparison is expanded to (a > ?) OR (a = ? AND b < ?) ...
+func ApplyCursor(query *bun.SelectQuery, c *Cursor) *bun.SelectQuery {
+	return ApplyCursorWithOptions(query, c, sorting.Options{})
+}
+
+// ApplyCursorWithOptions applies the cursor like ApplyCursor, comparing the rows on the expressions the sort options
+// order them on: collated or folded fields, computed fields and fields with their NULLs replaced
+func ApplyCursorWithOptions(query *bun.SelectQuery, c *Cursor, opts sorting.Options) *bun.SelectQuery {
+	if c == nil || len(c.Sort) == 0 {
+		return query
+	}
+
+	sortFields := c.Sort
+	if c.Backward {
+		sortFields = Invert(sortFields)
+	}
+
+	columns := make([]interface{}, len(sortFields))
+	values := make([]interface{}, len(sortFields))
+	for i, sort := range sortFields {
+		columns[i] = opts.Column(query, sort)
+		values[i] = opts.Value(query, sort, c.Values[i])
+	}
+
+	if sameDirection(sortFields) && supportsRowComparison(query.Dialect().Name()) {
+		placeholders := strings.Repeat(", ?", len(sortFields))[2:]
+		args := make([]interface{}, 0, 2*len(sortFields))
+		args = append(args, columns...)
+		args = append(args, values...)
+
+		expr := fmt.Sprintf("(%s) %s (%s)", placeholders, comparison(sortFields[0].Direction), placeholders)
+		return query.Where(expr, args...)
+	}
+
+	var terms []string
+	var args []interface{}
+	for i, sort := range sortFields {
+		var conditions []string
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, "? = ?")
+			args = append(args, columns[j], values[j])
+		}
+		conditions = append(conditions, fmt.Sprintf("? %s ?", comparison(sort.Direction)))
+		args = append(args, columns[i], values[i])
+
+		terms = append(terms, "("+strings.Join(conditions, " AND ")+")")
+	}
+
+	return query.Where(strings.Join(terms, " OR "), args...)
+}
+
+// comparison returns the operator selecting the rows after a value for a sort direction
+func comparison(direction dto.Direction) string {
+	if direction.IsDesc() {
+		return "<"
+	}
+	return ">"
+}
+
+// sameDirection reports whether all the sort fields have the same direction
+func sameDirection(sortFields []dto.SortField) bool {
+	for _, sort := range sortFields[1:] {
+		if sort.Direction.IsDesc() != sortFields[0].Direction.IsDesc() {
+			return false
+		}
+	}
+	return true
+}
+
+// supportsRowComparison reports whether the dialect supports row-value comparisons
+func supportsRowComparison(name dialect.Name) bool {
+	return name == dialect.PG || name == dialect.MySQL || name == dialect.SQLite
+}
+
+// sign computes the HMAC-SHA256 signature of the payload
+func sign(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
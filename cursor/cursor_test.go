@@ -0,0 +1,124 @@
+package cursor
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/sorting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	secret := []byte("secret")
+	c := Cursor{
+		Sort: []dto.SortField{
+			{Field: "last_name", Direction: "asc"},
+			{Field: "id", Direction: "desc"},
+		},
+		Values: []interface{}{"Smith", int64(9007199254740993)},
+	}
+
+	token, err := Encode(c, secret)
+	require.NoError(t, err)
+
+	decoded, err := Decode(token, secret)
+	require.NoError(t, err)
+	assert.Equal(t, c.Sort, decoded.Sort)
+	assert.Equal(t, c.Values, decoded.Values)
+	assert.True(t, decoded.Matches(c.Sort))
+	assert.False(t, decoded.Matches(c.Sort[:1]))
+
+	// A token signed with another secret is rejected
+	_, err = Decode(token, []byte("other"))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// A tampered payload is rejected
+	_, err = Decode("e30"+token[3:], secret)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = Decode("garbage", secret)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// Time values are decoded back to times rather than strings
+	at := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	token, err = Encode(Cursor{
+		Sort:   []dto.SortField{{Field: "created_at", Direction: "asc"}, {Field: "id", Direction: "asc"}},
+		Values: []interface{}{at, int64(7)},
+	}, secret)
+	require.NoError(t, err)
+	decoded, err = Decode(token, secret)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{at, int64(7)}, decoded.Values)
+}
+
+func TestApplyCursor(t *testing.T) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	defer sqldb.Close()
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	tests := []struct {
+		name     string
+		cursor   *Cursor
+		expected string
+	}{
+		{
+			name: "Row comparison for a single direction",
+			cursor: &Cursor{
+				Sort:   []dto.SortField{{Field: "age", Direction: "desc"}, {Field: "id", Direction: "desc"}},
+				Values: []interface{}{30, 7},
+			},
+			expected: `SELECT * FROM "users" WHERE (("age", "id") < (30, 7))`,
+		},
+		{
+			name: "OR expansion for mixed directions",
+			cursor: &Cursor{
+				Sort:   []dto.SortField{{Field: "age", Direction: "asc"}, {Field: "id", Direction: "desc"}},
+				Values: []interface{}{30, 7},
+			},
+			expected: `SELECT * FROM "users" WHERE (("age" > 30) OR ("age" = 30 AND "id" < 7))`,
+		},
+		{
+			name:     "No cursor",
+			cursor:   nil,
+			expected: `SELECT * FROM "users"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := ApplyCursor(db.NewSelect().TableExpr(`"users"`), tt.cursor)
+			assert.Equal(t, tt.expected, query.String())
+		})
+	}
+}
+
+func TestApplyCursorWithOptions(t *testing.T) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	defer sqldb.Close()
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	caseSensitive := false
+	opts := sorting.Options{Fields: map[string]dto.FieldConfig{"name": {CaseSensitive: &caseSensitive}}}
+	c := &Cursor{
+		Sort: []dto.SortField{
+			{Field: "name", Direction: "asc"},
+			{Field: "age", Direction: "asc", NullsAs: 0},
+			{Field: "id", Direction: "asc"},
+		},
+		Values:   []interface{}{"Smith", 0, 7},
+		Backward: true,
+	}
+
+	// The backward comparison keeps the folded and NULL-replaced expressions of the sort
+	query := ApplyCursorWithOptions(db.NewSelect().TableExpr(`"users"`), c, opts)
+	assert.Equal(t, `SELECT * FROM "users" WHERE ((LOWER("name"), COALESCE("age", 0), "id") < ('smith', 0, 7))`, query.String())
+	assert.Equal(t, 0, Invert(c.Sort)[1].NullsAs)
+}
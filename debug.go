@@ -0,0 +1,68 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/sorting"
+	"sync"
+)
+
+// debugKey is the context key of the debug information recorded for the queries applied with a context
+type debugKey struct{}
+
+// debugRecord is the debug information of the last query applied with a context
+type debugRecord struct {
+	mu   sync.Mutex
+	info *dto.DebugInfo
+}
+
+// TrackDebug returns a context recording the compiled SQL and the filters of the queries applied with it, read with
+// DebugFromContext to return them in the metadata of the response. It is meant for privileged callers, e.g. support
+// staff asking for it with a debug parameter: the SQL reveals the schema of the database, although the values of
+// sensitive fields are redacted
+func TrackDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, &debugRecord{})
+}
+
+// DebugFromContext returns the debug information of the last query applied with the context, or nil if the context
+// doesn't track it or no query was applied. ExecuteCursorPage adds it to the metadata it returns
+func DebugFromContext(ctx context.Context) *dto.DebugInfo {
+	record, ok := ctx.Value(debugKey{}).(*debugRecord)
+	if !ok {
+		return nil
+	}
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	if record.info == nil {
+		return nil
+	}
+	info := *record.info
+	return &info
+}
+
+// tracksDebug reports whether the context records the debug information of its queries
+func tracksDebug(ctx context.Context) bool {
+	_, ok := ctx.Value(debugKey{}).(*debugRecord)
+	return ok
+}
+
+// recordDebug records the SQL of a query applied with the context, and the SQL of its count query if any, if the
+// context tracks them; the SQL is rendered with redactedSQL
+func (q *BunQL) recordDebug(ctx context.Context, sql, countSQL string) {
+	record, ok := ctx.Value(debugKey{}).(*debugRecord)
+	if !ok {
+		return
+	}
+	info := &dto.DebugInfo{
+		SQL:     sql,
+		Filters: q.RedactedFilters(),
+		Sort:    sorting.ExpandAliases(q.Sort, q.SortAliases),
+	}
+	if countSQL != "" {
+		info.CountSQL = countSQL
+	}
+
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	record.info = info
+}
@@ -0,0 +1,76 @@
+package bunql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/uptrace/bun"
+	"golang.org/x/sync/singleflight"
+)
+
+// Deduplicator shares the execution of identical pages requested at the same time, so that a burst of identical
+// dashboard requests results in one database execution with the result fanned out to every caller
+// A Deduplicator is safe for concurrent use and is meant to be shared by the BunQLs of an endpoint, or of a process
+type Deduplicator struct {
+	group singleflight.Group
+}
+
+// NewDeduplicator creates a Deduplicator
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{}
+}
+
+// WithDeduplicator makes ExecutePage share the execution of identical pages through the Deduplicator
+// Pages are identical when their main and count queries, after the filters, middleware and pagination are applied,
+// render to the same SQL and are scanned into the same type. The callers sharing an execution get copies of the
+// result slice, whose elements are shallow copies. Pages read on a transaction or a connection are not shared
+func (q *BunQL) WithDeduplicator(d *Deduplicator) *BunQL {
+	q.Deduplicator = d
+	return q
+}
+
+// page is the result of a page execution shared by a Deduplicator
+type page[T any] struct {
+	results []T
+	count   int
+}
+
+// deduplicate runs the execution of a page unless an identical page is already being executed, in which case it
+// waits for its result. The shared execution is not canceled when the caller that started it gives up, so that the
+// other callers still get the result; each caller stops waiting when its own context is done
+// Pages read on a transaction or a connection are never shared, as they may see rows that other callers don't
+func deduplicate[T any](ctx context.Context, ql *BunQL, mainQuery, countQuery *bun.SelectQuery, execute func(ctx context.Context) ([]T, int, error)) ([]T, int, error) {
+	if isSingleConn(mainQuery) || isSingleConn(countQuery) {
+		return execute(ctx)
+	}
+	key := pageKey[T](mainQuery, countQuery)
+
+	ch := ql.Deduplicator.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := ql.deadline(context.WithoutCancel(ctx))
+		defer cancel()
+
+		results, count, err := execute(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return page[T]{results: results, count: count}, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, 0, res.Err
+		}
+		shared := res.Val.(page[T])
+		return append([]T(nil), shared.results...), shared.count, nil
+	}
+}
+
+// pageKey returns the key identifying the execution of a page: the hash of the result type and of the SQL of its queries
+func pageKey[T any](mainQuery, countQuery *bun.SelectQuery) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%T\x00%s\x00%s", (*T)(nil), querySQL(mainQuery), querySQL(countQuery))))
+	return hex.EncodeToString(sum[:])
+}
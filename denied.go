@@ -0,0 +1,83 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/search"
+)
+
+// DeniedField describes a filter or sort field rejected because it is denied or not in the allowed fields, or not in
+// the fields of the scope of its filter group
+type DeniedField struct {
+	Field    string      // Field that is not allowed
+	Usage    string      // "filter" or "sort"
+	Caller   string      // Caller identity, as set with WithIdentity; empty if unknown
+	Operator string      // Operator of the filter; empty for sorts
+	Value    interface{} // Value of the filter, Redacted for sensitive fields; nil for sorts
+	Scope    string      // Scope of the filter group that doesn't allow the field; empty if the field isn't allowed at all
+}
+
+// WithDeniedFieldHook sets the function notified of every field rejected by the allowed or denied fields while parsing,
+// e.g. to count which fields users keep trying to filter or sort by
+func (q *BunQL) WithDeniedFieldHook(hook func(ctx context.Context, denied DeniedField)) *BunQL {
+	q.DeniedFieldHook = hook
+	return q
+}
+
+// reportDeniedFilters notifies the denied field hook of every filter on a field that is not allowed, or not allowed
+// by the scope of its group
+func (q *BunQL) reportDeniedFilters(ctx context.Context, group dto.FilterGroup) {
+	if q.DeniedFieldHook == nil {
+		return
+	}
+
+	redacted := redactFilterGroup(group, q.Fields)
+	report := func(filter dto.Filter, scope string) {
+		q.DeniedFieldHook(ctx, DeniedField{
+			Field:    filter.Field,
+			Usage:    "filter",
+			Caller:   IdentityFromContext(ctx),
+			Operator: filter.Operator,
+			Value:    filter.Value,
+			Scope:    scope,
+		})
+	}
+
+	var walk func(group dto.FilterGroup)
+	walk = func(group dto.FilterGroup) {
+		for _, filter := range group.Filters {
+			if !q.allowsFilterField(filter.Field) {
+				report(filter, "")
+			}
+		}
+		for _, nestedGroup := range group.Groups {
+			walk(nestedGroup)
+		}
+	}
+	walk(redacted)
+
+	// Fields that aren't allowed at all are only reported once, and unknown scopes are rejected without reporting
+	denied, _ := q.scopeDenials(redacted, "", nil)
+	for _, d := range denied {
+		if q.allowsFilterField(d.filter.Field) {
+			report(d.filter, d.scope)
+		}
+	}
+}
+
+// reportDeniedSort notifies the denied field hook of every sort on a field that is not allowed
+func (q *BunQL) reportDeniedSort(ctx context.Context, sortFields []dto.SortField) {
+	if q.DeniedFieldHook == nil {
+		return
+	}
+
+	for _, sort := range sortFields {
+		if sort.Field != search.RelevanceField && !q.allowsSortField(sort.Field) {
+			q.DeniedFieldHook(ctx, DeniedField{
+				Field:  sort.Field,
+				Usage:  "sort",
+				Caller: IdentityFromContext(ctx),
+			})
+		}
+	}
+}
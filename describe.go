@@ -0,0 +1,183 @@
+package bunql
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Phrases are the words Describe builds descriptions with, which can be translated
+type Phrases struct {
+	And       string            // Joins the members of an AND group, e.g. "AND"
+	Or        string            // Joins the members of an OR group, e.g. "OR"
+	Operators map[string]string // Templates of the conditions per operator, with {field}, {value}, {from} and {to} placeholders
+	SortedBy  string            // Introduces the sort, e.g. "sorted by {sort}"
+	Asc       string            // Ascending direction
+	Desc      string            // Descending direction
+	Page      string            // Describes the page, with {page} and {size} placeholders
+}
+
+// EnglishPhrases are the phrases used by Describe
+var EnglishPhrases = Phrases{
+	And: "AND",
+	Or:  "OR",
+	Operators: map[string]string{
+		"eq":              "{field} = {value}",
+		"neq":             "{field} != {value}",
+		"gt":              "{field} > {value}",
+		"gte":             "{field} >= {value}",
+		"lt":              "{field} < {value}",
+		"lte":             "{field} <= {value}",
+		"like":            "{field} contains {value}",
+		"ilike":           "{field} contains {value}, ignoring case",
+		"regex":           "{field} matches {value}",
+		"in":              "{field} in {value}",
+		"notin":           "{field} not in {value}",
+		"isnull":          "{field} is empty",
+		"isnotnull":       "{field} is not empty",
+		"istrue":          "{field} is true",
+		"isfalse":         "{field} is false",
+		"between":         "{field} between {from} and {to}",
+		"overlaps":        "{field} overlaps {from} to {to}",
+		"similar":         "{field} is similar to {value}",
+		"nearby":          "{field} near {value}",
+		"withinbbox":      "{field} within {value}",
+		"distinctfrom":    "{field} is distinct from {value}",
+		"notdistinctfrom": "{field} is not distinct from {value}",
+		"anyeq":           "{field} = any of {value}",
+		"anyneq":          "{field} != any of {value}",
+		"anygt":           "{field} > any of {value}",
+		"anygte":          "{field} >= any of {value}",
+		"anylt":           "{field} < any of {value}",
+		"anylte":          "{field} <= any of {value}",
+		"alleq":           "{field} = all of {value}",
+		"allneq":          "{field} != all of {value}",
+		"allgt":           "{field} > all of {value}",
+		"allgte":          "{field} >= all of {value}",
+		"alllt":           "{field} < all of {value}",
+		"alllte":          "{field} <= all of {value}",
+		"descendantof":    "{field} descendant of {value}",
+		"ancestorof":      "{field} ancestor of {value}",
+	},
+	SortedBy: "sorted by {sort}",
+	Asc:      "asc",
+	Desc:     "desc",
+	Page:     "page {page} of size {size}",
+}
+
+// Describe returns a human-readable description of filters, a sort and a page, such as
+// "age > 30 AND (name contains 'J'), sorted by last_name asc, page 2 of size 10", for audit trails and UIs
+// listing the current filters. Parts that are not set are left out
+func Describe(group dto.FilterGroup, sort []dto.SortField, pagination *dto.Pagination) string {
+	return DescribeWithPhrases(group, sort, pagination, EnglishPhrases)
+}
+
+// DescribeWithPhrases returns a description of filters, a sort and a page built with the given phrases
+func DescribeWithPhrases(group dto.FilterGroup, sort []dto.SortField, pagination *dto.Pagination, phrases Phrases) string {
+	var parts []string
+
+	if filters := describeGroup(group, phrases); filters != "" {
+		parts = append(parts, filters)
+	}
+
+	if len(sort) > 0 {
+		fields := make([]string, len(sort))
+		for i, s := range sort {
+			direction := phrases.Asc
+			if s.Direction.IsDesc() {
+				direction = phrases.Desc
+			}
+			fields[i] = s.Field + " " + direction
+		}
+		parts = append(parts, strings.ReplaceAll(phrases.SortedBy, "{sort}", strings.Join(fields, ", ")))
+	}
+
+	if pagination != nil && pagination.PageSize > 0 {
+		page := max(pagination.Page, 1)
+		parts = append(parts, strings.NewReplacer(
+			"{page}", fmt.Sprint(page),
+			"{size}", fmt.Sprint(pagination.PageSize),
+		).Replace(phrases.Page))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Describe returns a human-readable description of the filters, sort and page of the query, see Describe
+// The values of the sensitive fields are redacted
+func (q *BunQL) Describe() string {
+	return Describe(q.RedactedFilters(), q.Sort, q.Pagination)
+}
+
+// describeGroup describes the members of a filter group joined by its logic, nested groups being parenthesized
+func describeGroup(group dto.FilterGroup, phrases Phrases) string {
+	separator := " " + phrases.And + " "
+	if group.IsOr() {
+		separator = " " + phrases.Or + " "
+	}
+
+	var members []string
+	for _, filter := range group.Filters {
+		members = append(members, describeFilter(filter, phrases))
+	}
+	for _, nestedGroup := range group.Groups {
+		if nested := describeGroup(nestedGroup, phrases); nested != "" {
+			members = append(members, "("+nested+")")
+		}
+	}
+	return strings.Join(members, separator)
+}
+
+// describeFilter describes a single condition with the template of its operator
+func describeFilter(filter dto.Filter, phrases Phrases) string {
+	op := strings.ToLower(filter.Operator)
+	template, ok := phrases.Operators[op]
+	if !ok {
+		template = "{field} " + op + " {value}"
+	}
+
+	from, to := "", ""
+	if bounds := flattenValue(filter.Value); len(bounds) == 2 {
+		from, to = describeValue(bounds[0]), describeValue(bounds[1])
+	}
+
+	return strings.NewReplacer(
+		"{field}", filter.Field,
+		"{value}", describeValue(filter.Value),
+		"{from}", from,
+		"{to}", to,
+	).Replace(template)
+}
+
+// describeValue formats a filter value: strings are quoted, lists parenthesized and objects written as JSON
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == Redacted {
+			return v
+		}
+		return "'" + v + "'"
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]string, rv.Len())
+		for i := range values {
+			values[i] = describeValue(rv.Index(i).Interface())
+		}
+		return "(" + strings.Join(values, ", ") + ")"
+	case reflect.Map, reflect.Struct, reflect.Pointer:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,118 @@
+package dto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Canonical returns a deterministic JSON representation of the filter group, which is the same for equivalent groups:
+// keys are sorted, logic and operators are lowercase, the logic defaults to "and", values are coerced to their JSON
+// form (so 30 and 30.0 are equal, and times are in UTC), and the members of each group are sorted. Scopes, which only
+// restrict the fields a group may use, are left out.
+// It is meant for cache keys, dedupe keys and audit identifiers rather than for parsing
+func (g FilterGroup) Canonical() string {
+	data, _ := json.Marshal(canonicalGroup(g))
+	return string(data)
+}
+
+// Hash returns the hex-encoded SHA-256 hash of the canonical representation of the filter group
+func (g FilterGroup) Hash() string {
+	sum := sha256.Sum256([]byte(g.Canonical()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal reports whether two filter groups are the same, member by member and in the same order: logic and operators
+// are compared case-insensitively, an empty logic is "and", values are compared in their JSON form (so 30 and 30.0
+// are equal) and empty and nil lists are equal. Hash tells whether groups are equivalent regardless of the order
+// of their members
+func (g FilterGroup) Equal(other FilterGroup) bool {
+	a, _ := json.Marshal(normalizedGroup(g, false))
+	b, _ := json.Marshal(normalizedGroup(other, false))
+	return bytes.Equal(a, b)
+}
+
+// canonicalGroup returns the canonical form of a filter group, made of maps so that the keys are sorted when marshaled
+func canonicalGroup(g FilterGroup) map[string]interface{} {
+	return normalizedGroup(g, true)
+}
+
+// normalizedGroup returns the normalized form of a filter group, with its members sorted or in their order
+func normalizedGroup(g FilterGroup, sorted bool) map[string]interface{} {
+	logic, _ := ParseLogic(g.Logic)
+
+	filters := make([]interface{}, len(g.Filters))
+	for i, filter := range g.Filters {
+		canonical := map[string]interface{}{
+			"field":    filter.Field,
+			"operator": strings.ToLower(filter.Operator),
+			"value":    canonicalValue(filter.Value),
+		}
+		if filter.CaseSensitive != nil {
+			canonical["caseSensitive"] = *filter.CaseSensitive
+		}
+		filters[i] = canonical
+	}
+	groups := make([]interface{}, len(g.Groups))
+	for i, group := range g.Groups {
+		groups[i] = normalizedGroup(group, sorted)
+	}
+
+	// The members of a group can be reordered without changing its meaning
+	if sorted {
+		sortByJSON(filters)
+		sortByJSON(groups)
+	}
+
+	return map[string]interface{}{"logic": logic, "filters": filters, "groups": groups}
+}
+
+// canonicalValue coerces a filter value to its generic JSON form: numbers become JSON numbers, structs and maps
+// become maps, and times are converted to UTC
+func canonicalValue(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC()
+	}
+	if values, ok := value.([]interface{}); ok {
+		coerced := make([]interface{}, len(values))
+		for i, v := range values {
+			coerced[i] = canonicalValue(v)
+		}
+		return coerced
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	// Numbers keep their precision, so that large integers that differ hash differently
+	var coerced interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&coerced); err != nil {
+		return fmt.Sprint(value)
+	}
+	return coerced
+}
+
+// sortByJSON sorts values by their JSON representation
+func sortByJSON(values []interface{}) {
+	type keyed struct {
+		key   string
+		value interface{}
+	}
+	members := make([]keyed, len(values))
+	for i, v := range values {
+		data, _ := json.Marshal(v)
+		members[i] = keyed{key: string(data), value: v}
+	}
+	sort.SliceStable(members, func(a, b int) bool { return members[a].key < members[b].key })
+	for i, member := range members {
+		values[i] = member.value
+	}
+}
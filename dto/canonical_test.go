@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFilterGroupCanonical(t *testing.T) {
+	group := FilterGroup{
+		Logic: "AND",
+		Filters: []Filter{
+			{Field: "name", Operator: "LIKE", Value: "J"},
+			{Field: "age", Operator: "gt", Value: 30},
+		},
+		Groups: []FilterGroup{{
+			Logic:   "Or",
+			Filters: []Filter{{Field: "created_at", Operator: "gte", Value: time.Date(2024, 1, 2, 3, 0, 0, 0, time.FixedZone("", 3600))}},
+		}},
+	}
+
+	assert.Equal(t, `{"filters":[{"field":"age","operator":"gt","value":30},{"field":"name","operator":"like","value":"J"}],`+
+		`"groups":[{"filters":[{"field":"created_at","operator":"gte","value":"2024-01-02T02:00:00Z"}],"groups":[],"logic":"or"}],`+
+		`"logic":"and"}`, group.Canonical())
+
+	// Equivalent groups have the same hash
+	equivalent := FilterGroup{
+		Filters: []Filter{
+			{Field: "age", Operator: "GT", Value: 30.0},
+			{Field: "name", Operator: "like", Value: "J"},
+		},
+		Groups: []FilterGroup{{
+			Logic:   "or",
+			Filters: []Filter{{Field: "created_at", Operator: "gte", Value: time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)}},
+		}},
+	}
+	assert.Equal(t, group.Hash(), equivalent.Hash())
+	assert.Len(t, group.Hash(), 64)
+
+	different := equivalent
+	different.Logic = "or"
+	assert.NotEqual(t, group.Hash(), different.Hash())
+
+	// The case sensitivity of a filter changes its meaning
+	sensitive := true
+	caseSensitive := FilterGroup{Filters: []Filter{{Field: "name", Operator: "like", Value: "J", CaseSensitive: &sensitive}}}
+	assert.Equal(t, `{"filters":[{"caseSensitive":true,"field":"name","operator":"like","value":"J"}],"groups":[],"logic":"and"}`, caseSensitive.Canonical())
+
+	// List values keep their order
+	between := FilterGroup{Filters: []Filter{{Field: "age", Operator: "between", Value: []int{30, 20}}}}
+	assert.Equal(t, `{"filters":[{"field":"age","operator":"between","value":[30,20]}],"groups":[],"logic":"and"}`, between.Canonical())
+}
+
+func TestFilterGroupEqual(t *testing.T) {
+	group := FilterGroup{
+		Logic:   "AND",
+		Filters: []Filter{{Field: "age", Operator: "GT", Value: 30}, {Field: "name", Operator: "like", Value: "J"}},
+		Groups:  []FilterGroup{},
+	}
+
+	same := FilterGroup{Filters: []Filter{{Field: "age", Operator: "gt", Value: 30.0}, {Field: "name", Operator: "like", Value: "J"}}}
+	assert.True(t, group.Equal(same))
+	assert.True(t, same.Equal(group))
+
+	// Unlike Hash, Equal takes the order of the members into account
+	reordered := FilterGroup{Logic: "and", Filters: []Filter{group.Filters[1], group.Filters[0]}}
+	assert.False(t, group.Equal(reordered))
+	assert.Equal(t, group.Hash(), reordered.Hash())
+
+	nested := same
+	nested.Groups = []FilterGroup{{Logic: "or"}}
+	assert.False(t, group.Equal(nested))
+	different := FilterGroup{Filters: []Filter{{Field: "age", Operator: "gt", Value: 31}, {Field: "name", Operator: "like", Value: "J"}}}
+	assert.False(t, group.Equal(different))
+
+	// Scopes don't change the rows a group matches
+	scoped := same
+	scoped.Scope = "search"
+	assert.True(t, group.Equal(scoped))
+}
+
+func TestFilterGroupMarshalJSON(t *testing.T) {
+	payload := `{"logic":"and","filters":[{"field":"age","operator":"gt","value":30}],"groups":[{"logic":"or","filters":[{"field":"name","operator":"eq","value":"J"}],"scope":"search"}]}`
+
+	var group FilterGroup
+	assert.NoError(t, json.Unmarshal([]byte(payload), &group))
+	data, err := json.Marshal(group)
+	assert.NoError(t, err)
+	assert.JSONEq(t, payload, string(data))
+
+	data, err = json.Marshal(FilterGroup{Logic: "and", Filters: []Filter{}, Groups: []FilterGroup{}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"logic":"and"}`, string(data))
+}
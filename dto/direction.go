@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Direction is the direction of a sort field
+type Direction string
+
+const (
+	Asc  Direction = "asc"  // Ascending, the default
+	Desc Direction = "desc" // Descending
+)
+
+// ParseDirection returns the direction named by s, case-insensitively
+// Unknown and empty directions are read as Asc, with ok false
+func ParseDirection(s string) (direction Direction, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case string(Asc):
+		return Asc, true
+	case string(Desc):
+		return Desc, true
+	}
+	return Asc, false
+}
+
+// Normalized returns the direction as Asc or Desc, reading unknown directions as Asc
+func (d Direction) Normalized() Direction {
+	direction, _ := ParseDirection(string(d))
+	return direction
+}
+
+// IsDesc reports whether the direction is descending
+func (d Direction) IsDesc() bool {
+	return d.Normalized() == Desc
+}
+
+// Inverted returns the opposite direction
+func (d Direction) Inverted() Direction {
+	if d.IsDesc() {
+		return Asc
+	}
+	return Desc
+}
+
+// SQL returns the direction as an ORDER BY keyword, ASC or DESC
+func (d Direction) SQL() string {
+	if d.IsDesc() {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// UnmarshalJSON reads the direction case-insensitively, so that legacy directions such as "DESC" are accepted
+func (d *Direction) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*d, _ = ParseDirection(s)
+	return nil
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseDirection(t *testing.T) {
+	direction, ok := ParseDirection(" DESC ")
+	assert.True(t, ok)
+	assert.Equal(t, Desc, direction)
+
+	direction, ok = ParseDirection("sideways")
+	assert.False(t, ok)
+	assert.Equal(t, Asc, direction)
+
+	assert.Equal(t, "DESC", Direction("Desc").SQL())
+	assert.Equal(t, "ASC", Direction("").SQL())
+	assert.Equal(t, Asc, Desc.Inverted())
+	assert.Equal(t, Desc, Direction("").Inverted())
+}
+
+func TestDirectionUnmarshalJSON(t *testing.T) {
+	var sortFields []SortField
+	require.NoError(t, json.Unmarshal([]byte(`[{"field":"a","dir":"DESC"},{"field":"b","dir":"up"},{"field":"c"}]`), &sortFields))
+
+	assert.Equal(t, Desc, sortFields[0].Direction)
+	assert.Equal(t, Asc, sortFields[1].Direction)
+	assert.Equal(t, Direction(""), sortFields[2].Direction)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"field":"a","dir":1}`), &sortFields[0]))
+}
@@ -1,34 +1,152 @@
 package dto
 
+import (
+	"encoding/json"
+)
+
+// Pagination represents a page of results, numbered from 1
+// It is read from JSON as {"page", "pageSize"} or as {"limit", "offset"} (see UnmarshalJSON)
 type Pagination struct {
 	Page     int `json:"page"`
 	PageSize int `json:"pageSize"`
 }
 
 // GetPaginationMetadataOutput represents the output of pagination metadata
+// Total and TotalItem are -1 when the total count is unknown, and then omitted from the JSON
 type GetPaginationMetadataOutput struct {
-	Total     int     `json:"total"`
-	Prev      *string `json:"prev"`
-	Next      *string `json:"next"`
-	TotalItem int     `json:"totalItem"`
+	Total     int        `json:"total"`
+	Prev      *string    `json:"prev"`
+	Next      *string    `json:"next"`
+	TotalItem int        `json:"totalItem"`
+	HasPrev   bool       `json:"hasPrev"`
+	HasNext   bool       `json:"hasNext"`
+	CountMode CountMode  `json:"countMode,omitempty"`
+	Debug     *DebugInfo `json:"debug,omitempty"`
+}
+
+// CountMode is how the total count of a page was obtained, telling clients whether to label it as approximate
+type CountMode string
+
+const (
+	CountExact     CountMode = "exact"     // Counted by the count query of the page
+	CountCached    CountMode = "cached"    // Counted earlier and served from a cache, possibly stale
+	CountEstimated CountMode = "estimated" // Estimated, e.g. from the statistics of the database
+	CountUnknown   CountMode = "unknown"   // Not counted, e.g. skipped by a query budget
+)
+
+// MarshalJSON marshals the metadata, omitting the totals when they are unknown
+func (m GetPaginationMetadataOutput) MarshalJSON() ([]byte, error) {
+	type metadata GetPaginationMetadataOutput
+	if m.Total >= 0 && m.TotalItem >= 0 {
+		return json.Marshal(metadata(m))
+	}
+	// The fields of the outer struct hide the totals of the embedded one
+	return json.Marshal(struct {
+		metadata
+		Total     *int `json:"total,omitempty"`
+		TotalItem *int `json:"totalItem,omitempty"`
+	}{metadata: metadata(m)})
+}
+
+// CursorPaginationMetadataOutput holds the cursor tokens of the pages around a keyset-paginated page
+type CursorPaginationMetadataOutput struct {
+	Prev  *string    `json:"prev"`
+	Next  *string    `json:"next"`
+	Debug *DebugInfo `json:"debug,omitempty"`
+}
+
+// DebugInfo is how a page was queried, returned to privileged callers to explain why they got its rows
+// Values of sensitive fields are redacted
+type DebugInfo struct {
+	SQL      string      `json:"sql"`                // Compiled SQL of the query of the page
+	CountSQL string      `json:"countSql,omitempty"` // Compiled SQL of the query counting the rows, if counted
+	Filters  FilterGroup `json:"filters"`            // Filters of the query
+	Sort     []SortField `json:"sort,omitempty"`     // Sort of the query, with the aliases expanded
 }
 
 // SortField represents a field to sorting by and the direction
 type SortField struct {
-	Field     string `json:"field"`
-	Direction string `json:"dir"` // "asc" or "desc"
+	Field     string      `json:"field"`
+	Direction Direction   `json:"dir"`               // Asc or Desc; Asc if empty
+	NullsAs   interface{} `json:"nullsAs,omitempty"` // Value NULLs are sorted as, e.g. "", 0 or "1970-01-01"
+}
+
+// FieldType is the type of the values a field can be compared with
+type FieldType string
+
+const (
+	FieldString  FieldType = "string"  // Strings
+	FieldNumber  FieldType = "number"  // Integers and floating point numbers
+	FieldBoolean FieldType = "boolean" // true or false
+	FieldTime    FieldType = "time"    // Dates, as "2006-01-02" or RFC 3339 strings
+	FieldDecimal FieldType = "decimal" // Exact decimal numbers, as numbers or strings such as "10.10", e.g. for money
+)
+
+// MatchMode is how a like value without wildcards is matched
+type MatchMode string
+
+const (
+	MatchContains MatchMode = "contains" // Anywhere in the field, LIKE '%J%'; the default
+	MatchPrefix   MatchMode = "prefix"   // At the start of the field, LIKE 'J%', which can use an index on the field
+	MatchSuffix   MatchMode = "suffix"   // At the end of the field, LIKE '%J'
+	MatchExact    MatchMode = "exact"    // The whole field, LIKE 'J'
+)
+
+// FieldConfig holds per-field settings applied when filtering and sorting on the field
+type FieldConfig struct {
+	Collation     string    `json:"collation,omitempty"`     // Collation applied to the field, e.g. "und-x-icu" or "Latin1_General_CI_AI"
+	Unaccent      bool      `json:"unaccent,omitempty"`      // Whether accents are ignored, so "José" matches "jose"
+	EmptyIsNull   bool      `json:"emptyIsNull,omitempty"`   // Whether empty strings and NULL are treated as the same value
+	Type          FieldType `json:"type,omitempty"`          // Type filter values must have; any value is accepted if empty
+	Operators     []string  `json:"operators,omitempty"`     // Operators allowed on the field; every operator is allowed if empty
+	Sensitive     bool      `json:"sensitive,omitempty"`     // Whether values are redacted in logs and audit entries, e.g. for emails
+	Precision     int       `json:"precision,omitempty"`     // Maximum number of significant digits of decimal values; unchecked if zero
+	Scale         int       `json:"scale,omitempty"`         // Maximum number of digits after the decimal point of decimal values; unchecked if zero
+	Parent        string    `json:"parent,omitempty"`        // Column referencing the parent row, for descendantof and ancestorof; parent_id if empty
+	End           string    `json:"end,omitempty"`           // Column holding the end of the range starting at the field, for overlaps; the field itself if empty
+	Match         MatchMode `json:"match,omitempty"`         // How like values without wildcards are matched; the mode of the request if empty
+	CaseSensitive *bool     `json:"caseSensitive,omitempty"` // Whether comparisons are case-sensitive or not; the database default if nil
+}
+
+// Search represents a free-text search over several fields
+type Search struct {
+	Term   string   `json:"term"`   // Text to search for
+	Fields []string `json:"fields"` // Fields searched for the term
+}
+
+// PinnedRows represents rows that are sorted before all the others, regardless of the requested sort
+type PinnedRows struct {
+	Column string        `json:"column"` // Column identifying the rows
+	IDs    []interface{} `json:"ids"`    // Values of the column for the pinned rows
 }
 
 // FilterGroup represents a group of filter with a logical operator
 type FilterGroup struct {
-	Logic   string        `json:"logic"`   // "and" or "or"
-	Filters []Filter      `json:"filters"` // List of filter
-	Groups  []FilterGroup `json:"groups"`  // Nested filter groups
+	Logic   string        `json:"logic"`             // "and" or "or", in any case (see ParseLogic)
+	Filters []Filter      `json:"filters,omitempty"` // List of filter; left out of the JSON form when empty
+	Groups  []FilterGroup `json:"groups,omitempty"`  // Nested filter groups; left out of the JSON form when empty
+	Scope   string        `json:"scope,omitempty"`   // Scope restricting the fields of the group and its nested groups; none if empty
 }
 
 // Filter represents a single filter condition
 type Filter struct {
-	Field    string      `json:"field"`    // Field name to filter on
-	Operator string      `json:"operator"` // Operator to use (eq, neq, gt, etc.)
-	Value    interface{} `json:"value"`    // Value to compare against
+	Field         string      `json:"field"`                   // Field name to filter on
+	Operator      string      `json:"operator"`                // Operator to use (eq, neq, gt, etc.)
+	Value         interface{} `json:"value"`                   // Value to compare against
+	CaseSensitive *bool       `json:"caseSensitive,omitempty"` // Overrides the case sensitivity of the field configuration
+}
+
+// GeoRadius is the value of the nearby operator: a circle around a point
+type GeoRadius struct {
+	Lat    float64 `json:"lat"`    // Latitude of the center in degrees
+	Lng    float64 `json:"lng"`    // Longitude of the center in degrees
+	Radius float64 `json:"radius"` // Radius in meters
+}
+
+// GeoBoundingBox is the value of the withinbbox operator: a box delimited by two corners
+type GeoBoundingBox struct {
+	MinLat float64 `json:"minLat"` // Latitude of the south-west corner in degrees
+	MinLng float64 `json:"minLng"` // Longitude of the south-west corner in degrees
+	MaxLat float64 `json:"maxLat"` // Latitude of the north-east corner in degrees
+	MaxLng float64 `json:"maxLng"` // Longitude of the north-east corner in degrees
 }
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"strings"
+)
+
+// Logics joining the members of a filter group
+const (
+	LogicAnd = "and" // Every member matches; the default
+	LogicOr  = "or"  // At least one member matches
+)
+
+// ParseLogic returns the logic of a filter group as LogicAnd or LogicOr, ignoring case and surrounding spaces
+// An empty logic is read as LogicAnd; unknown logics are read as LogicAnd too, with ok false
+func ParseLogic(s string) (logic string, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", LogicAnd:
+		return LogicAnd, true
+	case LogicOr:
+		return LogicOr, true
+	}
+	return LogicAnd, false
+}
+
+// IsOr reports whether the members of the group are joined with OR
+func (g FilterGroup) IsOr() bool {
+	logic, _ := ParseLogic(g.Logic)
+	return logic == LogicOr
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseLogic(t *testing.T) {
+	for input, expected := range map[string]string{"AND": LogicAnd, " Or ": LogicOr, "": LogicAnd} {
+		logic, ok := ParseLogic(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, logic, input)
+	}
+
+	logic, ok := ParseLogic("xor")
+	assert.False(t, ok)
+	assert.Equal(t, LogicAnd, logic)
+
+	assert.True(t, FilterGroup{Logic: " OR"}.IsOr())
+	assert.False(t, FilterGroup{}.IsOr())
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// paginationJSON is the JSON form of a pagination, with limit and offset as aliases of the page size and page
+type paginationJSON struct {
+	Page     int  `json:"page"`
+	PageSize int  `json:"pageSize"`
+	Limit    *int `json:"limit"`
+	Offset   *int `json:"offset"`
+}
+
+// Limit returns the number of rows of the page, 0 if the page size is not set
+func (p Pagination) Limit() int {
+	return max(p.PageSize, 0)
+}
+
+// Offset returns the number of rows before the page, reading pages below 1 as the first page
+func (p Pagination) Offset() int {
+	if p.PageSize <= 0 || p.Page <= 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
+
+// Normalize returns the pagination with a page below 1 replaced by the page of the defaults, a page size below 1 by
+// the page size of the defaults, and a page size above max capped to max, unless max is zero
+func (p Pagination) Normalize(defaults Pagination, max int) Pagination {
+	if p.Page < 1 {
+		p.Page = defaults.Page
+	}
+	if p.PageSize < 1 {
+		p.PageSize = defaults.PageSize
+	}
+	if max > 0 && p.PageSize > max {
+		p.PageSize = max
+	}
+	return p
+}
+
+// UnmarshalJSON reads the pagination from its page and pageSize, or from limit and offset aliases
+// The offset must be a multiple of the limit, so that it falls on the start of a page
+func (p *Pagination) UnmarshalJSON(data []byte) error {
+	var in paginationJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*p = Pagination{Page: in.Page, PageSize: in.PageSize}
+	if in.Limit != nil {
+		p.PageSize = *in.Limit
+	}
+	if in.Offset != nil {
+		if p.PageSize <= 0 {
+			return fmt.Errorf("offset %d requires a positive limit", *in.Offset)
+		}
+		if *in.Offset < 0 || *in.Offset%p.PageSize != 0 {
+			return fmt.Errorf("offset %d is not a multiple of the limit %d", *in.Offset, p.PageSize)
+		}
+		p.Page = *in.Offset/p.PageSize + 1
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPaginationOffsetLimit(t *testing.T) {
+	assert.Equal(t, 20, Pagination{Page: 3, PageSize: 10}.Offset())
+	assert.Equal(t, 10, Pagination{Page: 3, PageSize: 10}.Limit())
+	assert.Equal(t, 0, Pagination{Page: 0, PageSize: 10}.Offset())
+	assert.Equal(t, 0, Pagination{Page: 3}.Offset())
+	assert.Equal(t, 0, Pagination{PageSize: -1}.Limit())
+}
+
+func TestPaginationNormalize(t *testing.T) {
+	defaults := Pagination{Page: 1, PageSize: 25}
+	assert.Equal(t, Pagination{Page: 1, PageSize: 25}, Pagination{Page: -2}.Normalize(defaults, 100))
+	assert.Equal(t, Pagination{Page: 4, PageSize: 100}, Pagination{Page: 4, PageSize: 500}.Normalize(defaults, 100))
+	assert.Equal(t, Pagination{Page: 4, PageSize: 500}, Pagination{Page: 4, PageSize: 500}.Normalize(defaults, 0))
+}
+
+func TestPaginationUnmarshalJSON(t *testing.T) {
+	var p Pagination
+	require.NoError(t, json.Unmarshal([]byte(`{"page": 2, "pageSize": 10}`), &p))
+	assert.Equal(t, Pagination{Page: 2, PageSize: 10}, p)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"limit": 20, "offset": 40}`), &p))
+	assert.Equal(t, Pagination{Page: 3, PageSize: 20}, p)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"limit": 20}`), &p))
+	assert.Equal(t, Pagination{PageSize: 20}, p)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"limit": 20, "offset": 30}`), &p), "offset 30 is not a multiple of the limit 20")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"offset": 30}`), &p), "offset 30 requires a positive limit")
+
+	// Marshaling keeps the page and page size
+	data, err := json.Marshal(Pagination{Page: 3, PageSize: 20})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"page": 3, "pageSize": 20}`, string(data))
+}
@@ -0,0 +1,244 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueTime is the value type marking filter values made of times, so that they are read back as times
+const ValueTime = "time"
+
+// StringValue returns a string filter value
+func StringValue(s string) interface{} {
+	return s
+}
+
+// IntValue returns an integer filter value, in the form integers are read from JSON
+func IntValue(i int64) interface{} {
+	return i
+}
+
+// TimeValue returns a time filter value, marshaled with its value type so that it is read back as a time
+func TimeValue(t time.Time) interface{} {
+	return t
+}
+
+// ListValue returns a list filter value, e.g. for in and between
+func ListValue(values ...interface{}) interface{} {
+	return values
+}
+
+// StringValue returns the value of the filter if it is a string
+func (f Filter) StringValue() (string, bool) {
+	s, ok := f.Value.(string)
+	return s, ok
+}
+
+// IntValue returns the value of the filter if it is an integer, from any Go integer type, a float without a
+// fractional part or a JSON number
+func (f Filter) IntValue() (int64, bool) {
+	return intValue(f.Value)
+}
+
+// TimeValue returns the value of the filter if it is a time, or a string in the RFC 3339 format
+func (f Filter) TimeValue() (time.Time, bool) {
+	return timeValue(f.Value)
+}
+
+// ListValue returns the elements of the value of the filter if it is a slice or an array
+func (f Filter) ListValue() ([]interface{}, bool) {
+	v := reflect.ValueOf(f.Value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	if _, ok := f.Value.([]byte); ok {
+		return nil, false
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+// filterJSON is the JSON form of a filter, whose value type tells how the value is read back
+type filterJSON struct {
+	Field         string          `json:"field"`
+	Operator      string          `json:"operator"`
+	Value         json.RawMessage `json:"value"`
+	ValueType     string          `json:"valueType,omitempty"`
+	CaseSensitive *bool           `json:"caseSensitive,omitempty"`
+}
+
+// MarshalJSON marshals the filter, adding the value type of values made of times
+func (f Filter) MarshalJSON() ([]byte, error) {
+	value, err := json.Marshal(f.Value)
+	if err != nil {
+		return nil, err
+	}
+	out := filterJSON{Field: f.Field, Operator: f.Operator, Value: value, CaseSensitive: f.CaseSensitive}
+	if isTimeValue(f.Value) {
+		out.ValueType = ValueTime
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON unmarshals the filter without losing the precision of numbers: integers are read as int64, other
+// numbers as float64 when it represents them exactly and as json.Number otherwise. Values marked with the time value
+// type are read as times
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	var in filterJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	var value interface{}
+	if len(in.Value) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(in.Value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		value = readNumbers(value)
+	}
+
+	switch in.ValueType {
+	case "":
+	case ValueTime:
+		var err error
+		if value, err = readTimes(value); err != nil {
+			return fmt.Errorf("invalid value of filter field '%s': %w", in.Field, err)
+		}
+	default:
+		return fmt.Errorf("invalid value type '%s' of filter field '%s'", in.ValueType, in.Field)
+	}
+
+	*f = Filter{Field: in.Field, Operator: in.Operator, Value: value, CaseSensitive: in.CaseSensitive}
+	return nil
+}
+
+// readNumbers replaces the JSON numbers of a decoded value with int64 or float64 where they fit without loss
+func readNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return readNumber(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = readNumbers(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = readNumbers(v[key])
+		}
+	}
+	return value
+}
+
+// readNumber returns a JSON number as an int64 or a float64 if it fits without loss, or as is otherwise
+func readNumber(n json.Number) interface{} {
+	if !strings.ContainsAny(n.String(), ".eE") {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		return n
+	}
+
+	f, err := n.Float64()
+	if err != nil || math.IsInf(f, 0) {
+		return n
+	}
+	// The number fits when its shortest float64 form is the same decimal number
+	exact, _, err := big.ParseFloat(n.String(), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return n
+	}
+	shortest, _, _ := big.ParseFloat(strconv.FormatFloat(f, 'g', -1, 64), 10, 256, big.ToNearestEven)
+	if exact.Cmp(shortest) != 0 {
+		return n
+	}
+	return f
+}
+
+// readTimes parses the RFC 3339 strings of a value marked with the time value type
+func readTimes(value interface{}) (interface{}, error) {
+	if values, ok := value.([]interface{}); ok {
+		for i := range values {
+			var err error
+			if values[i], err = readTimes(values[i]); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+
+	// The errors leave the value out, as it may be the value of a sensitive field
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("time value is not a string")
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, errors.New("time value is not an RFC 3339 string")
+	}
+	return t, nil
+}
+
+// isTimeValue reports whether a value is a time, or a non-empty list of times
+func isTimeValue(value interface{}) bool {
+	if _, ok := value.(time.Time); ok {
+		return true
+	}
+	values, ok := Filter{Value: value}.ListValue()
+	if !ok || len(values) == 0 {
+		return false
+	}
+	for _, v := range values {
+		if _, ok := v.(time.Time); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// intValue returns a value as an int64 if it is an integer
+func intValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt():
+		return rv.Int(), true
+	case rv.CanUint() && rv.Uint() <= math.MaxInt64:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+// timeValue returns a value as a time if it is a time or an RFC 3339 string
+func timeValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestFilterUnmarshalNumbers(t *testing.T) {
+	var filters []Filter
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"field": "id", "operator": "eq", "value": 9007199254740993},
+		{"field": "price", "operator": "gt", "value": 10.5},
+		{"field": "amount", "operator": "eq", "value": 0.10000000000000000001},
+		{"field": "big", "operator": "eq", "value": 123456789012345678901234567890},
+		{"field": "age", "operator": "between", "value": [20, 30.5]},
+		{"field": "name", "operator": "isnull", "value": null}
+	]`), &filters))
+
+	assert.Equal(t, int64(9007199254740993), filters[0].Value)
+	assert.Equal(t, 10.5, filters[1].Value)
+	assert.Equal(t, json.Number("0.10000000000000000001"), filters[2].Value)
+	assert.Equal(t, json.Number("123456789012345678901234567890"), filters[3].Value)
+	assert.Equal(t, []interface{}{int64(20), 30.5}, filters[4].Value)
+	assert.Nil(t, filters[5].Value)
+}
+
+func TestFilterMarshalTimes(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	filters := []Filter{
+		{Field: "created_at", Operator: "gte", Value: TimeValue(at)},
+		{Field: "created_at", Operator: "between", Value: []time.Time{at, at.Add(time.Hour)}},
+		{Field: "name", Operator: "eq", Value: StringValue("2024-01-02T03:04:05Z")},
+	}
+
+	data, err := json.Marshal(filters)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"field": "created_at", "operator": "gte", "value": "2024-01-02T03:04:05.000000006Z", "valueType": "time"},
+		{"field": "created_at", "operator": "between", "value": ["2024-01-02T03:04:05.000000006Z", "2024-01-02T04:04:05.000000006Z"], "valueType": "time"},
+		{"field": "name", "operator": "eq", "value": "2024-01-02T03:04:05Z"}
+	]`, string(data))
+
+	var decoded []Filter
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, at, decoded[0].Value)
+	assert.Equal(t, []interface{}{at, at.Add(time.Hour)}, decoded[1].Value)
+	assert.Equal(t, "2024-01-02T03:04:05Z", decoded[2].Value)
+
+	var filter Filter
+	assert.Error(t, json.Unmarshal([]byte(`{"field": "created_at", "operator": "eq", "value": 1, "valueType": "time"}`), &filter))
+	assert.Error(t, json.Unmarshal([]byte(`{"field": "created_at", "operator": "eq", "value": 1, "valueType": "date"}`), &filter))
+
+	// The errors leave the value out
+	err = json.Unmarshal([]byte(`{"field": "created_at", "operator": "eq", "value": "secret-value", "valueType": "time"}`), &filter)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-value")
+}
+
+func TestFilterValueAccessors(t *testing.T) {
+	s, ok := Filter{Value: StringValue("J")}.StringValue()
+	assert.True(t, ok)
+	assert.Equal(t, "J", s)
+	_, ok = Filter{Value: 1}.StringValue()
+	assert.False(t, ok)
+
+	for _, value := range []interface{}{IntValue(42), 42, uint8(42), 42.0, json.Number("42")} {
+		i, ok := Filter{Value: value}.IntValue()
+		assert.True(t, ok, "%T", value)
+		assert.Equal(t, int64(42), i)
+	}
+	_, ok = Filter{Value: 42.5}.IntValue()
+	assert.False(t, ok)
+
+	at := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, value := range []interface{}{TimeValue(at), "2024-01-02T00:00:00Z"} {
+		tm, ok := Filter{Value: value}.TimeValue()
+		assert.True(t, ok)
+		assert.True(t, at.Equal(tm))
+	}
+	_, ok = Filter{Value: "2024-01-02"}.TimeValue()
+	assert.False(t, ok)
+
+	list, ok := Filter{Value: []int{1, 2}}.ListValue()
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{1, 2}, list)
+	list, ok = Filter{Value: ListValue(IntValue(1), StringValue("a"))}.ListValue()
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{int64(1), "a"}, list)
+	_, ok = Filter{Value: "a"}.ListValue()
+	assert.False(t, ok)
+}
@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"context"
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAddFilter(t *testing.T) {
+	db := GetDB()
+	ctx := context.Background()
+
+	// The client asks for either of two users
+	ql, err := bunql.ParseFromParams(`{"logic": "or", "filters": [
+		{"field": "first_name", "operator": "eq", "value": "User1"},
+		{"field": "first_name", "operator": "eq", "value": "User2"}
+	]}`, "", 0, 0)
+	require.NoError(t, err)
+	parsed := ql.Filters
+
+	// The server restricts them to the active ones, and to an age range
+	require.NoError(t, ql.AddFilter("active", "istrue", nil))
+	require.NoError(t, ql.AddGroup("OR", dto.Filter{Field: "age", Operator: "lt", Value: 30}, dto.Filter{Field: "age", Operator: "gte", Value: 60}))
+
+	assert.Equal(t, "and", ql.Filters.Logic)
+	assert.Equal(t, []dto.FilterGroup{parsed, {
+		Logic:   "or",
+		Filters: []dto.Filter{{Field: "age", Operator: "lt", Value: 30}, {Field: "age", Operator: "gte", Value: 60}},
+		Groups:  []dto.FilterGroup{},
+	}}, ql.Filters.Groups)
+	assert.Equal(t, []dto.Filter{{Field: "active", Operator: "istrue"}}, ql.Filters.Filters)
+
+	// The parsed group is left unchanged
+	assert.Equal(t, "or", parsed.Logic)
+	assert.Len(t, parsed.Filters, 2)
+
+	var users []User
+	require.NoError(t, ql.Apply(ctx, db.NewSelect().Model(&users)).Scan(ctx))
+	for _, user := range users {
+		assert.Contains(t, []string{"User1", "User2"}, user.FirstName)
+		assert.True(t, user.Active)
+		assert.True(t, user.Age < 30 || user.Age >= 60)
+	}
+
+	// Invalid filters are rejected without changing the filters
+	assert.EqualError(t, ql.AddFilter("age", "between", 3), "operator 'between' on field 'age' requires a pair of values")
+	assert.EqualError(t, ql.AddFilter("age", "almost", 3), "invalid operator: almost")
+	assert.EqualError(t, ql.AddGroup("xor", dto.Filter{Field: "age", Operator: "eq", Value: 1}),
+		"invalid filter group logic 'xor': must be 'and' or 'or'")
+	assert.Len(t, ql.Filters.Filters, 1)
+	assert.Len(t, ql.Filters.Groups, 2)
+
+	// Field configurations apply to the added filters
+	ql.WithFieldConfig("age", dto.FieldConfig{Type: dto.FieldNumber})
+	assert.Error(t, ql.AddFilter("age", "eq", "thirty"))
+}
+
+func TestAddFilterIf(t *testing.T) {
+	var status string
+	var minAge int
+	var ids []int64
+	active := false
+
+	ql := bunql.New()
+	require.NoError(t, ql.AddFilterIf("status", "eq", status))
+	require.NoError(t, ql.AddFilterIf("age", "gte", minAge))
+	require.NoError(t, ql.AddFilterIf("id", "in", ids))
+	require.NoError(t, ql.AddFilterIf("deleted_at", "lt", time.Time{}))
+	require.NoError(t, ql.AddFilterIf("email", "eq", (*string)(nil)))
+	assert.Empty(t, ql.Filters.Filters)
+
+	// Provided values are added, and pointers dereferenced
+	minAge, ids = 30, []int64{1, 2}
+	require.NoError(t, ql.AddFilterIf("age", "gte", minAge))
+	require.NoError(t, ql.AddFilterIf("id", "in", ids))
+	require.NoError(t, ql.AddFilterIf("active", "eq", &active))
+	assert.Equal(t, []dto.Filter{
+		{Field: "age", Operator: "gte", Value: 30},
+		{Field: "id", Operator: "in", Value: []int64{1, 2}},
+		{Field: "active", Operator: "eq", Value: false},
+	}, ql.Filters.Filters)
+
+	// Invalid provided values are still rejected
+	assert.Error(t, ql.AddFilterIf("age", "between", 3))
+}
+
+func TestApplyWhen(t *testing.T) {
+	ql := bunql.New()
+	sortByAge := func(q *bunql.BunQL) error { return q.OrderBy("age", dto.Desc) }
+
+	require.NoError(t, ql.ApplyWhen(false, sortByAge))
+	assert.Empty(t, ql.Sort)
+
+	require.NoError(t, ql.ApplyWhen(true, sortByAge))
+	assert.Equal(t, []dto.SortField{{Field: "age", Direction: dto.Desc}}, ql.Sort)
+
+	assert.EqualError(t, ql.ApplyWhen(true, func(q *bunql.BunQL) error { return q.AddFilter("", "eq", 1) }),
+		"filter key cannot be empty")
+}
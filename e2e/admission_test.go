@@ -0,0 +1,55 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdmissionHook tests that the admission hook can reject expensive queries per caller
+func TestAdmissionHook(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := bunql.WithIdentity(context.Background(), "api-key-1")
+	seedItems(t, ctx, 4)
+
+	filterJSON := `{
+		"filters": [{"field": "name", "operator": "like", "value": "Item"}],
+		"groups": [{"logic": "or", "filters": [
+			{"field": "category", "operator": "eq", "value": "a"},
+			{"field": "price", "operator": "gt", "value": 20}
+		]}]
+	}`
+	sortJSON := `[{"field": "price", "dir": "asc"}]`
+
+	ql, err := bunql.ParseFromParams(filterJSON, sortJSON, 1, 10)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	// like (3) + nested group (1) + two filters (2) + one sort field (1)
+	require.Equal(t, 7, ql.Complexity())
+
+	errTooExpensive := errors.New("query too expensive")
+	var callers []string
+	budget := 7
+	ql.WithAdmissionHook(func(ctx context.Context, caller string, complexity int) error {
+		callers = append(callers, caller)
+		if complexity > budget {
+			return errTooExpensive
+		}
+		budget -= complexity
+		return nil
+	})
+
+	items, _, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err, "Query failed")
+	require.Len(t, items, 3)
+
+	// The budget of the caller is exhausted
+	_, _, err = bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.ErrorIs(t, err, errTooExpensive)
+	require.Equal(t, []string{"api-key-1", "api-key-1"}, callers)
+}
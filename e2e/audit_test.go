@@ -0,0 +1,48 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuditHook tests that executed queries are passed to the audit hook along with the caller identity
+func TestAuditHook(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := bunql.WithIdentity(context.Background(), "user-42")
+	seedItems(t, ctx, 6)
+
+	filterJSON := `{"logic": "AND", "filters": [{"field": "category", "operator": "EQ", "value": "a"}]}`
+	sortJSON := `[{"field": "price", "dir": "DESC"}]`
+
+	ql, err := bunql.ParseFromParams(filterJSON, sortJSON, 1, 2)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	var entries []bunql.AuditEntry
+	ql.WithAuditHook(func(ctx context.Context, entry bunql.AuditEntry) {
+		entries = append(entries, entry)
+	})
+
+	items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err, "Query failed")
+	require.Len(t, items, 2)
+	require.Equal(t, 3, total)
+
+	require.Equal(t, []bunql.AuditEntry{{
+		Identity: "user-42",
+		Filters: dto.FilterGroup{
+			Logic:   "and",
+			Filters: []dto.Filter{{Field: "category", Operator: "eq", Value: "a"}},
+			Groups:  []dto.FilterGroup{},
+		},
+		Sort:       []dto.SortField{{Field: "price", Direction: "desc"}},
+		Pagination: &dto.Pagination{Page: 1, PageSize: 2},
+		Rows:       2,
+		Total:      3,
+	}}, entries)
+}
@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCountByTime tests that the filtered rows are counted per time bucket
+func TestCountByTime(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `DROP TABLE IF EXISTS events`)
+	require.NoError(t, err, "Failed to drop table")
+	_, err = db.NewCreateTable().Model((*Event)(nil)).Exec(ctx)
+	require.NoError(t, err, "Failed to create table")
+
+	day := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	events := []Event{
+		{Name: "signup", HappenedAt: day(1, 1, 9)},
+		{Name: "signup", HappenedAt: day(1, 1, 18)},
+		{Name: "signup", HappenedAt: day(1, 3, 12)},
+		{Name: "signup", HappenedAt: day(1, 8, 12)},
+		{Name: "signup", HappenedAt: day(2, 14, 12)},
+		{Name: "login", HappenedAt: day(1, 2, 12)},
+	}
+	_, err = db.NewInsert().Model(&events).Exec(ctx)
+	require.NoError(t, err, "Failed to insert events")
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "name", "operator": "eq", "value": "signup"}]}`, "", 1, 2)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	counts := func(buckets []bunql.Bucket) map[string]int {
+		m := map[string]int{}
+		for _, b := range buckets {
+			m[b.Start.Format(time.DateOnly)] = b.Count
+		}
+		return m
+	}
+
+	// Days with rows, ignoring the pagination
+	series, err := ql.CountByTime(ctx, db.NewSelect().Model((*Event)(nil)), bunql.TimeBucket{Field: "happened_at", Interval: bunql.BucketDay})
+	require.NoError(t, err, "CountByTime failed")
+	require.Equal(t, map[string]int{"2024-01-01": 2, "2024-01-03": 1, "2024-01-08": 1, "2024-02-14": 1}, counts(series.Buckets))
+	require.Equal(t, day(1, 1, 0), series.From)
+	require.Equal(t, day(2, 14, 0), series.To)
+	require.Equal(t, "UTC", series.Timezone)
+
+	// Zero-filled days
+	filled, err := series.Fill(time.Time{}, day(1, 9, 0))
+	require.NoError(t, err)
+	require.Len(t, filled, 9)
+	require.Equal(t, []int{2, 0, 1, 0, 0, 0, 0, 1, 0}, bucketCounts(filled))
+
+	// Weeks start on Monday: the 1st and 8th of January 2024 are Mondays
+	series, err = ql.CountByTime(ctx, db.NewSelect().Model((*Event)(nil)), bunql.TimeBucket{Field: "happened_at", Interval: bunql.BucketWeek})
+	require.NoError(t, err, "CountByTime failed")
+	require.Equal(t, map[string]int{"2024-01-01": 3, "2024-01-08": 1, "2024-02-12": 1}, counts(series.Buckets))
+	filled, err = series.Fill(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, filled, 7)
+
+	// Ranges too wide to fill are rejected
+	_, err = series.Fill(time.Time{}, day(1, 1, 0).AddDate(200, 0, 0))
+	require.ErrorIs(t, err, bunql.ErrTooManyBuckets)
+
+	series, err = ql.CountByTime(ctx, db.NewSelect().Model((*Event)(nil)), bunql.TimeBucket{Field: "happened_at", Interval: bunql.BucketMonth})
+	require.NoError(t, err, "CountByTime failed")
+	require.Equal(t, map[string]int{"2024-01-01": 4, "2024-02-01": 1}, counts(series.Buckets))
+
+	// SQLite has no time zones
+	_, err = ql.CountByTime(ctx, db.NewSelect().Model((*Event)(nil)), bunql.TimeBucket{Field: "happened_at", Interval: bunql.BucketDay, Timezone: "Europe/Paris"})
+	require.EqualError(t, err, "time zone 'Europe/Paris' is not supported on sqlite")
+
+	_, err = ql.CountByTime(ctx, db.NewSelect().Model((*Event)(nil)), bunql.TimeBucket{Field: "happened_at", Interval: "fortnight"})
+	require.EqualError(t, err, "invalid bucket interval 'fortnight'")
+}
+
+// bucketCounts returns the counts of buckets, in order
+func bucketCounts(buckets []bunql.Bucket) []int {
+	counts := make([]int, len(buckets))
+	for i, b := range buckets {
+		counts[i] = b.Count
+	}
+	return counts
+}
@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// TestQueryBudget tests that the statements of a request are bounded by its query budget
+func TestQueryBudget(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 4)
+
+	t.Run("Budget affording the whole page", func(t *testing.T) {
+		budget := bunql.NewQueryBudget(4, 0)
+		ctx := bunql.WithQueryBudget(ctx, budget)
+
+		items, total, err := bunql.ExecutePage[Item](ctx, bunql.New(), db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		require.Equal(t, 4, total)
+
+		// Outside Postgres, the median takes a count and a read of the middle values
+		median, err := bunql.New().Median(ctx, db.NewSelect().Model((*Item)(nil)), "price")
+		require.NoError(t, err)
+		require.Equal(t, 25.0, median)
+		require.Equal(t, 4, budget.Statements())
+		require.False(t, budget.Partial())
+
+		// The budget is spent
+		_, _, err = bunql.ExecutePage[Item](ctx, bunql.New(), db.NewSelect().Model((*Item)(nil)))
+		require.ErrorIs(t, err, bunql.ErrBudgetExceeded)
+		require.True(t, budget.Partial())
+	})
+
+	t.Run("Budget affording only the main query", func(t *testing.T) {
+		budget := bunql.NewQueryBudget(1, 0)
+		ctx := bunql.WithQueryBudget(ctx, budget)
+
+		ql, err := bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}`, "price:asc", 1, 10)
+		require.NoError(t, err)
+		items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, -1, total)
+		require.True(t, budget.Partial())
+		require.Equal(t, 1, budget.Statements())
+
+		_, err = bunql.New().CountByTime(ctx, db.NewSelect().Model((*Event)(nil)),
+			bunql.TimeBucket{Field: "happened_at", Interval: bunql.BucketDay})
+		require.ErrorIs(t, err, bunql.ErrBudgetExceeded)
+	})
+
+	t.Run("Database time budget", func(t *testing.T) {
+		hooked := bun.NewDB(db.DB, sqlitedialect.New())
+		hooked.AddQueryHook(&slowQueryHook{})
+
+		budget := bunql.NewQueryBudget(0, 20*time.Millisecond)
+		ctx := bunql.WithQueryBudget(ctx, budget)
+
+		_, _, err := bunql.ExecutePage[Item](ctx, bunql.New(), hooked.NewSelect().Model((*Item)(nil)))
+		require.ErrorIs(t, err, bunql.ErrBudgetExceeded)
+		require.GreaterOrEqual(t, budget.Spent(), 20*time.Millisecond)
+
+		_, err = bunql.New().Median(ctx, db.NewSelect().Model((*Item)(nil)), "price")
+		require.ErrorIs(t, err, bunql.ErrBudgetExceeded)
+		require.True(t, budget.Partial())
+	})
+}
@@ -0,0 +1,113 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// memoryCache is a ResultCache keeping the values in memory, ignoring their time to live
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	return value, ok, c.err
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key], c.ttls[key] = value, ttl
+	return c.err
+}
+
+// TestResultCache tests that pages are served from the result cache until their table is invalidated
+func TestResultCache(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 5)
+
+	hook := &slowQueryHook{}
+	hooked := bun.NewDB(db.DB, sqlitedialect.New())
+	hooked.AddQueryHook(hook)
+
+	cache := newMemoryCache()
+	execute := func(filterJSON string) ([]Item, int) {
+		ql, err := bunql.ParseFromParams(filterJSON, "price:asc", 1, 2)
+		require.NoError(t, err)
+		items, total, err := bunql.ExecutePage[Item](ctx, ql.WithResultCache(cache, time.Minute), hooked.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		return items, total
+	}
+	categoryA := `{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}`
+
+	items, total := execute(categoryA)
+	require.Equal(t, int32(2), hook.queries.Load())
+	require.Len(t, items, 2)
+	require.Equal(t, 3, total)
+
+	// The same page is served from the cache, and a different page is not
+	cachedItems, cachedTotal := execute(categoryA)
+	require.Equal(t, int32(2), hook.queries.Load())
+	require.Equal(t, items, cachedItems)
+	require.Equal(t, total, cachedTotal)
+
+	_, total = execute("")
+	require.Equal(t, int32(4), hook.queries.Load())
+	require.Equal(t, 5, total)
+	for key, ttl := range cache.ttls {
+		require.Equal(t, time.Minute, ttl, key)
+	}
+
+	// Writes invalidate the pages of the table
+	_, err := db.NewDelete().Model((*Item)(nil)).Where("name = ?", "Item1").Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, bunql.InvalidateResults(ctx, cache, "items"))
+	items, total = execute(categoryA)
+	require.Equal(t, int32(6), hook.queries.Load())
+	require.Equal(t, "Item3", items[0].Name)
+	require.Equal(t, 2, total)
+
+	// Cache errors fall back to the database
+	cache.err = errors.New("cache unavailable")
+	_, total = execute(categoryA)
+	require.Equal(t, int32(8), hook.queries.Load())
+	require.Equal(t, 2, total)
+	// Pages read on a transaction, which may see uncommitted rows, are neither cached nor served from the cache
+	cache.err = nil
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.NewInsert().Model(&Item{Name: "Uncommitted", Category: "a", Price: 5}).Exec(ctx)
+	require.NoError(t, err)
+	ql, err := bunql.ParseFromParams(categoryA, "price:asc", 1, 2)
+	require.NoError(t, err)
+	items, total, err = bunql.ExecutePage[Item](ctx, ql.WithResultCache(cache, time.Minute), tx.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, "Uncommitted", items[0].Name)
+	require.Equal(t, 3, total)
+	require.NoError(t, tx.Rollback())
+
+	items, total = execute(categoryA)
+	require.Equal(t, "Item3", items[0].Name)
+	require.Equal(t, 2, total)
+}
@@ -0,0 +1,106 @@
+package e2e
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// blockingQueryHook holds the matching queries until their context is done, or for at most a second
+type blockingQueryHook struct {
+	match func(query string) bool
+}
+
+func (h *blockingQueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	if h.match(event.Query) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+	return ctx
+}
+
+func (h *blockingQueryHook) AfterQuery(context.Context, *bun.QueryEvent) {}
+
+// isCountQuery reports whether a query is a count query
+func isCountQuery(query string) bool {
+	return strings.HasPrefix(query, "SELECT count(*)")
+}
+
+// TestExecuteWithCountCancellation tests that a failed query or a canceled context cancels the other query at once
+func TestExecuteWithCountCancellation(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	blockMain := bun.NewDB(db.DB, sqlitedialect.New())
+	blockMain.AddQueryHook(&blockingQueryHook{match: func(query string) bool { return !isCountQuery(query) }})
+	blockCount := bun.NewDB(db.DB, sqlitedialect.New())
+	blockCount.AddQueryHook(&blockingQueryHook{match: isCountQuery})
+
+	t.Run("Failed count query cancels the main query", func(t *testing.T) {
+		start := time.Now()
+		_, _, err := bunql.ExecuteWithCount[Item](ctx,
+			blockMain.NewSelect().Model((*Item)(nil)),
+			blockMain.NewSelect().Model((*MissingItem)(nil)))
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.ErrorContains(t, err, "failed to execute count query")
+		require.ErrorContains(t, err, "no such table")
+		require.NotErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Failed main query cancels the count query", func(t *testing.T) {
+		start := time.Now()
+		_, _, err := bunql.ExecuteWithCount[MissingItem](ctx,
+			blockCount.NewSelect().Model((*MissingItem)(nil)),
+			blockCount.NewSelect().Model((*Item)(nil)))
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.ErrorContains(t, err, "failed to execute main query")
+		require.ErrorContains(t, err, "no such table")
+		require.NotErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Canceled context cancels both queries", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, _, err := bunql.ExecuteWithCount[Item](canceled,
+			blockMain.NewSelect().Model((*Item)(nil)),
+			blockCount.NewSelect().Model((*Item)(nil)))
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.ErrorIs(t, err, context.Canceled)
+		require.EqualError(t, err, "query execution canceled: context canceled")
+	})
+
+	t.Run("Queries succeed concurrently", func(t *testing.T) {
+		items, total, err := bunql.ExecuteWithCount[Item](ctx,
+			db.NewSelect().Model((*Item)(nil)),
+			db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		require.Equal(t, 3, total)
+	})
+
+	t.Run("Queries on a transaction run one after the other", func(t *testing.T) {
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		items, total, err := bunql.ExecuteWithCount[Item](ctx,
+			tx.NewSelect().Model((*Item)(nil)),
+			tx.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		require.Equal(t, 3, total)
+	})
+}
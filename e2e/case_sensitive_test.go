@@ -0,0 +1,47 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCaseSensitive tests that filters compare case-sensitively or not according to the field and the filter
+func TestCaseSensitive(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	count := func(ql *bunql.BunQL, filterJSON string) int {
+		parsed, err := bunql.ParseFromParams(filterJSON, "", 0, 0)
+		require.NoError(t, err)
+		ql.WithFilters(parsed.Filters)
+		var items []Item
+		require.NoError(t, ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items))
+		return len(items)
+	}
+	insensitive, sensitive := false, true
+
+	// SQLite compares with = case-sensitively, and with LIKE case-insensitively
+	require.Equal(t, 0, count(bunql.New(), `[{"field": "name", "operator": "eq", "value": "item1"}]`))
+	require.Equal(t, 3, count(bunql.New(), `[{"field": "name", "operator": "like", "value": "item"}]`))
+
+	// The field configuration makes both forgiving, or both exact
+	forgiving := func() *bunql.BunQL {
+		return bunql.New().WithFieldConfig("name", dto.FieldConfig{CaseSensitive: &insensitive})
+	}
+	require.Equal(t, 1, count(forgiving(), `[{"field": "name", "operator": "eq", "value": "item1"}]`))
+	require.Equal(t, 1, count(forgiving(), `[{"field": "name", "operator": "in", "value": ["ITEM2", "item9"]}]`))
+	exact := bunql.New().WithFieldConfig("name", dto.FieldConfig{CaseSensitive: &sensitive})
+	require.Equal(t, 0, count(exact, `[{"field": "name", "operator": "like", "value": "item"}]`))
+	require.Equal(t, 3, count(exact, `[{"field": "name", "operator": "like", "value": "Item"}]`))
+
+	// Filters override the field configuration
+	require.Equal(t, 0, count(forgiving(), `[{"field": "name", "operator": "eq", "value": "item1", "caseSensitive": true}]`))
+	require.Equal(t, 3, count(exact, `[{"field": "name", "operator": "like", "value": "ITEM", "caseSensitive": false}]`))
+}
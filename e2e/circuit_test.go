@@ -0,0 +1,180 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// MissingItem is a model whose table does not exist, so that its queries fail
+type MissingItem struct {
+	bun.BaseModel `bun:"table:missing_items"`
+
+	ID int64 `bun:"id,pk"`
+}
+
+// TestCircuitBreaker tests that executions fail fast while the circuit is open, and that a probe closes it
+func TestCircuitBreaker(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	var transitions []string
+	breaker := bunql.NewCircuitBreaker(bunql.CircuitBreakerConfig{
+		MinRequests: 2,
+		OpenTimeout: 100 * time.Millisecond,
+		OnStateChange: func(from, to bunql.CircuitState) {
+			transitions = append(transitions, from.String()+" -> "+to.String())
+		},
+		// Count every error, so that the queries of a missing table fail
+		IsFailure: func(err error) bool { return true },
+	})
+	ql := bunql.New().WithCircuitBreaker(breaker)
+
+	// A success and a failure reach the failure rate of 0.5
+	_, _, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	_, _, err = bunql.ExecutePage[MissingItem](ctx, ql, db.NewSelect().Model((*MissingItem)(nil)))
+	require.Error(t, err)
+	require.False(t, errors.Is(err, bunql.ErrCircuitOpen))
+	require.Equal(t, bunql.CircuitOpen, breaker.State())
+
+	// Executions are rejected without querying the database
+	_, _, err = bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.ErrorIs(t, err, bunql.ErrCircuitOpen)
+	var openErr *bunql.CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	require.Greater(t, openErr.RetryAfter, time.Duration(0))
+
+	// After the open timeout, a failed probe opens the circuit again and a successful one closes it
+	time.Sleep(110 * time.Millisecond)
+	require.Equal(t, bunql.CircuitHalfOpen, breaker.State())
+	_, _, err = bunql.ExecutePage[MissingItem](ctx, ql, db.NewSelect().Model((*MissingItem)(nil)))
+	require.False(t, errors.Is(err, bunql.ErrCircuitOpen))
+	require.Equal(t, bunql.CircuitOpen, breaker.State())
+
+	time.Sleep(110 * time.Millisecond)
+	items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	require.Equal(t, 3, total)
+	require.Equal(t, bunql.CircuitClosed, breaker.State())
+
+	require.Equal(t, []string{"closed -> open", "open -> half-open", "half-open -> open", "open -> half-open", "half-open -> closed"}, transitions)
+}
+
+// TestCircuitBreakerProbes tests that the executions rejected while a probe runs are told when to retry
+func TestCircuitBreakerProbes(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	breaker := bunql.NewCircuitBreaker(bunql.CircuitBreakerConfig{
+		MinRequests: 1,
+		OpenTimeout: 50 * time.Millisecond,
+		IsFailure:   func(err error) bool { return true },
+	})
+	_, _, err := bunql.ExecutePage[MissingItem](ctx, bunql.New().WithCircuitBreaker(breaker), db.NewSelect().Model((*MissingItem)(nil)))
+	require.Error(t, err)
+	require.Equal(t, bunql.CircuitOpen, breaker.State())
+	time.Sleep(60 * time.Millisecond)
+
+	// The middleware holds the probe until it is released
+	entered, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	probing := bunql.New().WithCircuitBreaker(breaker).WithMiddleware(func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			once.Do(func() {
+				close(entered)
+				<-release
+			})
+			return next(ctx, ql, query)
+		}
+	})
+	done := make(chan error)
+	go func() {
+		_, _, err := bunql.ExecutePage[Item](ctx, probing, db.NewSelect().Model((*Item)(nil)))
+		done <- err
+	}()
+	<-entered
+
+	_, _, err = bunql.ExecutePage[Item](ctx, bunql.New().WithCircuitBreaker(breaker), db.NewSelect().Model((*Item)(nil)))
+	var openErr *bunql.CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, 50*time.Millisecond, openErr.RetryAfter)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, bunql.CircuitClosed, breaker.State())
+}
+
+// TestCircuitBreakerLatency tests that slow executions open the circuit
+func TestCircuitBreakerLatency(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	hooked := bun.NewDB(db.DB, sqlitedialect.New())
+	hooked.AddQueryHook(&slowQueryHook{})
+
+	breaker := bunql.NewCircuitBreaker(bunql.CircuitBreakerConfig{MinRequests: 1, SlowThreshold: 20 * time.Millisecond})
+	ql := bunql.New().WithCircuitBreaker(breaker)
+
+	// The slow execution succeeds, but opens the circuit
+	_, _, err := bunql.ExecutePage[Item](ctx, ql, hooked.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, bunql.CircuitOpen, breaker.State())
+
+	_, _, err = bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.ErrorIs(t, err, bunql.ErrCircuitOpen)
+
+	// Executions canceled by the caller are not counted
+	breaker = bunql.NewCircuitBreaker(bunql.CircuitBreakerConfig{MinRequests: 1})
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = bunql.ExecutePage[Item](canceled, bunql.New().WithCircuitBreaker(breaker), db.NewSelect().Model((*Item)(nil)))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, bunql.CircuitClosed, breaker.State())
+}
+
+// TestCircuitBreakerErrors tests that only timeouts and connection errors count as failures by default
+func TestCircuitBreakerErrors(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	breaker := bunql.NewCircuitBreaker(bunql.CircuitBreakerConfig{MinRequests: 1})
+	ql := bunql.New().WithCircuitBreaker(breaker)
+
+	// The errors of the query itself don't open the circuit
+	for i := 0; i < 3; i++ {
+		_, _, err := bunql.ExecutePage[MissingItem](ctx, ql, db.NewSelect().Model((*MissingItem)(nil)))
+		require.Error(t, err)
+		require.False(t, errors.Is(err, bunql.ErrCircuitOpen))
+	}
+	require.Equal(t, bunql.CircuitClosed, breaker.State())
+
+	// A timeout does
+	breaker = bunql.NewCircuitBreaker(bunql.CircuitBreakerConfig{MinRequests: 1})
+	ql = bunql.New().WithCircuitBreaker(breaker).WithTimeout(10 * time.Millisecond)
+	hooked := bun.NewDB(db.DB, sqlitedialect.New())
+	hooked.AddQueryHook(&slowQueryHook{})
+	_, _, err := bunql.ExecutePage[Item](ctx, ql, hooked.NewSelect().Model((*Item)(nil)))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, bunql.CircuitOpen, breaker.State())
+}
@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type CategoryTotal struct {
+	Category string `bun:"category"`
+	Total    int    `bun:"total"`
+}
+
+// TestComputedFields tests filtering and sorting on the aliased expressions selected by the base query
+func TestComputedFields(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	totals := func() *bun.SelectQuery {
+		return db.NewSelect().TableExpr("items AS i").
+			Column("i.category").ColumnExpr("SUM(i.price) AS total").
+			Group("i.category")
+	}
+	parse := func(filterJSON, sort string) *bunql.BunQL {
+		ql, err := bunql.ParseFromParams(filterJSON, sort, 0, 0)
+		require.NoError(t, err)
+		return ql.WithComputedField("total", bunql.ComputedField{Expression: "SUM(i.price)", Aggregate: true}).
+			WithComputedField("double_price", bunql.ComputedField{Expression: "i.price * 2"})
+	}
+
+	// The "a" items sum to 90 and the "b" items to 120
+	var rows []CategoryTotal
+	ql := parse(`{"filters": [{"field": "total", "operator": "gt", "value": 100}]}`, "total:desc")
+	require.NoError(t, ql.Apply(ctx, totals()).Scan(ctx, &rows))
+	require.Equal(t, []CategoryTotal{{Category: "b", Total: 120}}, rows)
+
+	// Filters on aggregates go to HAVING and the others to WHERE, on the expression of computed fields
+	rows = nil
+	ql = parse(`{"filters": [{"field": "double_price", "operator": "gte", "value": 60}, {"field": "total", "operator": "lt", "value": 100}]}`, "total:desc")
+	query := ql.Apply(ctx, totals())
+	require.Contains(t, query.String(), "WHERE ((((i.price * 2) >= 60)))")
+	require.Contains(t, query.String(), "HAVING ((((SUM(i.price)) < 100)))")
+	require.NoError(t, query.Scan(ctx, &rows))
+	require.Equal(t, []CategoryTotal{{Category: "a", Total: 80}}, rows)
+
+	// OR groups mixing aggregates and grouped columns are filtered in HAVING as a whole
+	rows = nil
+	ql = parse(`{"logic": "or", "filters": [{"field": "total", "operator": "gt", "value": 100}, {"field": "i.category", "operator": "eq", "value": "a"}]}`, "total:asc")
+	require.NoError(t, ql.Apply(ctx, totals()).Scan(ctx, &rows))
+	require.Equal(t, []CategoryTotal{{Category: "a", Total: 90}, {Category: "b", Total: 120}}, rows)
+
+	// Sorts replacing NULLs use the expression, as aliases can't be used in expressions
+	rows = nil
+	ql = parse(`[]`, `[{"field": "total", "dir": "desc", "nullsAs": 0}]`)
+	require.NoError(t, ql.Apply(ctx, totals()).Scan(ctx, &rows))
+	require.Equal(t, []CategoryTotal{{Category: "b", Total: 120}, {Category: "a", Total: 90}}, rows)
+
+	// The groups matching the filters on aggregates are counted
+	ql = parse(`{"filters": [{"field": "total", "operator": "gt", "value": 100}]}`, "")
+	_, countQuery := ql.ApplyWithCount(ctx, totals())
+	count, err := countQuery.Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
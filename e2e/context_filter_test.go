@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// categoryKey and minPriceKey are context keys set by upstream middleware
+type categoryKey struct{}
+type minPriceKey struct{}
+
+// TestContextFilters tests that filters are materialized from the values of the context at Apply time
+func TestContextFilters(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	policy := bunql.Policy{
+		FilterFields: []string{"name"},
+		ContextFilters: []bunql.ContextFilter{
+			{Key: categoryKey{}, Field: "category"},
+			{Key: minPriceKey{}, Field: "price", Operator: "gte", Optional: true},
+		},
+	}
+
+	t.Run("Values of the context", func(t *testing.T) {
+		ctx := context.WithValue(ctx, categoryKey{}, "a")
+		items, total, err := bunql.ExecutePage[Item](ctx, bunql.NewWithPolicy(policy), db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		require.Equal(t, 3, total)
+
+		ctx = context.WithValue(ctx, minPriceKey{}, 30)
+		items, total, err = bunql.ExecutePage[Item](ctx, bunql.NewWithPolicy(policy), db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, 2, total)
+	})
+
+	t.Run("Missing required value", func(t *testing.T) {
+		items, total, err := bunql.ExecutePage[Item](ctx, bunql.NewWithPolicy(policy), db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		require.Empty(t, items)
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("Combined with the filters of the request", func(t *testing.T) {
+		ql, err := bunql.ParseFromParamsWithAllowedFields(`{"logic": "or", "filters": [
+			{"field": "name", "operator": "eq", "value": "Item1"},
+			{"field": "name", "operator": "eq", "value": "Item2"}
+		]}`, "", 0, 0, []string{"name"}, nil)
+		require.NoError(t, err)
+		ql.WithContextFilters(bunql.ContextFilter{Key: categoryKey{}, Field: "category"})
+
+		var items []Item
+		query := ql.Apply(context.WithValue(ctx, categoryKey{}, "b"), db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, query.Scan(ctx, &items))
+		require.Len(t, items, 1)
+		require.Equal(t, "Item2", items[0].Name)
+	})
+}
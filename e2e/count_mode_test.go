@@ -0,0 +1,50 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCountMode tests that the pagination metadata tells how the total count of a page was obtained
+func TestCountMode(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 5)
+
+	cache := newMemoryCache()
+	execute := func(ctx context.Context) dto.CountMode {
+		ql, err := bunql.ParseFromParams("", "price:asc", 1, 2)
+		require.NoError(t, err)
+		ctx = bunql.TrackCountMode(ctx)
+		_, total, err := bunql.ExecutePage[Item](ctx, ql.WithResultCache(cache, time.Minute), db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+
+		metadata := bunql.GetPaginationMetadata(ql.Pagination, total, "/items")
+		metadata.CountMode = bunql.CountModeFromContext(ctx)
+		return metadata.CountMode
+	}
+
+	require.Equal(t, dto.CountExact, execute(ctx))
+	require.Equal(t, dto.CountCached, execute(ctx))
+
+	// Counts skipped by the query budget are unknown, and their pages aren't cached
+	require.NoError(t, bunql.InvalidateResults(ctx, cache, "items"))
+	require.Equal(t, dto.CountUnknown, execute(bunql.WithQueryBudget(ctx, bunql.NewQueryBudget(1, 0))))
+
+	// Untracked contexts have no count mode, and the metadata marks the totals it is given
+	require.Empty(t, bunql.CountModeFromContext(ctx))
+	metadata := bunql.GetPaginationMetadata(&dto.Pagination{Page: 1, PageSize: 2}, 5, "/items")
+	require.Equal(t, dto.CountExact, metadata.CountMode)
+	out, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"countMode":"exact"`)
+	require.Equal(t, dto.CountUnknown, bunql.GetPaginationMetadata(nil, -1, "/items").CountMode)
+}
@@ -0,0 +1,129 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type ItemTag struct {
+	bun.BaseModel `bun:"table:item_tags,alias:t"`
+
+	ItemID int64  `bun:"item_id,pk"`
+	Tag    string `bun:"tag,pk"`
+}
+
+// TestCountStrategy tests that rows multiplied by the joins of a query are counted once
+func TestCountStrategy(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 4)
+
+	require.NoError(t, db.ResetModel(ctx, (*ItemTag)(nil)))
+	tags := []ItemTag{
+		{ItemID: 1, Tag: "red"}, {ItemID: 1, Tag: "blue"}, {ItemID: 1, Tag: "green"},
+		{ItemID: 2, Tag: "red"},
+		{ItemID: 3, Tag: "blue"}, {ItemID: 3, Tag: "green"},
+	}
+	_, err := db.NewInsert().Model(&tags).Exec(ctx)
+	require.NoError(t, err)
+
+	filterJSON := `{"filters": [{"field": "i.category", "operator": "eq", "value": "a"}]}`
+	tagged := func() *bun.SelectQuery {
+		return db.NewSelect().Model((*Item)(nil)).Join("JOIN item_tags AS t ON t.item_id = i.id")
+	}
+
+	// Items 1 and 3 are in the "a" category, joined with 5 tags
+	ql, err := bunql.ParseFromParams(filterJSON, "", 1, 10)
+	require.NoError(t, err)
+	items, total, err := bunql.ExecutePage[Item](ctx, ql, tagged())
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	require.Equal(t, 2, total)
+
+	mainQuery, countQuery := ql.ApplyWithCount(ctx, tagged())
+	_, total, err = bunql.ExecuteWithCount[Item](ctx, mainQuery, countQuery)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+
+	ql, err = bunql.ParseFromParams(filterJSON, "", 1, 10)
+	require.NoError(t, err)
+	_, total, err = bunql.ExecutePage[Item](ctx, ql.WithCountStrategy(bunql.CountRows), tagged())
+	require.NoError(t, err)
+	require.Equal(t, 5, total)
+
+	// Queries without joins count their rows
+	ql, err = bunql.ParseFromParams(filterJSON, "", 1, 10)
+	require.NoError(t, err)
+	_, total, err = bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+
+	// Composite primary keys are counted over the distinct keys
+	ql, err = bunql.ParseFromParams(`{"filters": [{"field": "t.tag", "operator": "neq", "value": "red"}]}`, "", 1, 10)
+	require.NoError(t, err)
+	query := db.NewSelect().Model((*ItemTag)(nil)).Join("JOIN items AS i ON i.category = ?", "a")
+	rows, total, err := bunql.ExecutePage[ItemTag](ctx, ql.WithCountStrategy(bunql.CountDistinct), query)
+	require.NoError(t, err)
+	require.Len(t, rows, 8)
+	require.Equal(t, 4, total)
+}
+
+// TestCountGroups tests that the pages of aggregate listings count their groups
+func TestCountGroups(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	parse := func(filterJSON string) *bunql.BunQL {
+		ql, err := bunql.ParseFromParams(filterJSON, "total:desc", 1, 1)
+		require.NoError(t, err)
+		return ql.WithComputedField("total", bunql.ComputedField{Expression: "SUM(i.price)", Aggregate: true})
+	}
+	grouped := func() *bun.SelectQuery {
+		return db.NewSelect().TableExpr("items AS i").
+			Column("i.category").ColumnExpr("SUM(i.price) AS total").
+			Group("i.category")
+	}
+	aggregated := func() *bun.SelectQuery {
+		return db.NewSelect().TableExpr("items AS i").ColumnExpr("SUM(i.price) AS total")
+	}
+
+	// The "a" items sum to 90 and the "b" items to 120
+	rows, total, err := bunql.ExecutePage[CategoryTotal](ctx, parse(""), grouped())
+	require.NoError(t, err)
+	require.Equal(t, []CategoryTotal{{Category: "b", Total: 120}}, rows)
+	require.Equal(t, 2, total)
+
+	rows, total, err = bunql.ExecutePage[CategoryTotal](ctx, parse(`{"filters": [{"field": "total", "operator": "lt", "value": 100}]}`), grouped())
+	require.NoError(t, err)
+	require.Equal(t, []CategoryTotal{{Category: "a", Total: 90}}, rows)
+	require.Equal(t, 1, total)
+
+	// An aggregate without GROUP BY is a single row, or none when filtered out
+	_, total, err = bunql.ExecutePage[CategoryTotal](ctx, parse(""), aggregated())
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	rows, total, err = bunql.ExecutePage[CategoryTotal](ctx, parse(`{"filters": [{"field": "total", "operator": "lt", "value": 100}]}`), aggregated())
+	require.NoError(t, err)
+	require.Empty(t, rows)
+	require.Equal(t, 0, total)
+
+	// Without aggregate fields, the strategy is set explicitly
+	ql, err := bunql.ParseFromParams("", "", 1, 10)
+	require.NoError(t, err)
+	_, total, err = bunql.ExecutePage[CategoryTotal](ctx, ql, aggregated())
+	require.NoError(t, err)
+	require.Equal(t, 6, total)
+	_, total, err = bunql.ExecutePage[CategoryTotal](ctx, ql.WithCountStrategy(bunql.CountGroups), aggregated())
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
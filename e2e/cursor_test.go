@@ -0,0 +1,263 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// TestCursorPagination tests walking pages with signed cursors over a multi-column sort
+func TestCursorPagination(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 10)
+
+	secret := []byte("cursor-secret")
+	sortJSON := `[
+		{"field": "category", "dir": "asc"},
+		{"field": "price", "dir": "desc"},
+		{"field": "id", "dir": "asc"}
+	]`
+
+	// Test 1: Follow the next tokens until exhausted
+	t.Run("Forward", func(t *testing.T) {
+		var names []string
+		token := ""
+		for pages := 0; pages < 10; pages++ {
+			ql, err := bunql.ParseFromParams("", sortJSON, 0, 3)
+			require.NoError(t, err, "Failed to parse parameters")
+
+			if token != "" {
+				c, err := cursor.Decode(token, secret)
+				require.NoError(t, err, "Failed to decode cursor")
+				ql.WithCursor(c)
+			}
+
+			items, metadata, err := bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+			require.NoError(t, err, "Query execution failed")
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+
+			if metadata.Next == nil {
+				break
+			}
+			token = *metadata.Next
+		}
+
+		require.Equal(t, []string{
+			"Item9", "Item7", "Item5", "Item3", "Item1",
+			"Item10", "Item8", "Item6", "Item4", "Item2",
+		}, names)
+	})
+
+	// Test 2: Walk forward two pages, then follow the prev tokens back
+	t.Run("Backward", func(t *testing.T) {
+		fetch := func(token *string) ([]string, bunql.CursorPaginationMetadataOutput) {
+			ql, err := bunql.ParseFromParams("", sortJSON, 0, 3)
+			require.NoError(t, err, "Failed to parse parameters")
+
+			if token != nil {
+				c, err := cursor.Decode(*token, secret)
+				require.NoError(t, err, "Failed to decode cursor")
+				ql.WithCursor(c)
+			}
+
+			items, metadata, err := bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+			require.NoError(t, err, "Query execution failed")
+
+			names := make([]string, len(items))
+			for i, item := range items {
+				names[i] = item.Name
+			}
+			return names, metadata
+		}
+
+		page1, metadata := fetch(nil)
+		require.Nil(t, metadata.Prev, "The first page should not have a prev token")
+		page2, metadata := fetch(metadata.Next)
+		page3, metadata := fetch(metadata.Next)
+		require.Equal(t, []string{"Item8", "Item6", "Item4"}, page3)
+
+		back2, metadata := fetch(metadata.Prev)
+		require.Equal(t, page2, back2)
+		require.NotNil(t, metadata.Next)
+
+		back1, metadata := fetch(metadata.Prev)
+		require.Equal(t, page1, back1)
+		require.Nil(t, metadata.Prev, "The first page should not have a prev token")
+		require.NotNil(t, metadata.Next)
+	})
+
+	// Test 3: Cursor created for another sort
+	t.Run("Sort mismatch", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", sortJSON, 0, 3)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		_, metadata, err := bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+		require.NoError(t, err, "Query execution failed")
+		require.NotNil(t, metadata.Next)
+
+		c, err := cursor.Decode(*metadata.Next, secret)
+		require.NoError(t, err, "Failed to decode cursor")
+
+		ql, err = bunql.ParseFromParams("", `[{"field": "id", "dir": "asc"}]`, 0, 3)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithCursor(c)
+
+		_, _, err = bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+		require.Error(t, err, "Should fail when the cursor was created for another sort")
+		require.Contains(t, err.Error(), "cursor does not match the requested sort")
+	})
+	// Test 4: Pages of pointers to the model
+	t.Run("Pointer rows", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", sortJSON, 0, 3)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		items, metadata, err := bunql.ExecuteCursorPage[*Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+		require.NoError(t, err, "Query execution failed")
+		require.Len(t, items, 3)
+		require.Equal(t, "Item9", items[0].Name)
+		require.NotNil(t, metadata.Next)
+
+		c, err := cursor.Decode(*metadata.Next, secret)
+		require.NoError(t, err, "Failed to decode cursor")
+		ql.WithCursor(c)
+		items, _, err = bunql.ExecuteCursorPage[*Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+		require.NoError(t, err, "Query execution failed")
+		require.Equal(t, "Item3", items[0].Name)
+
+		_, _, err = bunql.ExecuteCursorPage[int64](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+		require.EqualError(t, err, "cursor pagination requires a struct model, got int64")
+	})
+}
+
+// TestCursorPaginationTimes tests walking pages sorted on a time column, whose cursor values must stay times
+func TestCursorPaginationTimes(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `DROP TABLE IF EXISTS events`)
+	require.NoError(t, err, "Failed to drop table")
+	_, err = db.NewCreateTable().Model((*Event)(nil)).Exec(ctx)
+	require.NoError(t, err, "Failed to create table")
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	events := make([]Event, 5)
+	for i := range events {
+		events[i] = Event{Name: "signup", HappenedAt: start.Add(time.Duration(i) * time.Hour)}
+	}
+	_, err = db.NewInsert().Model(&events).Exec(ctx)
+	require.NoError(t, err, "Failed to insert events")
+
+	secret := []byte("cursor-secret")
+	sortJSON := `[{"field": "happened_at", "dir": "asc"}, {"field": "id", "dir": "asc"}]`
+
+	var ids []int64
+	token := ""
+	for pages := 0; pages < 5; pages++ {
+		ql, err := bunql.ParseFromParams("", sortJSON, 0, 2)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		if token != "" {
+			c, err := cursor.Decode(token, secret)
+			require.NoError(t, err, "Failed to decode cursor")
+			require.IsType(t, time.Time{}, c.Values[0], "The time cursor value should be decoded as a time")
+			ql.WithCursor(c)
+		}
+
+		page, metadata, err := bunql.ExecuteCursorPage[Event](ctx, ql, db.NewSelect().Model((*Event)(nil)), secret)
+		require.NoError(t, err, "Query execution failed")
+		for _, event := range page {
+			ids = append(ids, event.ID)
+		}
+
+		if metadata.Next == nil {
+			break
+		}
+		token = *metadata.Next
+	}
+
+	require.Equal(t, []int64{1, 2, 3, 4, 5}, ids)
+}
+
+// TestCursorPaginationNullable tests that nullable sort fields need nullsAs under cursor pagination
+func TestCursorPaginationNullable(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	type Task struct {
+		bun.BaseModel `bun:"table:tasks"`
+
+		ID       int64 `bun:"id,pk,autoincrement"`
+		Priority *int  `bun:"priority"`
+	}
+
+	_, err := db.ExecContext(ctx, `DROP TABLE IF EXISTS tasks`)
+	require.NoError(t, err, "Failed to drop table")
+	_, err = db.NewCreateTable().Model((*Task)(nil)).Exec(ctx)
+	require.NoError(t, err, "Failed to create table")
+
+	one, two := 1, 2
+	tasks := []Task{{Priority: &two}, {}, {Priority: &one}, {}, {}}
+	_, err = db.NewInsert().Model(&tasks).Exec(ctx)
+	require.NoError(t, err, "Failed to insert tasks")
+
+	secret := []byte("cursor-secret")
+
+	// Test 1: NULLs would stop the pages, so the field is rejected
+	t.Run("Rejected", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", `[{"field": "priority", "dir": "asc"}]`, 0, 2)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		_, _, err = bunql.ExecuteCursorPage[Task](ctx, ql, db.NewSelect().Model((*Task)(nil)), secret)
+		require.EqualError(t, err, "cursor pagination cannot sort on the nullable field 'priority' without nullsAs")
+	})
+
+	// Test 2: NULLs sorted as nullsAs are walked through, across pages and backward
+	t.Run("NullsAs", func(t *testing.T) {
+		fetch := func(token *string) ([]int64, bunql.CursorPaginationMetadataOutput) {
+			ql, err := bunql.ParseFromParams("", `[{"field": "priority", "dir": "asc", "nullsAs": 0}]`, 0, 2)
+			require.NoError(t, err, "Failed to parse parameters")
+
+			if token != nil {
+				c, err := cursor.Decode(*token, secret)
+				require.NoError(t, err, "Failed to decode cursor")
+				ql.WithCursor(c)
+			}
+
+			page, metadata, err := bunql.ExecuteCursorPage[Task](ctx, ql, db.NewSelect().Model((*Task)(nil)), secret)
+			require.NoError(t, err, "Query execution failed")
+
+			ids := make([]int64, len(page))
+			for i, task := range page {
+				ids[i] = task.ID
+			}
+			return ids, metadata
+		}
+
+		page1, metadata := fetch(nil)
+		require.Equal(t, []int64{2, 4}, page1)
+		page2, metadata := fetch(metadata.Next)
+		require.Equal(t, []int64{5, 3}, page2)
+		page3, metadata := fetch(metadata.Next)
+		require.Equal(t, []int64{1}, page3)
+		require.Nil(t, metadata.Next)
+
+		back2, metadata := fetch(metadata.Prev)
+		require.Equal(t, page2, back2)
+		back1, _ := fetch(metadata.Prev)
+		require.Equal(t, page1, back1)
+	})
+}
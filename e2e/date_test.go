@@ -0,0 +1,107 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type Event struct {
+	bun.BaseModel `bun:"table:events"`
+
+	ID         int64     `bun:"id,pk,autoincrement"`
+	Name       string    `bun:"name"`
+	HappenedAt time.Time `bun:"happened_at"`
+}
+
+// TestDateFilters tests that filters on date strings compare dates on SQLite
+func TestDateFilters(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `DROP TABLE IF EXISTS events`)
+	require.NoError(t, err, "Failed to drop table")
+	_, err = db.NewCreateTable().Model((*Event)(nil)).Exec(ctx)
+	require.NoError(t, err, "Failed to create table")
+
+	events := []Event{
+		{Name: "Kickoff", HappenedAt: time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)},
+		{Name: "Review", HappenedAt: time.Date(2024, 2, 1, 18, 0, 0, 0, time.UTC)},
+		{Name: "Launch", HappenedAt: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)},
+	}
+	_, err = db.NewInsert().Model(&events).Exec(ctx)
+	require.NoError(t, err, "Failed to insert events")
+
+	tests := []struct {
+		name       string
+		filterJSON string
+		locale     filter.DateLocale
+		expected   []string
+	}{
+		{
+			name:       "Equal to a day ignores the time",
+			filterJSON: `{"filters": [{"field": "happened_at", "operator": "eq", "value": "2024-02-01"}]}`,
+			expected:   []string{"Review"},
+		},
+		{
+			name:       "Greater than a US date",
+			filterJSON: `{"filters": [{"field": "happened_at", "operator": "gt", "value": "1/15/2024"}]}`,
+			expected:   []string{"Review", "Launch"},
+		},
+		{
+			name:       "Between dates",
+			filterJSON: `{"filters": [{"field": "happened_at", "operator": "between", "value": ["2024/1/15", "2024-02-01"]}]}`,
+			expected:   []string{"Kickoff", "Review"},
+		},
+		{
+			name:       "Greater than a European date",
+			filterJSON: `{"filters": [{"field": "happened_at", "operator": "gt", "value": "01/02/2024"}]}`,
+			locale:     filter.Locales["fr"],
+			expected:   []string{"Launch"},
+		},
+		{
+			name:       "Equal to a date with a month name",
+			filterJSON: `{"filters": [{"field": "happened_at", "operator": "eq", "value": "10 mars 2024"}]}`,
+			locale:     filter.Locales["fr"],
+			expected:   []string{"Launch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ql, err := bunql.ParseFromParams(tt.filterJSON, `[{"field": "id", "dir": "asc"}]`, 0, 0)
+			require.NoError(t, err, "Failed to parse parameters")
+
+			var results []Event
+			err = ql.WithDateLocale(tt.locale).Apply(ctx, db.NewSelect().Model((*Event)(nil))).Scan(ctx, &results)
+			require.NoError(t, err, "Query failed")
+
+			names := make([]string, len(results))
+			for i, event := range results {
+				names[i] = event.Name
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+// TestTypedDateFilters tests that time fields accept the dates of the date locale, as the date filters read them
+func TestTypedDateFilters(t *testing.T) {
+	ql := bunql.New().
+		WithFieldConfig("happened_at", dto.FieldConfig{Type: dto.FieldTime}).
+		WithDateLocale(filter.Locales["fr"])
+
+	require.NoError(t, ql.AddFilter("happened_at", "gt", "01/02/2024"))
+	require.NoError(t, ql.AddFilter("happened_at", "lt", "10 mars 2024"))
+	require.NoError(t, ql.AddFilter("happened_at", "between", []string{"2024-01-15", "2024-03-10T12:00:00Z"}))
+	require.EqualError(t, ql.AddFilter("happened_at", "gt", "31/02/2024"), "filter field 'happened_at' requires time values")
+	require.EqualError(t, ql.AddFilter("happened_at", "gt", "2024-01-15 or later"), "filter field 'happened_at' requires time values")
+}
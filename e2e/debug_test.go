@@ -0,0 +1,58 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDebug tests that privileged callers get the compiled SQL and the filters of their page in its metadata
+func TestDebug(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 5)
+
+	parse := func() *bunql.BunQL {
+		ql, err := bunql.ParseFromParams(`{"filters": [{"field": "name", "operator": "eq", "value": "Item3"}, {"field": "price", "operator": "gte", "value": 20}]}`, "price:desc", 1, 2)
+		require.NoError(t, err)
+		return ql.WithFieldConfig("name", dto.FieldConfig{Sensitive: true}).WithFieldConfig("price", dto.FieldConfig{Sensitive: true})
+	}
+
+	// Contexts don't track the debug information unless asked to
+	ql := parse()
+	_, _, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Nil(t, bunql.DebugFromContext(ctx))
+
+	debugCtx := bunql.TrackDebug(ctx)
+	_, total, err := bunql.ExecutePage[Item](debugCtx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	metadata := bunql.GetPaginationMetadata(ql.Pagination, total, "/items")
+	metadata.Debug = bunql.DebugFromContext(debugCtx)
+
+	require.NotNil(t, metadata.Debug)
+	require.Contains(t, metadata.Debug.SQL, `"name" = '[REDACTED]'`)
+	require.Contains(t, metadata.Debug.SQL, "ORDER BY price DESC, id DESC LIMIT 2")
+	require.Contains(t, metadata.Debug.SQL, `"price" >= '[REDACTED]'`)
+	require.NotContains(t, metadata.Debug.SQL, "Item3")
+	require.Contains(t, metadata.Debug.CountSQL, `"name" = '[REDACTED]'`)
+	require.Equal(t, bunql.Redacted, metadata.Debug.Filters.Filters[0].Value)
+	require.Equal(t, []dto.SortField{{Field: "price", Direction: "desc"}}, metadata.Debug.Sort)
+	out, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"debug":{"sql":"SELECT`)
+
+	// Cursor pages carry it in their metadata
+	debugCtx = bunql.TrackDebug(ctx)
+	_, cursorMetadata, err := bunql.ExecuteCursorPage[Item](debugCtx, parse(), db.NewSelect().Model((*Item)(nil)), []byte("secret"))
+	require.NoError(t, err)
+	require.NotNil(t, cursorMetadata.Debug)
+	require.Contains(t, cursorMetadata.Debug.SQL, "LIMIT 3")
+	require.Empty(t, cursorMetadata.Debug.CountSQL)
+}
@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecimalFields tests that decimal values are validated and compared as decimal literals
+func TestDecimalFields(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 4)
+
+	policy := bunql.Policy{
+		Fields: map[string]dto.FieldConfig{
+			"price": {Type: dto.FieldDecimal, Precision: 6, Scale: 2},
+		},
+	}
+	parse := func(filter string) (*bunql.BunQL, error) {
+		request := httptest.NewRequest("GET", "/items?"+url.Values{"filter": {filter}}.Encode(), nil)
+		return bunql.ParseFromRequestWithPolicy(request, policy)
+	}
+
+	// Decimal strings and numbers are inlined as decimal literals
+	ql, err := parse(`{"filters": [{"field": "price", "operator": "gt", "value": "20.10"},
+		{"field": "price", "operator": "in", "value": [30, "40.00", 50.5]}]}`)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+	require.Contains(t, query.String(), `(("price" > 20.10)) AND (("price" IN (30, 40.00, 50.5)))`)
+
+	var items []Item
+	require.NoError(t, query.Scan(ctx, &items), "Query failed")
+	require.Len(t, items, 2)
+	require.Equal(t, "Item3", items[0].Name)
+	require.Equal(t, "Item4", items[1].Name)
+
+	// Values must be decimals fitting the precision and scale of the field
+	_, err = parse(`{"filters": [{"field": "price", "operator": "gt", "value": "10,10"}]}`)
+	require.EqualError(t, err, "filter field 'price' requires decimal values")
+
+	_, err = parse(`{"filters": [{"field": "price", "operator": "gt", "value": "10.105"}]}`)
+	require.EqualError(t, err, "filter field 'price' allows at most 2 decimal places")
+
+	_, err = parse(`{"filters": [{"field": "price", "operator": "between", "value": ["0.00", "12345.67"]}]}`)
+	require.EqualError(t, err, "filter field 'price' allows at most 6 digits")
+
+	// Trailing zeros don't count
+	_, err = parse(`{"filters": [{"field": "price", "operator": "lt", "value": "1234.5000"}]}`)
+	require.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+package e2e
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// slowQueryHook counts the queries and slows them down, so that concurrent identical requests overlap
+type slowQueryHook struct {
+	queries atomic.Int32
+}
+
+func (h *slowQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	h.queries.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	return ctx
+}
+
+func (h *slowQueryHook) AfterQuery(context.Context, *bun.QueryEvent) {}
+
+// TestDeduplicator tests that identical pages requested at the same time are executed once
+func TestDeduplicator(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	hook := &slowQueryHook{}
+	hooked := bun.NewDB(db.DB, sqlitedialect.New())
+	hooked.AddQueryHook(hook)
+
+	dedup := bunql.NewDeduplicator()
+	execute := func(filterJSON string) ([]Item, int, error) {
+		ql, err := bunql.ParseFromParams(filterJSON, "price:asc", 1, 2)
+		if err != nil {
+			return nil, 0, err
+		}
+		return bunql.ExecutePage[Item](ctx, ql.WithDeduplicator(dedup), hooked.NewSelect().Model((*Item)(nil)))
+	}
+
+	// A burst of identical requests; require must not be called from the goroutines
+	var wg sync.WaitGroup
+	results := make([][]Item, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items, total, err := execute(`{"filters": [{"field": "category", "operator": "eq", "value": "b"}]}`)
+			assert.NoError(t, err, "Execution failed")
+			assert.Equal(t, 3, total)
+			results[i] = items
+		}()
+	}
+	wg.Wait()
+
+	// One main query and one count query were executed, and every caller got its own copy of the rows
+	require.Equal(t, int32(2), hook.queries.Load())
+	for _, items := range results {
+		require.Len(t, items, 2)
+		require.Equal(t, "Item2", items[0].Name)
+	}
+	results[0][0].Name = "changed"
+	require.Equal(t, "Item2", results[1][0].Name)
+
+	// Different filters are executed separately
+	hook.queries.Store(0)
+	wg.Add(2)
+	for _, category := range []string{"a", "b"} {
+		go func() {
+			defer wg.Done()
+			_, _, err := execute(`{"filters": [{"field": "category", "operator": "eq", "value": "` + category + `"}]}`)
+			assert.NoError(t, err, "Execution failed")
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(4), hook.queries.Load())
+	// Pages read on a transaction are not shared with the pages read outside of it
+	tx, err := hooked.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	hook.queries.Store(0)
+	categoryB := `{"filters": [{"field": "category", "operator": "eq", "value": "b"}]}`
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _, err := execute(categoryB)
+		assert.NoError(t, err, "Execution failed")
+	}()
+	ql, err := bunql.ParseFromParams(categoryB, "price:asc", 1, 2)
+	require.NoError(t, err)
+	_, total, err := bunql.ExecutePage[Item](ctx, ql.WithDeduplicator(dedup), tx.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	wg.Wait()
+	require.Equal(t, int32(4), hook.queries.Load())
+}
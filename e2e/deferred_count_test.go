@@ -0,0 +1,115 @@
+package e2e
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// orderQueryHook records when the main and count queries start and end
+type orderQueryHook struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *orderQueryHook) record(event *bun.QueryEvent, step string) {
+	if !strings.HasPrefix(event.Query, "SELECT") {
+		return
+	}
+	name := "main"
+	if strings.Contains(event.Query, "count(*)") {
+		name = "count"
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, step+" "+name)
+}
+
+func (h *orderQueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	h.record(event, "start")
+	return ctx
+}
+
+func (h *orderQueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	h.record(event, "end")
+}
+
+// TestDeferredCount tests that the total count is delivered separately from the page results
+func TestDeferredCount(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 12)
+
+	filterJSON := `{
+		"logic": "and",
+		"filters": [
+			{"field": "category", "operator": "eq", "value": "a"}
+		]
+	}`
+
+	ql, err := bunql.ParseFromParams(filterJSON, `[{"field": "price", "dir": "asc"}]`, 1, 4)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	query := db.NewSelect().Model((*Item)(nil))
+	mainQuery, countQuery := ql.ApplyWithCount(ctx, query)
+
+	items, countCh, err := bunql.ExecuteWithDeferredCount[Item](ctx, mainQuery, countQuery)
+	require.NoError(t, err, "Query execution failed")
+	require.Len(t, items, 4, "Expected a full first page")
+
+	result := <-countCh
+	require.NoError(t, result.Err, "Count query failed")
+	require.Equal(t, 6, result.Count, "Expected 6 items in category a")
+
+	_, ok := <-countCh
+	require.False(t, ok, "The count channel should be closed after the result")
+}
+
+// TestDeferredCountInTransaction tests that the count query runs after the main query on a transaction, which can't
+// run two queries at once
+func TestDeferredCountInTransaction(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 12)
+
+	hook := &orderQueryHook{}
+	hooked := bun.NewDB(db.DB, sqlitedialect.New())
+	hooked.AddQueryHook(hook)
+
+	tx, err := hooked.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}`, "price:asc", 1, 4)
+	require.NoError(t, err)
+	mainQuery, countQuery := ql.ApplyWithCount(ctx, tx.NewSelect().Model((*Item)(nil)))
+
+	items, countCh, err := bunql.ExecuteWithDeferredCount[Item](ctx, mainQuery, countQuery)
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+
+	result := <-countCh
+	require.NoError(t, result.Err)
+	require.Equal(t, 6, result.Count)
+	require.Equal(t, []string{"start main", "end main", "start count", "end count"}, hook.events)
+
+	// The count query is skipped when the main query fails
+	hook.events = nil
+	mainQuery, countQuery = ql.ApplyWithCount(ctx, tx.NewSelect().Model((*MissingItem)(nil)))
+	_, countCh, err = bunql.ExecuteWithDeferredCount[MissingItem](ctx, mainQuery, countQuery)
+	require.Error(t, err)
+
+	result = <-countCh
+	require.ErrorContains(t, result.Err, "failed to execute main query")
+	require.Equal(t, []string{"start main", "end main"}, hook.events)
+}
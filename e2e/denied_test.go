@@ -0,0 +1,46 @@
+package e2e
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeniedFieldHook tests that the fields rejected by the allowed fields are reported
+func TestDeniedFieldHook(t *testing.T) {
+	var denied []bunql.DeniedField
+	policy := bunql.Policy{
+		FilterFields: []string{"age"},
+		SortFields:   []string{"age"},
+		Fields:       map[string]dto.FieldConfig{"email": {Sensitive: true}},
+		DeniedFieldHook: func(ctx context.Context, field bunql.DeniedField) {
+			denied = append(denied, field)
+		},
+	}
+
+	params := url.Values{
+		"filter": {`{"filters": [{"field": "age", "operator": "gt", "value": 20}, {"field": "email", "operator": "eq", "value": "jane@example.com"}],
+			"groups": [{"filters": [{"field": "city", "operator": "eq", "value": "Paris"}]}]}`},
+		"sort": {"last_name:asc"},
+	}
+	r := httptest.NewRequest("GET", "/users?"+params.Encode(), nil)
+	r = r.WithContext(bunql.WithIdentity(r.Context(), "api-key-1"))
+
+	// The filters are rejected before the sort is parsed
+	_, err := bunql.ParseFromRequestWithPolicy(r, policy)
+	require.EqualError(t, err, "filter field 'email' is not allowed")
+	require.Equal(t, []bunql.DeniedField{
+		{Field: "email", Usage: "filter", Caller: "api-key-1", Operator: "eq", Value: bunql.Redacted},
+		{Field: "city", Usage: "filter", Caller: "api-key-1", Operator: "eq", Value: "Paris"},
+	}, denied)
+
+	denied = nil
+	_, err = bunql.ParseFromRequestWithPolicy(httptest.NewRequest("GET", "/users?sort=last_name:asc,age:desc", nil), policy)
+	require.EqualError(t, err, "sort field 'last_name' is not allowed")
+	require.Equal(t, []bunql.DeniedField{{Field: "last_name", Usage: "sort"}}, denied)
+}
@@ -0,0 +1,63 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDescribe tests the human-readable descriptions of queries
+func TestDescribe(t *testing.T) {
+	filterJSON := `{
+		"logic": "and",
+		"filters": [{"field": "age", "operator": "gt", "value": 30}],
+		"groups": [{"logic": "or", "filters": [
+			{"field": "name", "operator": "like", "value": "J"},
+			{"field": "age", "operator": "between", "value": [50, 60]}
+		]}]
+	}`
+	sortJSON := `[{"field": "last_name", "dir": "asc"}]`
+
+	// Test 1: Filters, sort and page
+	t.Run("Full query", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams(filterJSON, sortJSON, 2, 10)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		require.Equal(t, "age > 30 AND (name contains 'J' OR age between 50 and 60), sorted by last_name asc, page 2 of size 10",
+			ql.Describe())
+	})
+
+	// Test 2: Sensitive values and list values
+	t.Run("Redacted", func(t *testing.T) {
+		ql := bunql.New().
+			WithFieldConfig("email", dto.FieldConfig{Sensitive: true}).
+			WithFilters(dto.FilterGroup{Filters: []dto.Filter{
+				{Field: "email", Operator: "eq", Value: "jane@example.com"},
+				{Field: "id", Operator: "in", Value: []int{1, 2}},
+				{Field: "deleted_at", Operator: "isnull"},
+			}})
+
+		require.Equal(t, "email = [REDACTED] AND id in (1, 2) AND deleted_at is empty", ql.Describe())
+	})
+
+	// Test 3: Localized phrases
+	t.Run("Localized", func(t *testing.T) {
+		phrases := bunql.EnglishPhrases
+		phrases.And = "ET"
+		phrases.Operators = map[string]string{"gt": "{field} supérieur à {value}"}
+		phrases.SortedBy = "trié par {sort}"
+		phrases.Page = "page {page} ({size} par page)"
+
+		group := dto.FilterGroup{Filters: []dto.Filter{
+			{Field: "age", Operator: "gt", Value: 30},
+			{Field: "name", Operator: "eq", Value: "J"},
+		}}
+		sort := []dto.SortField{{Field: "age", Direction: "desc"}}
+
+		require.Equal(t, "age supérieur à 30 ET name eq 'J', trié par age desc, page 1 (10 par page)",
+			bunql.DescribeWithPhrases(group, sort, &dto.Pagination{PageSize: 10}, phrases))
+		require.Empty(t, bunql.Describe(dto.FilterGroup{}, nil, nil))
+	})
+}
@@ -0,0 +1,120 @@
+//go:build containers
+
+package dialects
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/microsoft/go-mssqldb"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mssql"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mssqldialect"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// backend starts a database and returns a connection to it
+type backend struct {
+	name  string
+	start func(t *testing.T) *bun.DB
+}
+
+// backends are the databases the matrix runs on; SQLite needs no container and always runs
+var backends = []backend{
+	{name: "sqlite", start: startSQLite},
+	{name: "postgres", start: startPostgres},
+	{name: "mysql", start: startMySQL},
+	{name: "mssql", start: startMSSQL},
+}
+
+// startSQLite opens an in-memory SQLite database
+func startSQLite(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+	return closeOnCleanup(t, bun.NewDB(sqldb, sqlitedialect.New()))
+}
+
+// startPostgres starts a Postgres container
+func startPostgres(t *testing.T) *bun.DB {
+	requireDocker(t)
+	ctx := context.Background()
+
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		postgres.WithDatabase("bunql"),
+		testcontainers.WithWaitStrategy(wait.ForLog("database system is ready to accept connections").
+			WithOccurrence(2).WithStartupTimeout(time.Minute)),
+	)
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn)))
+	return closeOnCleanup(t, bun.NewDB(sqldb, pgdialect.New()))
+}
+
+// startMySQL starts a MySQL container
+func startMySQL(t *testing.T) *bun.DB {
+	requireDocker(t)
+	ctx := context.Background()
+
+	container, err := mysql.Run(ctx, "mysql:8.0", mysql.WithDatabase("bunql"))
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	dsn, err := container.ConnectionString(ctx, "parseTime=true")
+	require.NoError(t, err)
+	sqldb, err := sql.Open("mysql", dsn)
+	require.NoError(t, err)
+	return closeOnCleanup(t, bun.NewDB(sqldb, mysqldialect.New()))
+}
+
+// startMSSQL starts a SQL Server container
+func startMSSQL(t *testing.T) *bun.DB {
+	requireDocker(t)
+	ctx := context.Background()
+
+	container, err := mssql.Run(ctx, "mcr.microsoft.com/mssql/server:2022-CU14-ubuntu-22.04",
+		mssql.WithAcceptEULA(),
+		mssql.WithPassword("BunQL-Passw0rd"),
+	)
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	dsn, err := container.ConnectionString(ctx)
+	require.NoError(t, err)
+	sqldb, err := sql.Open("sqlserver", dsn)
+	require.NoError(t, err)
+	return closeOnCleanup(t, bun.NewDB(sqldb, mssqldialect.New()))
+}
+
+// requireDocker skips the test when Docker is not available
+// The provider lookup panics when no Docker host is found at all, which also means skipping
+func requireDocker(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("Docker is not available: %v", r)
+		}
+	}()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+}
+
+// closeOnCleanup closes the database at the end of the test
+func closeOnCleanup(t *testing.T, db *bun.DB) *bun.DB {
+	t.Cleanup(func() { db.Close() })
+	return db
+}
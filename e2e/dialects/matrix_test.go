@@ -0,0 +1,216 @@
+//go:build containers
+
+package dialects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// Product is the model the matrix runs on
+type Product struct {
+	bun.BaseModel `bun:"table:products"`
+
+	ID         int64     `bun:"id,pk,autoincrement"`
+	Name       string    `bun:"name"`
+	Category   string    `bun:"category"`
+	Price      int       `bun:"price"`
+	Note       *string   `bun:"note"`
+	InStock    bool      `bun:"in_stock"`
+	ReleasedAt time.Time `bun:"released_at"`
+}
+
+// seedProducts creates the products table with 8 rows, released at 10:30 UTC so that date filters must ignore the time
+func seedProducts(t *testing.T, ctx context.Context, db *bun.DB) {
+	note := func(s string) *string { return &s }
+	day := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 10, 30, 0, 0, time.UTC) }
+
+	products := []Product{
+		{Name: "Apple", InStock: true, Category: "fruit", Price: 3, Note: note("fresh"), ReleasedAt: day(1, 10)},
+		{Name: "Banana", Category: "fruit", Price: 1, ReleasedAt: day(1, 15)},
+		{Name: "Carrot", InStock: true, Category: "veg", Price: 2, Note: note("organic"), ReleasedAt: day(2, 1)},
+		{Name: "Daikon", Category: "veg", Price: 5, ReleasedAt: day(2, 20)},
+		{Name: "Eggplant", InStock: true, Category: "veg", Price: 4, Note: note("seasonal"), ReleasedAt: day(3, 5)},
+		{Name: "Fig", InStock: true, Category: "fruit", Price: 6, ReleasedAt: day(3, 30)},
+		{Name: "Grape", Category: "fruit", Price: 2, Note: note("seedless"), ReleasedAt: day(4, 12)},
+		{Name: "Hazelnut", Category: "nut", Price: 7, ReleasedAt: day(5, 1)},
+	}
+
+	require.NoError(t, db.ResetModel(ctx, (*Product)(nil)))
+	_, err := db.NewInsert().Model(&products).Exec(ctx)
+	require.NoError(t, err)
+}
+
+// filterCases are the filters of the matrix with the IDs of the products they match
+var filterCases = []struct {
+	name       string
+	filterJSON string
+	expected   []int64
+	skip       []string // Backends the operator is not supported on
+}{
+	{name: "eq", filterJSON: `{"filters": [{"field": "category", "operator": "eq", "value": "fruit"}]}`, expected: []int64{1, 2, 6, 7}},
+	{name: "neq", filterJSON: `{"filters": [{"field": "category", "operator": "neq", "value": "fruit"}]}`, expected: []int64{3, 4, 5, 8}},
+	{name: "gt", filterJSON: `{"filters": [{"field": "price", "operator": "gt", "value": 4}]}`, expected: []int64{4, 6, 8}},
+	{name: "gte", filterJSON: `{"filters": [{"field": "price", "operator": "gte", "value": 5}]}`, expected: []int64{4, 6, 8}},
+	{name: "lt", filterJSON: `{"filters": [{"field": "price", "operator": "lt", "value": 2}]}`, expected: []int64{2}},
+	{name: "lte", filterJSON: `{"filters": [{"field": "price", "operator": "lte", "value": 2}]}`, expected: []int64{2, 3, 7}},
+	{name: "like", filterJSON: `{"filters": [{"field": "name", "operator": "like", "value": "an"}]}`, expected: []int64{2, 5}},
+	{name: "ilike", filterJSON: `{"filters": [{"field": "name", "operator": "ilike", "value": "AN"}]}`, expected: []int64{2, 5}},
+	{name: "in", filterJSON: `{"filters": [{"field": "category", "operator": "in", "value": ["veg", "nut"]}]}`, expected: []int64{3, 4, 5, 8}},
+	{name: "notin", filterJSON: `{"filters": [{"field": "category", "operator": "notin", "value": ["fruit"]}]}`, expected: []int64{3, 4, 5, 8}},
+	{name: "isnull", filterJSON: `{"filters": [{"field": "note", "operator": "isnull"}]}`, expected: []int64{2, 4, 6, 8}},
+	{name: "isnotnull", filterJSON: `{"filters": [{"field": "note", "operator": "isnotnull"}]}`, expected: []int64{1, 3, 5, 7}},
+	{name: "istrue", filterJSON: `{"filters": [{"field": "in_stock", "operator": "istrue"}]}`, expected: []int64{1, 3, 5, 6}},
+	{name: "isfalse", filterJSON: `{"filters": [{"field": "in_stock", "operator": "isfalse"}]}`, expected: []int64{2, 4, 7, 8}},
+	{name: "anyeq", filterJSON: `{"filters": [{"field": "category", "operator": "anyeq", "value": ["veg", "nut"]}]}`, expected: []int64{3, 4, 5, 8}},
+	{name: "allgt", filterJSON: `{"filters": [{"field": "price", "operator": "allgt", "value": [2, 4]}]}`, expected: []int64{4, 6, 8}},
+	{name: "between", filterJSON: `{"filters": [{"field": "price", "operator": "between", "value": [2, 4]}]}`, expected: []int64{1, 3, 5, 7}},
+	{name: "date eq", filterJSON: `{"filters": [{"field": "released_at", "operator": "eq", "value": "2024-02-01"}]}`, expected: []int64{3}},
+	{name: "date gt", filterJSON: `{"filters": [{"field": "released_at", "operator": "gt", "value": "2024-03-05"}]}`, expected: []int64{6, 7, 8}},
+	{name: "date between", filterJSON: `{"filters": [{"field": "released_at", "operator": "between", "value": ["2024-01-15", "2024-02-20"]}]}`, expected: []int64{2, 3, 4}},
+	{name: "distinctfrom", filterJSON: `{"filters": [{"field": "note", "operator": "distinctfrom", "value": "fresh"}]}`, expected: []int64{2, 3, 4, 5, 6, 7, 8}},
+	{name: "notdistinctfrom", filterJSON: `{"filters": [{"field": "note", "operator": "notdistinctfrom", "value": "organic"}]}`, expected: []int64{3}},
+	{name: "regex", filterJSON: `{"filters": [{"field": "name", "operator": "regex", "value": "^[A-C]"}]}`, expected: []int64{1, 2, 3}, skip: []string{"sqlite", "mssql"}},
+	{
+		name: "nested groups",
+		filterJSON: `{"logic": "and", "filters": [{"field": "category", "operator": "eq", "value": "fruit"}],
+			"groups": [{"logic": "or", "filters": [{"field": "price", "operator": "lt", "value": 2}, {"field": "price", "operator": "gt", "value": 5}]}]}`,
+		expected: []int64{2, 6},
+	},
+}
+
+// TestDialects runs the operator, sort and pagination matrix on every backend
+// The container backends need Docker and are skipped without it: go test -tags containers ./e2e/dialects
+func TestDialects(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			db := b.start(t)
+			ctx := context.Background()
+			seedProducts(t, ctx, db)
+
+			for _, tc := range filterCases {
+				t.Run(tc.name, func(t *testing.T) {
+					for _, skip := range tc.skip {
+						if skip == b.name {
+							t.Skipf("%s is not supported on %s", tc.name, b.name)
+						}
+					}
+
+					ql, err := bunql.ParseFromParams(tc.filterJSON, "id:asc", 0, 0)
+					require.NoError(t, err)
+
+					var products []Product
+					require.NoError(t, ql.Apply(ctx, db.NewSelect().Model(&products)).Scan(ctx))
+					require.Equal(t, tc.expected, productIDs(products))
+				})
+			}
+
+			t.Run("sort", func(t *testing.T) {
+				ql, err := bunql.ParseFromParams("", "price:desc,id:asc", 0, 0)
+				require.NoError(t, err)
+
+				var products []Product
+				require.NoError(t, ql.Apply(ctx, db.NewSelect().Model(&products)).Scan(ctx))
+				require.Equal(t, []int64{8, 6, 4, 5, 1, 3, 7, 2}, productIDs(products))
+			})
+
+			t.Run("pagination", func(t *testing.T) {
+				ql, err := bunql.ParseFromParams(`{"filters": [{"field": "price", "operator": "gte", "value": 2}]}`, "id:asc", 2, 3)
+				require.NoError(t, err)
+
+				products, total, err := bunql.ExecutePage[Product](ctx, ql, db.NewSelect().Model((*Product)(nil)))
+				require.NoError(t, err)
+				require.Equal(t, []int64{5, 6, 7}, productIDs(products))
+				require.Equal(t, 7, total)
+			})
+
+			t.Run("percentiles", func(t *testing.T) {
+				ql, err := bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "fruit"}]}`, "", 0, 0)
+				require.NoError(t, err)
+
+				// The fruits are priced 1, 2, 3 and 6
+				values, err := ql.Percentiles(ctx, db.NewSelect().Model((*Product)(nil)), "price", 0.5, 0.9)
+				require.NoError(t, err)
+				require.InDeltaSlice(t, []float64{2.5, 5.1}, values, 1e-9)
+			})
+
+			t.Run("time buckets", func(t *testing.T) {
+				ql, err := bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "veg"}]}`, "", 0, 0)
+				require.NoError(t, err)
+
+				// The vegetables were released on the 1st and 20th of February and the 5th of March
+				series, err := ql.CountByTime(ctx, db.NewSelect().Model((*Product)(nil)), bunql.TimeBucket{Field: "released_at", Interval: bunql.BucketMonth})
+				require.NoError(t, err)
+				require.Len(t, series.Buckets, 2)
+				require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), series.Buckets[0].Start)
+				require.Equal(t, []int{2, 1}, []int{series.Buckets[0].Count, series.Buckets[1].Count})
+
+				series, err = ql.CountByTime(ctx, db.NewSelect().Model((*Product)(nil)), bunql.TimeBucket{Field: "released_at", Interval: bunql.BucketWeek})
+				require.NoError(t, err)
+				filled, err := series.Fill(time.Time{}, time.Time{})
+				require.NoError(t, err)
+				require.Equal(t, []int{1, 0, 0, 1, 0, 1}, fillCounts(filled))
+			})
+
+			t.Run("union", func(t *testing.T) {
+				ql, err := bunql.ParseFromParams(`{"filters": [{"field": "price", "operator": "gte", "value": 5}]}`, "price:desc,kind:asc", 1, 3)
+				require.NoError(t, err)
+
+				type row struct {
+					Kind  string `bun:"kind"`
+					Price int    `bun:"price"`
+				}
+				union, err := ql.Union(ctx,
+					db.NewSelect().Model((*Product)(nil)).ColumnExpr("'a' AS kind, price"),
+					db.NewSelect().Model((*Product)(nil)).ColumnExpr("'b' AS kind, price"),
+				)
+				require.NoError(t, err)
+				rows, total, err := bunql.ExecuteUnion[row](ctx, union)
+				require.NoError(t, err)
+				require.Equal(t, 6, total)
+				require.Equal(t, []row{{"a", 7}, {"b", 7}, {"a", 6}}, rows)
+			})
+
+			t.Run("cursor", func(t *testing.T) {
+				secret := []byte("secret")
+				ql, err := bunql.ParseFromParams("", "price:asc,id:asc", 0, 3)
+				require.NoError(t, err)
+
+				products, metadata, err := bunql.ExecuteCursorPage[Product](ctx, ql, db.NewSelect().Model((*Product)(nil)), secret)
+				require.NoError(t, err)
+				require.Equal(t, []int64{2, 3, 7}, productIDs(products))
+				require.NotNil(t, metadata.Next)
+
+				c, err := cursor.Decode(*metadata.Next, secret)
+				require.NoError(t, err)
+				products, _, err = bunql.ExecuteCursorPage[Product](ctx, ql.WithCursor(c), db.NewSelect().Model((*Product)(nil)), secret)
+				require.NoError(t, err)
+				require.Equal(t, []int64{1, 5, 4}, productIDs(products))
+			})
+		})
+	}
+}
+
+// productIDs returns the IDs of products, in order
+func productIDs(products []Product) []int64 {
+	ids := make([]int64, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// fillCounts returns the counts of zero-filled buckets, in order
+func fillCounts(buckets []bunql.Bucket) []int {
+	counts := make([]int, len(buckets))
+	for i, b := range buckets {
+		counts[i] = b.Count
+	}
+	return counts
+}
@@ -0,0 +1,121 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// sqlQueryHook records the SQL of the executed queries
+type sqlQueryHook struct {
+	queries []string
+}
+
+func (h *sqlQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *sqlQueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	h.queries = append(h.queries, event.Query)
+}
+
+// TestExport tests streaming filtered and sorted results as CSV and NDJSON
+func TestExport(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	filterJSON := `{
+		"logic": "and",
+		"filters": [
+			{"field": "category", "operator": "eq", "value": "b"}
+		]
+	}`
+	sortJSON := `[{"field": "price", "dir": "desc"}]`
+
+	ql, err := bunql.ParseFromParams(filterJSON, sortJSON, 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	// Test 1: CSV
+	t.Run("CSV", func(t *testing.T) {
+		query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+		var buf bytes.Buffer
+		err := bunql.ExportCSV(ctx, &buf, query, []string{"name", "price"})
+		require.NoError(t, err, "Export failed")
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err, "Invalid CSV output")
+		require.Equal(t, [][]string{
+			{"name", "price"},
+			{"Item6", "60"},
+			{"Item4", "40"},
+			{"Item2", "20"},
+		}, records)
+	})
+
+	// Test 2: NDJSON
+	t.Run("NDJSON", func(t *testing.T) {
+		query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+		var buf bytes.Buffer
+		err := bunql.ExportNDJSON(ctx, &buf, query, []string{"price", "name"})
+		require.NoError(t, err, "Export failed")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		require.Equal(t, `{"price":60,"name":"Item6"}`, lines[0])
+
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &row))
+		require.Equal(t, "Item2", row["name"])
+	})
+
+	// Test 3: Column not selected by the query
+	t.Run("Unknown column", func(t *testing.T) {
+		query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+		var buf bytes.Buffer
+		err := bunql.ExportCSV(ctx, &buf, query, []string{"name", "password"})
+		require.Error(t, err, "Should fail with a column that is not selected")
+		require.Contains(t, err.Error(), "export column 'password' is not selected by the query")
+	})
+
+	// Test 4: Only the exported columns are read
+	t.Run("Selected columns", func(t *testing.T) {
+		hook := &sqlQueryHook{}
+		hooked := bun.NewDB(db.DB, sqlitedialect.New())
+		hooked.AddQueryHook(hook)
+		query := ql.Apply(ctx, hooked.NewSelect().Model((*Item)(nil)))
+
+		var buf bytes.Buffer
+		require.NoError(t, bunql.ExportCSV(ctx, &buf, query, []string{"name"}), "Export failed")
+		require.Len(t, hook.queries, 1)
+		require.True(t, strings.HasPrefix(hook.queries[0], `SELECT "i"."name" FROM "items" AS "i"`), hook.queries[0])
+	})
+
+	// Test 5: Values read as formulas by spreadsheets are escaped
+	t.Run("Formulas", func(t *testing.T) {
+		_, err := db.NewInsert().Model(&Item{Name: "=HYPERLINK(\"http://example.com\")", Category: "c", Price: -5}).Exec(ctx)
+		require.NoError(t, err, "Failed to insert item")
+
+		query := db.NewSelect().Model((*Item)(nil)).Where("category = ?", "c")
+
+		var buf bytes.Buffer
+		require.NoError(t, bunql.ExportCSV(ctx, &buf, query, []string{"name", "price"}), "Export failed")
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err, "Invalid CSV output")
+		require.Equal(t, []string{"'=HYPERLINK(\"http://example.com\")", "-5"}, records[1])
+	})
+}
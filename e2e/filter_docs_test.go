@@ -0,0 +1,82 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilterDocs tests that the filters accepted by a policy are documented with examples the policy accepts
+func TestFilterDocs(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 4)
+
+	policy := bunql.Policy{
+		FilterFields: []string{"name", "category", "price"},
+		Fields: map[string]dto.FieldConfig{
+			"name":     {Type: dto.FieldString},
+			"category": {Operators: []string{"eq", "in"}},
+			"price":    {Type: dto.FieldNumber},
+		},
+	}
+
+	docs := bunql.NewWithPolicy(policy).FilterDocs()
+	require.Len(t, docs, 3)
+
+	operators := func(doc bunql.FilterDoc) []string {
+		var names []string
+		for _, op := range doc.Operators {
+			names = append(names, op.Operator)
+		}
+		return names
+	}
+
+	// Pattern operators are only documented on strings, and configured operators restrict the list
+	require.Equal(t, "name", docs[0].Field)
+	require.Equal(t, dto.FieldString, docs[0].Type)
+	require.Contains(t, operators(docs[0]), "like")
+	require.NotContains(t, operators(docs[0]), "nearby")
+	require.Equal(t, []string{"eq", "in"}, operators(docs[1]))
+	require.Equal(t, dto.FieldNumber, docs[2].Type)
+	require.NotContains(t, operators(docs[2]), "like")
+	require.Contains(t, operators(docs[2]), "between")
+
+	between := docs[2].Operators[0]
+	for _, op := range docs[2].Operators {
+		if op.Operator == "between" {
+			between = op
+		}
+	}
+	require.Equal(t, "Between two values, inclusive; a null bound leaves the range open", between.Description)
+	require.Equal(t, "a pair of values", between.Value)
+	require.JSONEq(t, `{"logic":"and","filters":[{"field":"price","operator":"between","value":[20,30]}]}`, between.Example)
+
+	// Every example is accepted by the policy and can be run
+	for _, doc := range docs {
+		for _, op := range doc.Operators {
+			request := httptest.NewRequest("GET", "/items?"+url.Values{"filter": {op.Example}}.Encode(), nil)
+			ql, err := bunql.ParseFromRequestWithPolicy(request, policy)
+			require.NoError(t, err, "Example %s is rejected", op.Example)
+			if op.Operator == "regex" {
+				continue
+			}
+			var items []Item
+			err = ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items)
+			require.NoError(t, err, "Example %s failed", op.Example)
+		}
+	}
+
+	// The docs are served as JSON
+	data, err := json.Marshal(docs[1])
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"operator":"in","description":"In a list of values","value":"a list of values"`)
+}
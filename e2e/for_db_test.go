@@ -0,0 +1,30 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/dialect"
+)
+
+// TestForDB tests that a BunQL created for a database generates SQL for its dialect
+func TestForDB(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	ql := bunql.ForDB(db)
+	require.Equal(t, dialect.SQLite, ql.FilterOptions.Dialect)
+
+	ql.WithFilters(dto.FilterGroup{Filters: []dto.Filter{{Field: "price", Operator: "gte", Value: 20}}})
+
+	var items []Item
+	err := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items)
+	require.NoError(t, err, "Query failed")
+	require.Len(t, items, 2)
+}
@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type Category struct {
+	bun.BaseModel `bun:"table:categories,alias:c"`
+
+	ID       int64  `bun:"id,pk"`
+	Name     string `bun:"name"`
+	ParentID *int64 `bun:"parent_id"`
+}
+
+// TestHierarchyFilters tests that the descendants and ancestors of a node of a tree are filtered with a recursive CTE
+func TestHierarchyFilters(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	require.NoError(t, db.ResetModel(ctx, (*Category)(nil)), "Failed to create table")
+
+	parent := func(id int64) *int64 { return &id }
+	categories := []Category{
+		{ID: 1, Name: "Electronics"},
+		{ID: 2, Name: "Computers", ParentID: parent(1)},
+		{ID: 3, Name: "Laptops", ParentID: parent(2)},
+		{ID: 4, Name: "Gaming laptops", ParentID: parent(3)},
+		{ID: 5, Name: "Phones", ParentID: parent(1)},
+		{ID: 6, Name: "Books"},
+		{ID: 7, Name: "Novels", ParentID: parent(6)},
+	}
+	_, err := db.NewInsert().Model(&categories).Exec(ctx)
+	require.NoError(t, err, "Failed to insert categories")
+
+	tests := []struct {
+		name       string
+		filterJSON string
+		expected   []string
+	}{
+		{
+			name:       "Descendants of a root",
+			filterJSON: `{"filters": [{"field": "id", "operator": "descendantof", "value": 1}]}`,
+			expected:   []string{"Computers", "Laptops", "Gaming laptops", "Phones"},
+		},
+		{
+			name:       "Descendants of an inner node",
+			filterJSON: `{"filters": [{"field": "id", "operator": "descendantof", "value": 2}]}`,
+			expected:   []string{"Laptops", "Gaming laptops"},
+		},
+		{
+			name:       "Ancestors of a leaf",
+			filterJSON: `{"filters": [{"field": "id", "operator": "ancestorof", "value": 4}]}`,
+			expected:   []string{"Electronics", "Computers", "Laptops"},
+		},
+		{
+			name:       "Subtree combined with other filters",
+			filterJSON: `{"filters": [{"field": "id", "operator": "descendantof", "value": 1}, {"field": "name", "operator": "like", "value": "aptop"}]}`,
+			expected:   []string{"Laptops", "Gaming laptops"},
+		},
+		{
+			name:       "Leaf has no descendants",
+			filterJSON: `{"filters": [{"field": "id", "operator": "descendantof", "value": 7}]}`,
+			expected:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ql, err := bunql.ParseFromParams(tt.filterJSON, "id:asc", 0, 0)
+			require.NoError(t, err, "Failed to parse parameters")
+
+			var result []Category
+			err = ql.Apply(ctx, db.NewSelect().Model(&result)).Scan(ctx)
+			require.NoError(t, err, "Query failed")
+
+			var names []string
+			for _, c := range result {
+				names = append(names, c.Name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+
+	// A parent column with another name is configured on the field
+	_, err = db.ExecContext(ctx, `ALTER TABLE categories RENAME COLUMN parent_id TO parent_category_id`)
+	require.NoError(t, err, "Failed to rename column")
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "id", "operator": "ancestorof", "value": 3}]}`, "id:asc", 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+	ql.WithFieldConfig("id", dto.FieldConfig{Parent: "parent_category_id"})
+
+	var names []string
+	err = ql.Apply(ctx, db.NewSelect().Model((*Category)(nil)).Column("name")).Scan(ctx, &names)
+	require.NoError(t, err, "Query failed")
+	require.Equal(t, []string{"Electronics", "Computers"}, names)
+}
@@ -0,0 +1,53 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/dialect"
+)
+
+// TestHints tests that statement hints wrap the query on their dialect only
+func TestHints(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	hint := bunql.Hint{
+		UseIndex: []string{"items_category_idx"},
+		Plan:     "IndexScan(items items_category_idx)",
+		Option:   "RECOMPILE",
+	}
+
+	ql := bunql.New().WithHint(hint)
+	query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+	hinted := func() string {
+		b, err := ql.Hinted(query).AppendQuery(db.Formatter(), nil)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	// SQLite has no statement hints, and bun only adds index hints on MySQL
+	require.Equal(t, `SELECT "i"."id", "i"."name", "i"."category", "i"."price" FROM "items" AS "i"`,
+		hinted())
+
+	ql.WithDialect(dialect.PG)
+	require.Equal(t, `/*+ IndexScan(items items_category_idx) */ SELECT "i"."id", "i"."name", "i"."category", "i"."price" FROM "items" AS "i"`,
+		hinted())
+
+	ql.WithDialect(dialect.MSSQL)
+	require.Equal(t, `SELECT "i"."id", "i"."name", "i"."category", "i"."price" FROM "items" AS "i" OPTION (RECOMPILE)`,
+		hinted())
+
+	// ExecutePage runs the hinted statement; SQLite ignores the comment
+	ql.WithDialect(dialect.PG)
+	items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err, "Query failed")
+	require.Len(t, items, 3)
+	require.Equal(t, 3, total)
+}
@@ -0,0 +1,129 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type Item struct {
+	bun.BaseModel `bun:"table:items,alias:i"`
+
+	ID       int64  `bun:"id,pk,autoincrement"`
+	Name     string `bun:"name"`
+	Category string `bun:"category"`
+	Price    int    `bun:"price"`
+}
+
+// seedItems recreates the items table with count items, alternating between the "a" and "b" categories
+func seedItems(t *testing.T, ctx context.Context, count int) {
+	_, err := db.ExecContext(ctx, `DROP TABLE IF EXISTS items`)
+	require.NoError(t, err, "Failed to drop table")
+
+	_, err = db.NewCreateTable().Model((*Item)(nil)).Exec(ctx)
+	require.NoError(t, err, "Failed to create table")
+
+	if count == 0 {
+		return
+	}
+
+	items := make([]Item, count)
+	for i := range items {
+		category := "a"
+		if i%2 == 1 {
+			category = "b"
+		}
+		items[i] = Item{
+			Name:     fmt.Sprintf("Item%d", i+1),
+			Category: category,
+			Price:    (i + 1) * 10,
+		}
+	}
+
+	_, err = db.NewInsert().Model(&items).Exec(ctx)
+	require.NoError(t, err, "Failed to insert data")
+}
+
+// TestIterate tests walking a filtered dataset page by page
+func TestIterate(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 25)
+
+	filterJSON := `{
+		"logic": "and",
+		"filters": [
+			{"field": "category", "operator": "eq", "value": "a"}
+		]
+	}`
+
+	// Test 1: Offset pagination
+	t.Run("Offset", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams(filterJSON, `[{"field": "price", "dir": "desc"}]`, 1, 5)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		var prices []int
+		for item, err := range bunql.Iterate[Item](ctx, db, ql) {
+			require.NoError(t, err, "Iteration failed")
+			require.Equal(t, "a", item.Category)
+			prices = append(prices, item.Price)
+		}
+
+		require.Len(t, prices, 13, "Expected every item of category a")
+		require.IsNonIncreasing(t, prices)
+	})
+
+	// Test 2: Keyset pagination
+	t.Run("Keyset", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams(filterJSON, "", 1, 4)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		var ids []int64
+		for item, err := range bunql.IterateKeyset[Item](ctx, db, ql, "id") {
+			require.NoError(t, err, "Iteration failed")
+			require.Equal(t, "a", item.Category)
+			ids = append(ids, item.ID)
+		}
+
+		require.Len(t, ids, 13, "Expected every item of category a")
+		require.IsIncreasing(t, ids)
+	})
+
+	// Test 3: Stopping early
+	t.Run("Break", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "", 1, 10)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		count := 0
+		for _, err := range bunql.Iterate[Item](ctx, db, ql) {
+			require.NoError(t, err, "Iteration failed")
+			count++
+			if count == 3 {
+				break
+			}
+		}
+		require.Equal(t, 3, count)
+	})
+
+	// Test 4: Keyset over another sort field
+	t.Run("Keyset with other sort", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", `[{"field": "category", "dir": "desc"}, {"field": "price", "dir": "asc"}]`, 1, 4)
+		require.NoError(t, err, "Failed to parse parameters")
+
+		var names []string
+		for item, err := range bunql.IterateKeyset[Item](ctx, db, ql, "id") {
+			require.NoError(t, err, "Iteration failed")
+			names = append(names, item.Name)
+		}
+
+		require.Len(t, names, 25, "Expected every item")
+		require.Equal(t, []string{"Item2", "Item4", "Item6"}, names[:3])
+		require.Equal(t, "Item25", names[24])
+	})
+}
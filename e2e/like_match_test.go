@@ -0,0 +1,36 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLikeMatch tests that like matches according to the match mode of the field or of the request
+func TestLikeMatch(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 12)
+
+	count := func(ql *bunql.BunQL, value string) int {
+		ql.WithFilters(dto.FilterGroup{Logic: "and", Filters: []dto.Filter{{Field: "name", Operator: "like", Value: value}}})
+		var items []Item
+		require.NoError(t, ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items))
+		return len(items)
+	}
+
+	require.Equal(t, 4, count(bunql.New(), "1"))
+	require.Equal(t, 0, count(bunql.New().WithLikeMatch(dto.MatchPrefix), "1"))
+	require.Equal(t, 4, count(bunql.New().WithLikeMatch(dto.MatchPrefix), "Item1"))
+	require.Equal(t, 2, count(bunql.New().WithLikeMatch(dto.MatchSuffix), "1"))
+	require.Equal(t, 1, count(bunql.New().WithLikeMatch(dto.MatchExact), "Item1"))
+
+	// The mode of the field takes precedence over the mode of the request
+	ql := bunql.New().WithLikeMatch(dto.MatchSuffix).WithFieldConfig("name", dto.FieldConfig{Match: dto.MatchPrefix})
+	require.Equal(t, 4, count(ql, "Item1"))
+}
@@ -0,0 +1,78 @@
+package e2e
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// TestExecutionLimiter tests that the executions through a BunQL beyond its limit wait for a slot
+func TestExecutionLimiter(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 3)
+
+	// The middleware holds the slot of the first execution until it is released
+	entered, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	limiter := bunql.NewExecutionLimiter(1)
+	template := bunql.New().WithExecutionLimiter(limiter).WithMiddleware(func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			if !bunql.IsCount(ctx) {
+				once.Do(func() {
+					close(entered)
+					<-release
+				})
+			}
+			return next(ctx, ql, query)
+		}
+	})
+
+	done := make(chan error)
+	go func() {
+		_, _, err := bunql.ExecutePage[Item](ctx, template.Clone(), db.NewSelect().Model((*Item)(nil)))
+		done <- err
+	}()
+	<-entered
+	require.Equal(t, 1, limiter.InFlight())
+
+	// A clone of the template waits for the slot until its context is done
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, _, err := bunql.ExecutePage[Item](waitCtx, template.Clone(), db.NewSelect().Model((*Item)(nil)))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, "failed to wait for an execution slot: context deadline exceeded")
+
+	// It gets the slot once the first execution is done
+	second := make(chan int)
+	go func() {
+		_, total, _ := bunql.ExecutePage[Item](ctx, template.Clone(), db.NewSelect().Model((*Item)(nil)))
+		second <- total
+	}()
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, 3, <-second)
+	require.Equal(t, 0, limiter.InFlight())
+
+	// Queries executed by the caller hold a slot through Do
+	mainQuery, countQuery := bunql.New().ApplyWithCount(ctx, db.NewSelect().Model((*Item)(nil)))
+	err = limiter.Do(ctx, func(ctx context.Context) error {
+		require.Equal(t, 1, limiter.InFlight())
+		items, total, err := bunql.ExecuteWithCount[Item](ctx, mainQuery, countQuery)
+		require.Len(t, items, 3)
+		require.Equal(t, 3, total)
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, limiter.InFlight())
+
+	var nilLimiter *bunql.ExecutionLimiter
+	require.NoError(t, nilLimiter.Do(ctx, func(context.Context) error { return nil }))
+}
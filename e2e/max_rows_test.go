@@ -0,0 +1,67 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaxRows tests that the row cap applies with and without pagination
+func TestMaxRows(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	// Test 1: No pagination
+	t.Run("Without pagination", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "", 0, 0)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithMaxRows(3)
+
+		var users []User
+		err = ql.Apply(ctx, db.NewSelect().Model((*User)(nil))).Scan(ctx, &users)
+		require.NoError(t, err, "Query execution failed")
+		require.Len(t, users, 3)
+	})
+
+	// Test 2: Page size above the cap
+	t.Run("Page size above the cap", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "", 1, 8)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithMaxRows(3)
+
+		users, totalCount, err := bunql.ExecutePage[User](ctx, ql, db.NewSelect().Model((*User)(nil)))
+		require.NoError(t, err, "Query execution failed")
+		require.Len(t, users, 3)
+		require.Equal(t, 10, totalCount, "The count is not capped")
+	})
+
+	// Test 3: Page size below the cap
+	t.Run("Page size below the cap", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "", 1, 2)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithMaxRows(3)
+
+		var users []User
+		err = ql.Apply(ctx, db.NewSelect().Model((*User)(nil))).Scan(ctx, &users)
+		require.NoError(t, err, "Query execution failed")
+		require.Len(t, users, 2)
+	})
+
+	// Test 4: Cursor pagination still detects the next page
+	t.Run("Cursor pagination", func(t *testing.T) {
+		ql := bunql.New().
+			WithSort([]dto.SortField{{Field: "id", Direction: "asc"}}).
+			WithPagination(&dto.Pagination{PageSize: 5}).
+			WithMaxRows(3)
+
+		users, metadata, err := bunql.ExecuteCursorPage[User](ctx, ql, db.NewSelect().Model((*User)(nil)), []byte("secret"))
+		require.NoError(t, err, "Query execution failed")
+		require.Len(t, users, 3)
+		require.NotNil(t, metadata.Next, "There are rows beyond the cap")
+	})
+}
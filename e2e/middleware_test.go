@@ -0,0 +1,72 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// TestMiddleware tests composing middleware around Apply
+func TestMiddleware(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	// Restricts every query, including the count query, to a subset of the rows
+	scope := func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			return next(ctx, ql, query.Where("id <= ?", 6))
+		}
+	}
+
+	// Rewrites the public "years" field to the "age" column on a copy of the BunQL
+	rewrite := func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			rewritten := *ql
+			rewritten.Filters.Filters = make([]dto.Filter, len(ql.Filters.Filters))
+			for i, f := range ql.Filters.Filters {
+				if f.Field == "years" {
+					f.Field = "age"
+				}
+				rewritten.Filters.Filters[i] = f
+			}
+			return next(ctx, &rewritten, query)
+		}
+	}
+
+	// Records the order the middleware runs in
+	var calls []string
+	record := func(name string) bunql.Middleware {
+		return func(next bunql.Applier) bunql.Applier {
+			return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+				calls = append(calls, "before "+name)
+				query = next(ctx, ql, query)
+				calls = append(calls, "after "+name)
+				return query
+			}
+		}
+	}
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "years", "operator": "gte", "value": 0}]}`, "", 1, 4)
+	require.NoError(t, err, "Failed to parse parameters")
+	ql.WithMiddleware(record("outer"), scope, rewrite, record("inner"))
+
+	users, totalCount, err := bunql.ExecutePage[User](ctx, ql, db.NewSelect().Model((*User)(nil)))
+	require.NoError(t, err, "Query execution failed")
+	require.Len(t, users, 4)
+	require.Equal(t, 6, totalCount, "The count query goes through the middleware too")
+	for _, user := range users {
+		require.LessOrEqual(t, user.ID, int64(6))
+	}
+
+	require.Equal(t, []string{
+		"before outer", "before inner", "after inner", "after outer",
+		"before outer", "before inner", "after inner", "after outer",
+	}, calls)
+	require.Equal(t, "years", ql.Filters.Filters[0].Field, "The original BunQL is unchanged")
+}
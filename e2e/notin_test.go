@@ -2,12 +2,11 @@ package e2e
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/fxnoob/bunql"
-	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
 	"github.com/stretchr/testify/require"
 )
 
@@ -63,28 +62,10 @@ func TestArrayNotInOperator(t *testing.T) {
 	// This is similar to the format in the issue description
 	filterJSON := `[{"field": "id", "operator": "notin", "value": [1,2]}]`
 
-	// Parse the filter JSON
-	// Note: The filter JSON format is an array of filters, not a filter group
-	// We need to convert it to a filter group
-	var filters []map[string]interface{}
-	err := json.Unmarshal([]byte(filterJSON), &filters)
+	// Parse the filter JSON, a bare array of filters being read as an AND group
+	filterGroup, err := filter.ParseFilters(filterJSON)
 	require.NoError(t, err, "Failed to parse filter JSON")
 
-	// Convert to a filter group
-	filterGroup := dto.FilterGroup{
-		Logic:   "and",
-		Filters: make([]dto.Filter, 0),
-	}
-
-	for _, f := range filters {
-		filter := dto.Filter{
-			Field:    f["field"].(string),
-			Operator: f["operator"].(string),
-			Value:    f["value"],
-		}
-		filterGroup.Filters = append(filterGroup.Filters, filter)
-	}
-
 	// Create a BunQL instance with the filter
 	ql := bunql.New().WithFilters(filterGroup)
 
@@ -0,0 +1,42 @@
+package e2e
+
+import (
+	"context"
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOrderBy(t *testing.T) {
+	db := GetDB()
+	ctx := context.Background()
+
+	ql := bunql.NewWithAllowedFields(nil, []string{"age", "last_name", "id"})
+	require.NoError(t, ql.OrderBy("age", dto.Desc))
+	require.NoError(t, ql.OrderByMany(dto.SortField{Field: "last_name"}, dto.SortField{Field: "id", Direction: "ASC"}))
+	assert.Equal(t, []dto.SortField{
+		{Field: "age", Direction: dto.Desc},
+		{Field: "last_name", Direction: dto.Asc},
+		{Field: "id", Direction: dto.Asc},
+	}, ql.Sort)
+
+	query := ql.Apply(ctx, db.NewSelect().Model((*User)(nil)))
+	assert.Contains(t, query.String(), "ORDER BY age DESC, last_name ASC, id ASC")
+
+	// Invalid fields are rejected without changing the sort
+	assert.EqualError(t, ql.OrderBy("email", dto.Asc), "sort field 'email' is not allowed")
+	assert.EqualError(t, ql.OrderBy("age; DROP TABLE users", dto.Asc), "invalid sort field 'age; DROP TABLE users'")
+	assert.EqualError(t, ql.OrderByMany(dto.SortField{Field: "id"}, dto.SortField{Field: "age", Direction: "sideways"}),
+		"invalid sort direction 'sideways' for field 'age'")
+	assert.EqualError(t, ql.OrderByMany(dto.SortField{Field: "age", NullsAs: []int{0}}),
+		"nullsAs of sort field 'age' must be a string, a number or a boolean")
+	assert.Len(t, ql.Sort, 3)
+
+	// A copy made before appending keeps its sort
+	sort := ql.Sort
+	require.NoError(t, ql.OrderBy("id", dto.Desc))
+	assert.Len(t, sort, 3)
+	assert.Len(t, ql.Sort, 4)
+}
@@ -0,0 +1,51 @@
+package e2e
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPaginationBounds tests that out of range pagination is rejected, or clamped when configured
+func TestPaginationBounds(t *testing.T) {
+	// Test 1: Negative values passed directly
+	t.Run("Negative parameters", func(t *testing.T) {
+		_, err := bunql.ParseFromParams("", "", -3, 10)
+		require.ErrorIs(t, err, bunql.ErrInvalidPage)
+
+		_, err = bunql.ParseFromParams("", "", 1, -1)
+		require.ErrorIs(t, err, bunql.ErrInvalidPageSize)
+		require.EqualError(t, err, "invalid page size -1: page size must be a positive integer")
+	})
+
+	// Test 2: Zero, negative and non-numeric query parameters
+	t.Run("Request parameters", func(t *testing.T) {
+		for _, target := range []string{"/users?page=0", "/users?page=-3", "/users?page=x"} {
+			_, err := bunql.ParseFromRequest(httptest.NewRequest("GET", target, nil))
+			require.ErrorIs(t, err, bunql.ErrInvalidPage, target)
+		}
+		for _, target := range []string{"/users?pageSize=0", "/users?pageSize=-1", "/users?pageSize=ten"} {
+			_, err := bunql.ParseFromRequest(httptest.NewRequest("GET", target, nil))
+			require.ErrorIs(t, err, bunql.ErrInvalidPageSize, target)
+		}
+	})
+
+	// Test 3: Clamping per policy
+	t.Run("Clamped", func(t *testing.T) {
+		policy := bunql.Policy{MaxPageSize: 20, ClampPagination: true}
+
+		ql, err := bunql.ParseFromRequestWithPolicy(httptest.NewRequest("GET", "/users?page=-3&pageSize=-1", nil), policy)
+		require.NoError(t, err)
+		require.Nil(t, ql.Pagination, "Out of range values are treated as not provided")
+
+		ql, err = bunql.ParseFromRequestWithPolicy(httptest.NewRequest("GET", "/users?page=-3&pageSize=50", nil), policy)
+		require.NoError(t, err)
+		require.Equal(t, 0, ql.Pagination.Page)
+		require.Equal(t, 20, ql.Pagination.PageSize)
+
+		_, err = bunql.ParseFromRequestWithPolicy(httptest.NewRequest("GET", "/users?page=x", nil), policy)
+		require.ErrorIs(t, err, bunql.ErrInvalidPage, "Non-numeric values are never clamped")
+	})
+}
@@ -0,0 +1,38 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPinnedIDs tests that pinned rows are sorted before the others
+func TestPinnedIDs(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	sortJSON := `[{"field": "price", "dir": "asc"}]`
+
+	ql, err := bunql.ParseFromParams("", sortJSON, 1, 4)
+	require.NoError(t, err, "Failed to parse parameters")
+	ql.WithPinnedIDs("id", 5, 3)
+
+	query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+	var items []Item
+	err = query.Scan(ctx, &items)
+	require.NoError(t, err, "Query failed")
+
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+
+	// Pinned rows come first, each partition following the requested sort
+	require.Equal(t, []string{"Item3", "Item5", "Item1", "Item2"}, names)
+}
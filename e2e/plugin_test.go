@@ -0,0 +1,113 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// pluginCtxKey marks the contexts the test plugin acts on, so it does not affect the other tests
+type pluginCtxKey struct{}
+
+// testPlugin parses "eq:<field>:<value>" filters, rejects filters on password, scopes queries and records executions
+type testPlugin struct {
+	executions []string
+}
+
+func (p *testPlugin) Name() string { return "test" }
+
+func (p *testPlugin) ParseFilter(param string) (dto.FilterGroup, bool, error) {
+	if !strings.HasPrefix(param, "eq:") {
+		return dto.FilterGroup{}, false, nil
+	}
+	field, value, ok := strings.Cut(strings.TrimPrefix(param, "eq:"), ":")
+	if !ok {
+		return dto.FilterGroup{}, false, errors.New("missing value")
+	}
+	return dto.FilterGroup{Filters: []dto.Filter{{Field: field, Operator: "eq", Value: value}}}, true, nil
+}
+
+func (p *testPlugin) Validate(ql *bunql.BunQL) error {
+	for _, f := range ql.Filters.Filters {
+		if f.Field == "password" {
+			return errors.New("filtering on password is forbidden")
+		}
+	}
+	return nil
+}
+
+func (p *testPlugin) Middleware() bunql.Middleware {
+	return func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			if ctx.Value(pluginCtxKey{}) != nil {
+				query = query.Where("id <= ?", 5)
+			}
+			return next(ctx, ql, query)
+		}
+	}
+}
+
+func (p *testPlugin) BeforeExecute(ctx context.Context, ql *bunql.BunQL) error {
+	if ctx.Value(pluginCtxKey{}) != nil {
+		p.executions = append(p.executions, "before")
+	}
+	return nil
+}
+
+func (p *testPlugin) AfterExecute(ctx context.Context, ql *bunql.BunQL, rows, total int, err error) {
+	if ctx.Value(pluginCtxKey{}) != nil {
+		p.executions = append(p.executions, "after")
+	}
+}
+
+// TestPlugin tests the hooks of a registered plugin
+func TestPlugin(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	plugin := &testPlugin{}
+	bunql.Use(plugin)
+	require.Contains(t, bunql.Plugins(), bunql.Plugin(plugin))
+
+	ctx := context.WithValue(context.Background(), pluginCtxKey{}, true)
+
+	// Test 1: Parse hook
+	t.Run("Parse", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("eq:first_name:User1", "", 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, []dto.Filter{{Field: "first_name", Operator: "eq", Value: "User1"}}, ql.Filters.Filters)
+
+		_, err = bunql.ParseFromParams("eq:first_name", "", 0, 0)
+		require.EqualError(t, err, "plugin 'test': missing value")
+
+		_, err = bunql.ParseFromParamsWithAllowedFields("eq:email:x", "", 0, 0, []string{"first_name"}, nil)
+		require.EqualError(t, err, "filter field 'email' is not allowed", "Parsed filters are validated")
+	})
+
+	// Test 2: Validate hook
+	t.Run("Validate", func(t *testing.T) {
+		_, err := bunql.ParseFromParams(`{"filters": [{"field": "password", "operator": "eq", "value": "x"}]}`, "", 0, 0)
+		require.EqualError(t, err, "filtering on password is forbidden")
+	})
+
+	// Test 3: Apply and execute hooks
+	t.Run("Apply and execute", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "", 1, 3)
+		require.NoError(t, err)
+
+		expected, err := db.NewSelect().Model((*User)(nil)).Where("id <= ?", 5).Count(ctx)
+		require.NoError(t, err)
+
+		users, totalCount, err := bunql.ExecutePage[User](ctx, ql, db.NewSelect().Model((*User)(nil)))
+		require.NoError(t, err)
+		require.Len(t, users, min(expected, 3))
+		require.Equal(t, expected, totalCount, "The plugin middleware scopes the count query too")
+		require.Equal(t, []string{"before", "after"}, plugin.executions)
+	})
+}
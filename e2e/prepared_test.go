@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreparedStatements tests that pages differing only by their filter values reuse the same prepared statements
+func TestPreparedStatements(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 10)
+	_, err := db.NewInsert().Model(&Item{Name: "O'Brien", Category: "c", Price: 5}).Exec(ctx)
+	require.NoError(t, err)
+
+	statements := bunql.NewStatementCache(10)
+	defer statements.Close()
+
+	page := func(category string, minPrice float64) ([]Item, int) {
+		ql := bunql.New().WithPreparedStatements(statements).WithFilters(dto.FilterGroup{Logic: "and", Filters: []dto.Filter{
+			{Field: "category", Operator: "eq", Value: category},
+			{Field: "price", Operator: "gte", Value: minPrice},
+		}})
+		ql.WithPagination(&dto.Pagination{Page: 1, PageSize: 3})
+		items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+		require.NoError(t, err)
+		return items, total
+	}
+
+	items, total := page("a", 30)
+	require.Equal(t, 4, total)
+	require.Len(t, items, 3)
+	require.Equal(t, 2, statements.Len())
+
+	items, total = page("b", 0)
+	require.Equal(t, 5, total)
+	require.Len(t, items, 3)
+	require.Equal(t, 2, statements.Len())
+
+	// Values are passed as parameters, quotes and decimals included
+	items, total = page("c", 4.5)
+	require.Equal(t, 1, total)
+	require.Equal(t, "O'Brien", items[0].Name)
+	require.Equal(t, 2, statements.Len())
+
+	// The least recently used statements are closed once the cache is full
+	small := bunql.NewStatementCache(1)
+	defer small.Close()
+	ql := bunql.New().WithPreparedStatements(small)
+	_, total, err = bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, 11, total)
+	require.Equal(t, 1, small.Len())
+}
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSensitiveFields tests that the values of sensitive fields are redacted in audit entries
+func TestSensitiveFields(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	filterJSON := `{
+		"filters": [{"field": "email", "operator": "eq", "value": "user1@example.com"}],
+		"groups": [{"logic": "or", "filters": [
+			{"field": "email", "operator": "isnull"},
+			{"field": "age", "operator": "gt", "value": 21}
+		]}]
+	}`
+
+	ql, err := bunql.ParseFromParams(filterJSON, "", 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+	ql.WithFieldConfig("email", dto.FieldConfig{Sensitive: true})
+
+	expected := dto.FilterGroup{
+		Logic:   "and",
+		Filters: []dto.Filter{{Field: "email", Operator: "eq", Value: bunql.Redacted}},
+		Groups: []dto.FilterGroup{{
+			Logic: "or",
+			Filters: []dto.Filter{
+				{Field: "email", Operator: "isnull"},
+				{Field: "age", Operator: "gt", Value: int64(21)},
+			},
+			Groups: []dto.FilterGroup{},
+		}},
+	}
+	require.Equal(t, expected, ql.RedactedFilters())
+
+	// The original filters are left untouched
+	require.Equal(t, "user1@example.com", ql.Filters.Filters[0].Value)
+
+	var entry bunql.AuditEntry
+	ql.WithAuditHook(func(ctx context.Context, e bunql.AuditEntry) {
+		entry = e
+	})
+
+	_, _, err = bunql.ExecutePage[User](ctx, ql, db.NewSelect().Model((*User)(nil)))
+	require.NoError(t, err, "Query failed")
+	require.Equal(t, expected, entry.Filters)
+	require.NoError(t, entry.Err)
+
+	// The errors of failed queries lose their message, which may quote the values
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = bunql.ExecutePage[User](canceled, ql, db.NewSelect().Model((*User)(nil)))
+	require.Error(t, err)
+	require.ErrorIs(t, entry.Err, context.Canceled)
+	require.Equal(t, "query failed: error message [REDACTED]", entry.Err.Error())
+}
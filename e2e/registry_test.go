@@ -0,0 +1,106 @@
+package e2e
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFromRequestFor tests that requests are validated against the policy registered for a model
+func TestParseFromRequestFor(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	bunql.Register[Item](bunql.Policy{
+		FilterFields: []string{"category", "price"},
+		SortFields:   []string{"price"},
+		Fields: map[string]dto.FieldConfig{
+			"price":    {Type: dto.FieldNumber},
+			"category": {Operators: []string{"eq", "in"}},
+		},
+		DefaultSort: []dto.SortField{{Field: "price", Direction: "desc"}},
+		MaxPageSize: 2,
+	})
+
+	parse := func(params url.Values) (*bunql.BunQL, error) {
+		return bunql.ParseFromRequestFor[Item](httptest.NewRequest("GET", "/items?"+params.Encode(), nil))
+	}
+
+	// The default sort applies and the page size is capped
+	ql, err := parse(url.Values{
+		"filter":   {`{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}`},
+		"pageSize": {"50"},
+	})
+	require.NoError(t, err, "Failed to parse request")
+
+	var items []Item
+	err = ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items)
+	require.NoError(t, err, "Query failed")
+	require.Len(t, items, 2)
+	require.Equal(t, "Item5", items[0].Name)
+	require.Equal(t, "Item3", items[1].Name)
+
+	// Requests breaking the policy are rejected
+	_, err = parse(url.Values{"filter": {`{"filters": [{"field": "name", "operator": "eq", "value": "Item1"}]}`}})
+	require.EqualError(t, err, "filter field 'name' is not allowed")
+
+	_, err = parse(url.Values{"filter": {`{"filters": [{"field": "category", "operator": "like", "value": "a"}]}`}})
+	require.EqualError(t, err, "operator 'like' is not allowed on field 'category'")
+
+	_, err = parse(url.Values{"filter": {`{"filters": [{"field": "price", "operator": "in", "value": [10, "x"]}]}`}})
+	require.EqualError(t, err, "filter field 'price' requires number values")
+
+	_, err = parse(url.Values{"sort": {`[{"field": "name", "dir": "asc"}]`}})
+	require.EqualError(t, err, "sort field 'name' is not allowed")
+
+	_, err = parse(url.Values{"page": {"two"}})
+	require.EqualError(t, err, "invalid page parameter 'two': page must be a positive integer")
+	require.ErrorIs(t, err, bunql.ErrInvalidPage)
+
+	// Models without a policy are rejected
+	_, err = bunql.ParseFromRequestFor[User](httptest.NewRequest("GET", "/users", nil))
+	require.ErrorIs(t, err, bunql.ErrNoPolicy)
+}
+
+// TestParseFromRequestRepeatedFilters tests that repeated filter parameters are combined
+func TestParseFromRequestRepeatedFilters(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	params := url.Values{"filter": {
+		`{"field": "age", "operator": "lte", "value": 25}`,
+		`{"field": "first_name", "operator": "in", "value": ["User1", "User2", "User9"]}`,
+	}}
+	r := httptest.NewRequest("GET", "/users?"+params.Encode(), nil)
+
+	// Test 1: Facets combined with AND
+	t.Run("AND", func(t *testing.T) {
+		ql, err := bunql.ParseFromRequest(r)
+		require.NoError(t, err)
+
+		var users []User
+		require.NoError(t, ql.Apply(ctx, db.NewSelect().Model((*User)(nil))).Scan(ctx, &users))
+		for _, user := range users {
+			require.LessOrEqual(t, user.Age, 25)
+			require.Contains(t, []string{"User1", "User2", "User9"}, user.FirstName)
+		}
+	})
+
+	// Test 2: Facets combined with OR per policy
+	t.Run("OR", func(t *testing.T) {
+		ql, err := bunql.ParseFromRequestWithPolicy(r, bunql.Policy{FilterParamLogic: "or"})
+		require.NoError(t, err)
+		require.Equal(t, "or", ql.Filters.Logic)
+		require.Len(t, ql.Filters.Filters, 2)
+	})
+}
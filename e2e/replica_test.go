@@ -0,0 +1,58 @@
+package e2e
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// TestReplica tests that the read-only queries are routed to the replica, except within a transaction
+func TestReplica(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 5)
+
+	// The replica is a separate database lagging behind the primary, with only two of its items
+	sqldb, err := sql.Open(sqliteshim.DriverName(), "file:replica?mode=memory&cache=shared")
+	require.NoError(t, err)
+	defer sqldb.Close()
+	replica := bun.NewDB(sqldb, sqlitedialect.New())
+	require.NoError(t, replica.ResetModel(ctx, (*Item)(nil)))
+	_, err = replica.NewInsert().Model(&[]Item{{Name: "Item1", Category: "a", Price: 10}, {Name: "Item2", Category: "b", Price: 20}}).Exec(ctx)
+	require.NoError(t, err)
+
+	ql := bunql.New().WithReplica(replica)
+
+	items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, 2, total)
+
+	median, err := ql.Median(ctx, db.NewSelect().Model((*Item)(nil)), "price")
+	require.NoError(t, err)
+	require.Equal(t, 15.0, median)
+
+	var names []string
+	for item, err := range bunql.Iterate[Item](ctx, db, ql) {
+		require.NoError(t, err)
+		names = append(names, item.Name)
+	}
+	require.Equal(t, []string{"Item1", "Item2"}, names)
+
+	// Queries on a transaction stay on the primary
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	items, total, err = bunql.ExecutePage[Item](ctx, ql, tx.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	require.Equal(t, 5, total)
+}
@@ -0,0 +1,72 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSavedSearch tests saving the state of a query and restoring it
+func TestSavedSearch(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	store := bunql.NewMemorySavedSearchStore()
+
+	filterJSON := `{"logic": "and", "filters": [{"field": "age", "operator": "gt", "value": 30}]}`
+	sortJSON := `[{"field": "age", "dir": "desc"}]`
+
+	ql, err := bunql.ParseFromParams(filterJSON, sortJSON, 3, 4)
+	require.NoError(t, err, "Failed to parse parameters")
+	require.NoError(t, store.Save(ctx, "alice", ql.SavedSearch("over-30", "Over 30")))
+
+	// Test 1: Round trip through JSON and the store
+	t.Run("Restore", func(t *testing.T) {
+		saved, err := store.Load(ctx, "alice", "over-30")
+		require.NoError(t, err)
+
+		data, err := json.Marshal(saved)
+		require.NoError(t, err)
+		var decoded bunql.SavedSearch
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		restored := bunql.New()
+		require.NoError(t, restored.RestoreSavedSearch(decoded))
+		require.Equal(t, 1, restored.Pagination.Page, "Restored searches start from the first page")
+		require.Equal(t, 4, restored.Pagination.PageSize)
+
+		var expected, actual []User
+		require.NoError(t, ql.WithPagination(restored.Pagination).Apply(ctx, db.NewSelect().Model((*User)(nil))).Scan(ctx, &expected))
+		require.NoError(t, restored.Apply(ctx, db.NewSelect().Model((*User)(nil))).Scan(ctx, &actual))
+		require.Equal(t, expected, actual)
+	})
+
+	// Test 2: Restoring validates against the allowed fields
+	t.Run("Validation", func(t *testing.T) {
+		saved, err := store.Load(ctx, "alice", "over-30")
+		require.NoError(t, err)
+
+		restricted := bunql.NewWithAllowedFields([]string{"first_name"}, nil)
+		require.EqualError(t, restricted.RestoreSavedSearch(saved), "filter field 'age' is not allowed")
+	})
+
+	// Test 3: Searches are per user
+	t.Run("Per user", func(t *testing.T) {
+		_, err := store.Load(ctx, "bob", "over-30")
+		require.ErrorIs(t, err, bunql.ErrSavedSearchNotFound)
+
+		require.NoError(t, store.Save(ctx, "alice", bunql.New().SavedSearch("all", "All users")))
+		searches, err := store.List(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, searches, 2)
+		require.Equal(t, "All users", searches[0].Name)
+
+		searches, err = store.List(ctx, "bob")
+		require.NoError(t, err)
+		require.Empty(t, searches)
+	})
+}
@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilterScopes tests that scoped filter groups may only use the fields of their scope
+func TestFilterScopes(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 10)
+
+	var denied []bunql.DeniedField
+	template := bunql.NewWithAllowedFields([]string{"name", "category", "price"}, nil).
+		WithFilterScope("search", "name").
+		WithFilterScope("facets", "name", "category")
+	template.WithDeniedFieldHook(func(ctx context.Context, field bunql.DeniedField) {
+		denied = append(denied, field)
+	})
+
+	parse := func(filter string) (*bunql.BunQL, error) {
+		denied = nil
+		return template.ParseRequest(httptest.NewRequest("GET", "/items?"+url.Values{"filter": {filter}}.Encode(), nil))
+	}
+
+	// The search box group only uses its fields, the rest of the request any allowed field
+	ql, err := parse(`{"logic": "and", "filters": [{"field": "price", "operator": "gte", "value": 50}],
+		"groups": [{"logic": "or", "scope": "search", "filters": [{"field": "name", "operator": "eq", "value": "Item5"}, {"field": "name", "operator": "eq", "value": "Item6"}]}]}`)
+	require.NoError(t, err)
+	items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Len(t, items, 2)
+
+	_, err = parse(`{"groups": [{"logic": "or", "scope": "search", "filters": [{"field": "name", "operator": "eq", "value": "Item5"}, {"field": "price", "operator": "gt", "value": 0}]}]}`)
+	require.EqualError(t, err, "filter field 'price' is not allowed in scope 'search'")
+	require.Equal(t, []bunql.DeniedField{{Field: "price", Usage: "filter", Operator: "gt", Value: int64(0), Scope: "search"}}, denied)
+
+	// Nested groups inherit the scope, and a nested scope only narrows it
+	_, err = parse(`{"groups": [{"scope": "search", "groups": [{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}]}]}`)
+	require.EqualError(t, err, "filter field 'category' is not allowed in scope 'search'")
+	_, err = parse(`{"groups": [{"scope": "search", "groups": [{"scope": "facets", "filters": [{"field": "category", "operator": "eq", "value": "a"}]}]}]}`)
+	require.EqualError(t, err, "filter field 'category' is not allowed in scope 'facets'")
+
+	// Fields outside of the allowed fields are reported once, without a scope
+	_, err = parse(`{"groups": [{"scope": "search", "filters": [{"field": "id", "operator": "eq", "value": 1}]}]}`)
+	require.EqualError(t, err, "filter field 'id' is not allowed")
+	require.Equal(t, []bunql.DeniedField{{Field: "id", Usage: "filter", Operator: "eq", Value: int64(1)}}, denied)
+
+	_, err = parse(`{"groups": [{"scope": "admin", "filters": [{"field": "name", "operator": "eq", "value": "Item1"}]}]}`)
+	require.EqualError(t, err, "unknown filter group scope 'admin'")
+}
@@ -0,0 +1,53 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSearchRelevance tests the global search and the virtual relevance sort field
+func TestSearchRelevance(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 12)
+
+	// Test 1: Relevance sort, exact matches first
+	t.Run("Relevance", func(t *testing.T) {
+		sortJSON := `[{"field": "relevance", "dir": "desc"}, {"field": "price", "dir": "desc"}]`
+
+		ql, err := bunql.ParseFromParamsWithAllowedFields("", sortJSON, 0, 0, nil, []string{"price"})
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithSearch("item1", "name", "category")
+
+		query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+		var items []Item
+		err = query.Scan(ctx, &items)
+		require.NoError(t, err, "Query failed")
+
+		names := make([]string, len(items))
+		for i, item := range items {
+			names[i] = item.Name
+		}
+		require.Equal(t, []string{"Item1", "Item12", "Item11", "Item10"}, names)
+	})
+
+	// Test 2: Wildcards in the term are matched literally
+	t.Run("Escaped wildcards", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "", 0, 0)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithSearch("item_", "name")
+
+		query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+		var items []Item
+		err = query.Scan(ctx, &items)
+		require.NoError(t, err, "Query failed")
+		require.Empty(t, items, "The underscore should not match any character")
+	})
+}
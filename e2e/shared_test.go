@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// TestSharedTemplate tests that a configured BunQL can serve as the template of concurrent requests, whose clones
+// never write to the template. Run with -race to check for data races
+func TestSharedTemplate(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 10)
+
+	passthrough := func(next bunql.Applier) bunql.Applier {
+		return func(ctx context.Context, ql *bunql.BunQL, query *bun.SelectQuery) *bun.SelectQuery {
+			return next(ctx, ql, query)
+		}
+	}
+	template := bunql.NewWithAllowedFields([]string{"category", "price"}, []string{"price", "cheapest"}).
+		WithFieldConfig("category", dto.FieldConfig{Type: dto.FieldString}).
+		WithOperatorTemplate(dialect.PG, "like", "? ILIKE ?")
+	// Spare capacity would let concurrent appends of the clones write to the same array
+	template.Middleware = append(make([]bunql.Middleware, 0, 8), passthrough)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			category := []string{"a", "b"}[i%2]
+			params := url.Values{
+				"filter":   {fmt.Sprintf(`{"filters":[{"field":"category","operator":"eq","value":%q}]}`, category)},
+				"sort":     {`[{"field":"cheapest","dir":"desc"}]`},
+				"page":     {"1"},
+				"pageSize": {"3"},
+			}
+			ql, err := template.ParseRequest(httptest.NewRequest("GET", "/items?"+params.Encode(), nil))
+			if !assert.NoError(t, err) {
+				return
+			}
+			ql.WithMiddleware(passthrough).
+				WithSortAlias("cheapest", dto.SortField{Field: "price", Direction: "asc"}).
+				WithFieldConfig("price", dto.FieldConfig{Type: dto.FieldNumber}).
+				WithOperatorTemplate(dialect.PG, "eq", "? = ?").
+				WithOperatorTemplate(dialect.MySQL, "eq", "? = ?")
+
+			items, total, err := bunql.ExecutePage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)))
+			if !assert.NoError(t, err) || !assert.Len(t, items, 3) {
+				return
+			}
+			assert.Equal(t, 5, total)
+			for _, item := range items {
+				assert.Equal(t, category, item.Category)
+			}
+			assert.Greater(t, items[0].Price, items[1].Price)
+		}(i)
+	}
+	wg.Wait()
+
+	// The template is left as configured
+	require.Len(t, template.Middleware, 1)
+	require.Len(t, template.Fields, 1)
+	require.Empty(t, template.SortAliases)
+	require.Equal(t, map[string]string{"like": "? ILIKE ?"}, template.FilterOptions.Templates[dialect.PG])
+	require.Nil(t, template.FilterOptions.Templates[dialect.MySQL])
+	require.Empty(t, template.Filters.Filters)
+	require.Nil(t, template.Pagination)
+}
@@ -2,6 +2,7 @@ package e2e
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -192,4 +193,38 @@ func TestPaginationMetadata(t *testing.T) {
 	// Check that neither prev nor next is included
 	require.Nil(t, metadata.Prev, "prev should be nil when pagination is nil")
 	require.Nil(t, metadata.Next, "next should be nil when pagination is nil")
+
+	// Test case 5: Unknown total count (the totals are omitted and a full page is taken as followed by another one)
+	pagination.Page = 2
+	metadata = bunql.GetPaginationMetadataForPage(pagination, -1, 10, baseURI)
+	require.Equal(t, -1, metadata.Total)
+	require.True(t, metadata.HasPrev)
+	require.True(t, metadata.HasNext)
+	require.Contains(t, *metadata.Next, "page=3")
+
+	out, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	require.NotContains(t, string(out), "total")
+	require.Contains(t, string(out), `"hasNext":true`)
+
+	metadata = bunql.GetPaginationMetadataForPage(pagination, -1, 4, baseURI)
+	require.False(t, metadata.HasNext)
+	require.Nil(t, metadata.Next)
+
+	// Known totals are kept in the JSON, even when zero
+	out, err = json.Marshal(bunql.GetPaginationMetadata(pagination, 0, baseURI))
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"total":0`)
+	require.Contains(t, string(out), `"totalItem":0`)
+}
+
+// BenchmarkGetPaginationMetadata measures building the pagination metadata with prev and next links
+func BenchmarkGetPaginationMetadata(b *testing.B) {
+	pagination := &dto.Pagination{Page: 2, PageSize: 10}
+	baseURI := "https://api.example.com/users?filter=active&sort=name"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bunql.GetPaginationMetadata(pagination, 100, baseURI)
+	}
 }
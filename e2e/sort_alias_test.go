@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSortAlias tests that a sort alias expands to its columns, all inverted when sorting descending
+func TestSortAlias(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	names := func(items []Item) []string {
+		var names []string
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+	parse := func(sortParam string) *bunql.BunQL {
+		ql, err := bunql.ParseFromParamsWithAllowedFields("", sortParam, 0, 0, nil, []string{"shelf"})
+		require.NoError(t, err)
+		return ql.WithSortAlias("shelf", dto.SortField{Field: "category"}, dto.SortField{Field: "price", Direction: "desc"})
+	}
+
+	var items []Item
+	require.NoError(t, parse("shelf:asc").Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items))
+	require.Equal(t, []string{"Item5", "Item3", "Item1", "Item6", "Item4", "Item2"}, names(items))
+
+	items = nil
+	require.NoError(t, parse("shelf:desc").Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &items))
+	require.Equal(t, []string{"Item2", "Item4", "Item6", "Item1", "Item3", "Item5"}, names(items))
+
+	// An alias named after one of its columns is expanded once
+	ql, err := bunql.ParseFromParamsWithAllowedFields("", "price:asc", 0, 0, nil, []string{"price"})
+	require.NoError(t, err)
+	ql.WithSortAlias("price", dto.SortField{Field: "price", Direction: "desc"}, dto.SortField{Field: "id", Direction: "asc"})
+	query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+	require.Contains(t, query.String(), `ORDER BY price DESC, id ASC`)
+	require.NotContains(t, query.String(), `id DESC`)
+
+	// The columns of the alias are not sort fields on their own
+	_, err = bunql.ParseFromParamsWithAllowedFields("", "category:asc", 0, 0, nil, []string{"shelf"})
+	require.Error(t, err)
+
+	// Cursors walk the columns of the alias
+	secret := []byte("cursor-secret")
+	ql = parse("shelf:desc")
+	ql.WithPagination(&dto.Pagination{PageSize: 4})
+	page, metadata, err := bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Item2", "Item4", "Item6", "Item1"}, names(page))
+	require.NotNil(t, metadata.Next)
+
+	c, err := cursor.Decode(*metadata.Next, secret)
+	require.NoError(t, err)
+	ql = parse("shelf:desc")
+	ql.WithPagination(&dto.Pagination{PageSize: 4})
+	page, _, err = bunql.ExecuteCursorPage[Item](ctx, ql.WithCursor(c), db.NewSelect().Model((*Item)(nil)), secret)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Item3", "Item5"}, names(page))
+}
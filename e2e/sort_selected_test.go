@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSortNotSelected tests that sorting on a column the query doesn't select fails clearly where it can't work,
+// or adds the column when configured so
+func TestSortNotSelected(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	sortedBy := func(field string) *bunql.BunQL {
+		return bunql.New().WithSort([]dto.SortField{{Field: field, Direction: "desc"}})
+	}
+
+	// Sorting DISTINCT rows on a column they don't have
+	var categories []string
+	query := db.NewSelect().Model((*Item)(nil)).Column("category").Distinct()
+	err := sortedBy("price").Apply(ctx, query).Scan(ctx, &categories)
+	require.ErrorIs(t, err, bunql.ErrSortNotSelected)
+	require.EqualError(t, err, "cannot sort on 'price': sort field is not among the selected columns")
+
+	query = db.NewSelect().Model((*Item)(nil)).Column("category").Distinct()
+	require.NoError(t, sortedBy("category").Apply(ctx, query).Scan(ctx, &categories))
+	require.Equal(t, []string{"b", "a"}, categories)
+
+	// Other queries may sort on columns they don't select
+	var names []string
+	require.NoError(t, sortedBy("price").Apply(ctx, db.NewSelect().Model((*Item)(nil)).Column("name")).Scan(ctx, &names))
+	require.Equal(t, "Item6", names[0])
+
+	// Cursors are read from the selected columns, the primary key breaking ties included
+	secret := []byte("cursor-secret")
+	ql := sortedBy("price").WithPagination(&dto.Pagination{Page: 1, PageSize: 2})
+	_, _, err = bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)).Column("name", "price"), secret)
+	require.ErrorIs(t, err, bunql.ErrSortNotSelected)
+
+	ql.WithAddSortColumns(true)
+	items, metadata, err := bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)).Column("name", "price"), secret)
+	require.NoError(t, err)
+	require.Equal(t, []int64{6, 5}, []int64{items[0].ID, items[1].ID})
+	require.NotNil(t, metadata.Next)
+
+	// The missing columns are added to any query
+	var sparse []Item
+	err = sortedBy("price").WithAddSortColumns(true).Apply(ctx, db.NewSelect().Model((*Item)(nil)).Column("name")).Scan(ctx, &sparse)
+	require.NoError(t, err)
+	require.Equal(t, 60, sparse[0].Price)
+	require.Zero(t, sparse[0].ID)
+}
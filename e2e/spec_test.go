@@ -0,0 +1,63 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/dialect"
+)
+
+// TestQuerySpec tests passing the parsed state of a query as a serialized spec applied elsewhere
+func TestQuerySpec(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}`, "price:desc", 1, 2)
+	require.NoError(t, err)
+	ql.WithSelectedFields("id", "name")
+
+	// The spec survives a round trip through JSON, e.g. over a message queue
+	data, err := json.Marshal(ql.Spec())
+	require.NoError(t, err)
+	var spec bunql.QuerySpec
+	require.NoError(t, json.Unmarshal(data, &spec))
+	require.Equal(t, ql.Spec(), spec)
+
+	var items []Item
+	require.NoError(t, spec.Apply(ctx, db.NewSelect().Model(&items)).Scan(ctx))
+	require.Equal(t, []Item{{ID: 5, Name: "Item5"}, {ID: 3, Name: "Item3"}}, items)
+
+	sql, args, err := spec.Compile(dialect.PG, "items")
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id", "name" FROM "items" WHERE ((("category" = $1))) ORDER BY price DESC LIMIT $2`, sql)
+	require.Equal(t, []interface{}{"a", int64(2)}, args)
+
+	_, _, err = spec.Compile(dialect.Invalid, "items")
+	require.Error(t, err)
+
+	// Specs are validated against the configuration of the BunQL they are set on
+	configured := bunql.NewWithAllowedFields([]string{"category", "id"}, []string{"name"})
+	require.EqualError(t, configured.WithSpec(spec), "sort field 'price' is not allowed")
+	spec.Sort = nil
+	require.NoError(t, configured.WithSpec(spec))
+	require.Equal(t, spec, configured.Spec())
+
+	spec.Fields = []string{"name, secret"}
+	require.EqualError(t, configured.WithSpec(spec), "selected field 'name, secret' is not a column")
+
+	// Selected fields must be allowed, and not denied, for filtering or sorting
+	spec.Fields = []string{"id", "price"}
+	require.EqualError(t, configured.WithSpec(spec), "selected field 'price' is not allowed")
+
+	denied := bunql.New().WithDeniedFields([]string{"password_hash"}, nil)
+	spec.Fields = []string{"id", "Password_Hash"}
+	require.EqualError(t, denied.WithSpec(spec), "selected field 'Password_Hash' is not allowed")
+	spec.Fields = []string{"id", "name"}
+	require.NoError(t, denied.WithSpec(spec))
+}
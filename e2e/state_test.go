@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// TestMarshalBinary tests that a worker restores the exact query serialized by an API node
+func TestMarshalBinary(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "price", "operator": "between", "value": [20, 50]}]}`, "cheapest:asc", 1, 3)
+	require.NoError(t, err)
+	ql.WithSortAlias("cheapest", dto.SortField{Field: "price", Direction: "asc", NullsAs: 0}).
+		WithPinnedIDs("id", int64(4)).
+		WithFieldConfig("name", dto.FieldConfig{Type: dto.FieldString, Sensitive: true}).
+		WithDeniedFields([]string{"secret"}, nil).
+		WithTimeout(time.Second).
+		WithMaxRows(100)
+
+	data, err := ql.MarshalBinary()
+	require.NoError(t, err)
+
+	// The worker keeps its own hooks
+	var warnings int
+	worker := bunql.New().WithWarningHook(func(context.Context, bunql.Warning) { warnings++ })
+	require.NoError(t, worker.UnmarshalBinary(data))
+
+	require.Equal(t, ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).String(), worker.Apply(ctx, db.NewSelect().Model((*Item)(nil))).String())
+	require.Equal(t, ql.Spec(), worker.Spec())
+	require.Equal(t, ql.Pinned, worker.Pinned)
+	require.Equal(t, ql.Fields, worker.Fields)
+	require.Equal(t, time.Second, worker.Timeout)
+	require.NotNil(t, worker.WarningHook)
+
+	items, total, err := bunql.ExecutePage[Item](ctx, worker, db.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+	require.Equal(t, []int64{4, 2, 3}, []int64{items[0].ID, items[1].ID, items[2].ID})
+
+	require.EqualError(t, worker.UnmarshalBinary([]byte(`{"version": 2}`)), "unsupported query state version 2")
+}
+
+// TestMarshalBinaryComputedFields tests that the computed fields survive the round trip, with the filters on them
+func TestMarshalBinaryComputedFields(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	totals := func() *bun.SelectQuery {
+		return db.NewSelect().TableExpr("items AS i").
+			Column("i.category").ColumnExpr("SUM(i.price) AS total").
+			Group("i.category")
+	}
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "total", "operator": "gt", "value": 100}]}`, "total:desc", 0, 0)
+	require.NoError(t, err)
+	ql.WithComputedField("total", bunql.ComputedField{Expression: "SUM(i.price)", Aggregate: true})
+
+	data, err := ql.MarshalBinary()
+	require.NoError(t, err)
+	worker := bunql.New()
+	require.NoError(t, worker.UnmarshalBinary(data))
+	require.Equal(t, ql.ComputedFields, worker.ComputedFields)
+
+	var rows []CategoryTotal
+	query := worker.Apply(ctx, totals())
+	require.Contains(t, query.String(), "HAVING ((((SUM(i.price)) > 100)))")
+	require.NoError(t, query.Scan(ctx, &rows))
+	require.Equal(t, []CategoryTotal{{Category: "b", Total: 120}}, rows)
+}
@@ -0,0 +1,40 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPercentiles tests that percentiles are computed over the filtered rows
+func TestPercentiles(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	// Items in the category a are priced 10, 30 and 50
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "a"}]}`, "price:desc", 1, 2)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	median, err := ql.Median(ctx, db.NewSelect().Model((*Item)(nil)), "price")
+	require.NoError(t, err, "Median failed")
+	require.Equal(t, 30.0, median)
+
+	// Percentiles between two values are interpolated, and the sort and pagination are ignored
+	values, err := ql.Percentiles(ctx, db.NewSelect().Model((*Item)(nil)), "price", 0, 0.25, 0.95, 1)
+	require.NoError(t, err, "Percentiles failed")
+	require.InDeltaSlice(t, []float64{10, 20, 48, 50}, values, 1e-9)
+
+	// No rows match
+	ql, err = bunql.ParseFromParams(`{"filters": [{"field": "category", "operator": "eq", "value": "c"}]}`, "", 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+	_, err = ql.Median(ctx, db.NewSelect().Model((*Item)(nil)), "price")
+	require.ErrorIs(t, err, bunql.ErrNoValues)
+
+	_, err = ql.Percentiles(ctx, db.NewSelect().Model((*Item)(nil)), "price", 1.5)
+	require.EqualError(t, err, "percentile 1.5 is out of range [0, 1]")
+}
@@ -0,0 +1,68 @@
+package e2e
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// summaryHook is a query hook recording the summaries of the queries it sees
+type summaryHook struct {
+	mu        sync.Mutex
+	summaries []bunql.QuerySummary
+	queries   int
+}
+
+func (h *summaryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queries++
+	if summary, ok := bunql.SummaryFromContext(ctx); ok {
+		h.summaries = append(h.summaries, summary)
+	}
+	return ctx
+}
+
+func (h *summaryHook) AfterQuery(context.Context, *bun.QueryEvent) {}
+
+// TestQuerySummary tests that query hooks can tell which filters produced the queries they see
+func TestQuerySummary(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 4)
+
+	hook := &summaryHook{}
+	hooked := bun.NewDB(db.DB, sqlitedialect.New())
+	hooked.AddQueryHook(hook)
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "name", "operator": "EQ", "value": "Item2"}]}`, "price:asc", 1, 2)
+	require.NoError(t, err)
+	ql.WithFieldConfig("name", dto.FieldConfig{Sensitive: true})
+
+	// The main query and the count query of a page both carry the summary
+	_, _, err = bunql.ExecutePage[Item](ctx, ql, hooked.NewSelect().Model((*Item)(nil)))
+	require.NoError(t, err)
+	require.Equal(t, 2, hook.queries)
+	require.Len(t, hook.summaries, 2)
+	summary := hook.summaries[0]
+	require.Equal(t, "eq", summary.Filters.Filters[0].Operator)
+	require.Equal(t, bunql.Redacted, summary.Filters.Filters[0].Value)
+	require.Equal(t, summary.Filters.Hash(), summary.Hash)
+	require.Equal(t, "name = [REDACTED], sorted by price asc, page 1 of size 2", summary.Description)
+
+	// Queries applied by hand carry it when executed with the context of the BunQL
+	var items []Item
+	require.NoError(t, ql.Apply(ctx, hooked.NewSelect().Model((*Item)(nil))).Scan(ql.ContextWithSummary(ctx), &items))
+	require.Len(t, hook.summaries, 3)
+	require.NoError(t, ql.Apply(ctx, hooked.NewSelect().Model((*Item)(nil))).Scan(ctx, &items))
+	require.Equal(t, 4, hook.queries)
+	require.Len(t, hook.summaries, 3)
+}
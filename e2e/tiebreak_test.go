@@ -0,0 +1,90 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// NamedItem is an item whose name is declared unique
+type NamedItem struct {
+	bun.BaseModel `bun:"table:items,alias:i"`
+
+	ID   int64  `bun:"id,pk,autoincrement"`
+	Name string `bun:"name,unique,notnull"`
+}
+
+// TestSortTieBreak tests that pages sorted on a non-unique column are ordered by the primary key within ties
+func TestSortTieBreak(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 7)
+
+	t.Run("Offset pagination", func(t *testing.T) {
+		var names []string
+		for page := 1; page <= 4; page++ {
+			ql, err := bunql.ParseFromParams("", "category:desc", page, 2)
+			require.NoError(t, err)
+			query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+			require.Contains(t, query.String(), "ORDER BY category DESC, id DESC")
+
+			var items []Item
+			require.NoError(t, query.Scan(ctx, &items))
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+		}
+		require.Equal(t, []string{"Item6", "Item4", "Item2", "Item7", "Item5", "Item3", "Item1"}, names)
+	})
+
+	t.Run("Cursor pagination", func(t *testing.T) {
+		secret := []byte("cursor-secret")
+		var names []string
+		token := ""
+		for pages := 0; pages < 5; pages++ {
+			ql, err := bunql.ParseFromParams("", "category:asc", 0, 3)
+			require.NoError(t, err)
+			if token != "" {
+				c, err := cursor.Decode(token, secret)
+				require.NoError(t, err)
+				ql.WithCursor(c)
+			}
+
+			items, metadata, err := bunql.ExecuteCursorPage[Item](ctx, ql, db.NewSelect().Model((*Item)(nil)), secret)
+			require.NoError(t, err)
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			if metadata.Next == nil {
+				break
+			}
+			token = *metadata.Next
+		}
+		require.Equal(t, []string{"Item1", "Item3", "Item5", "Item7", "Item2", "Item4", "Item6"}, names)
+	})
+
+	t.Run("Unique sort keys", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "i.name:asc", 1, 2)
+		require.NoError(t, err)
+		query := ql.Apply(ctx, db.NewSelect().Model((*NamedItem)(nil)))
+		require.NotContains(t, query.String(), "id ASC")
+
+		ql, err = bunql.ParseFromParams("", "id:desc,category:asc", 1, 2)
+		require.NoError(t, err)
+		query = ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+		require.Contains(t, query.String(), "ORDER BY id DESC, category ASC LIMIT 2")
+	})
+
+	t.Run("Unpaginated queries", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", "category:asc", 0, 0)
+		require.NoError(t, err)
+		query := ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+		require.NotContains(t, query.String(), "id ASC")
+	})
+}
@@ -0,0 +1,50 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecutePageTimeout tests that the configured timeout bounds query execution
+func TestExecutePageTimeout(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	filterJSON := `{
+		"logic": "and",
+		"filters": [
+			{"field": "age", "operator": "gt", "value": 20}
+		]
+	}`
+
+	// Test 1: Generous timeout
+	t.Run("Within timeout", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams(filterJSON, "", 1, 5)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithTimeout(5 * time.Second)
+
+		query := db.NewSelect().Model((*User)(nil))
+		users, totalCount, err := bunql.ExecutePage[User](ctx, ql, query)
+		require.NoError(t, err, "Query execution failed")
+		require.LessOrEqual(t, len(users), 5)
+		require.GreaterOrEqual(t, totalCount, len(users))
+	})
+
+	// Test 2: Timeout already elapsed
+	t.Run("Exceeded timeout", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams(filterJSON, "", 1, 5)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithTimeout(time.Nanosecond)
+
+		query := db.NewSelect().Model((*User)(nil))
+		_, _, err = bunql.ExecutePage[User](ctx, ql, query)
+		require.Error(t, err, "Should fail once the deadline has passed")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
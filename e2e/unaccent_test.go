@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnaccentField tests accent-insensitive filtering and sorting on a configured field
+func TestUnaccentField(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 0)
+
+	items := []Item{
+		{Name: "Eva", Category: "a"},
+		{Name: "José", Category: "a"},
+		{Name: "Élodie", Category: "a"},
+		{Name: "Emma", Category: "a"},
+	}
+	_, err := db.NewInsert().Model(&items).Exec(ctx)
+	require.NoError(t, err, "Failed to insert data")
+
+	// Test 1: Filtering ignores accents
+	t.Run("Filter", func(t *testing.T) {
+		filterJSON := `{"filters": [{"field": "name", "operator": "like", "value": "jose"}]}`
+
+		ql, err := bunql.ParseFromParams(filterJSON, "", 0, 0)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithFieldConfig("name", dto.FieldConfig{Unaccent: true})
+
+		var result []Item
+		err = ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &result)
+		require.NoError(t, err, "Query failed")
+		require.Len(t, result, 1)
+		require.Equal(t, "José", result[0].Name)
+	})
+
+	// Test 2: Sorting ignores accents
+	t.Run("Sort", func(t *testing.T) {
+		ql, err := bunql.ParseFromParams("", `[{"field": "name", "dir": "asc"}]`, 0, 0)
+		require.NoError(t, err, "Failed to parse parameters")
+		ql.WithFieldConfig("name", dto.FieldConfig{Unaccent: true})
+
+		var result []Item
+		err = ql.Apply(ctx, db.NewSelect().Model((*Item)(nil))).Scan(ctx, &result)
+		require.NoError(t, err, "Query failed")
+
+		names := make([]string, len(result))
+		for i, item := range result {
+			names[i] = item.Name
+		}
+		require.Equal(t, []string{"Élodie", "Emma", "Eva", "José"}, names)
+	})
+}
@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type Post struct {
+	bun.BaseModel `bun:"table:posts"`
+
+	ID        int64     `bun:"id,pk,autoincrement"`
+	Title     string    `bun:"title"`
+	AuthorID  int64     `bun:"author_id"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+type Comment struct {
+	bun.BaseModel `bun:"table:comments"`
+
+	ID        int64     `bun:"id,pk,autoincrement"`
+	Body      string    `bun:"body"`
+	AuthorID  int64     `bun:"author_id"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+// Activity is a row of the combined feed of posts and comments
+type Activity struct {
+	Kind      string    `bun:"kind"`
+	ID        int64     `bun:"id"`
+	Text      string    `bun:"text"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+// TestUnion tests that the union of several filtered models is sorted, paginated and counted as a whole
+func TestUnion(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	for _, model := range []interface{}{(*Post)(nil), (*Comment)(nil)} {
+		require.NoError(t, db.ResetModel(ctx, model), "Failed to create table")
+	}
+
+	at := func(day int) time.Time { return time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC) }
+	posts := []Post{
+		{Title: "Hello", AuthorID: 1, CreatedAt: at(1)},
+		{Title: "Second post", AuthorID: 1, CreatedAt: at(4)},
+		{Title: "Other author", AuthorID: 2, CreatedAt: at(5)},
+	}
+	comments := []Comment{
+		{Body: "First!", AuthorID: 1, CreatedAt: at(2)},
+		{Body: "Thanks", AuthorID: 1, CreatedAt: at(6)},
+		{Body: "Nice", AuthorID: 2, CreatedAt: at(3)},
+	}
+	_, err := db.NewInsert().Model(&posts).Exec(ctx)
+	require.NoError(t, err, "Failed to insert posts")
+	_, err = db.NewInsert().Model(&comments).Exec(ctx)
+	require.NoError(t, err, "Failed to insert comments")
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "author_id", "operator": "eq", "value": 1}]}`, "created_at:desc", 1, 3)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	union, err := ql.Union(ctx,
+		db.NewSelect().Model((*Post)(nil)).ColumnExpr("'post' AS kind, id, title AS text, created_at"),
+		db.NewSelect().Model((*Comment)(nil)).ColumnExpr("'comment' AS kind, id, body AS text, created_at"),
+	)
+	require.NoError(t, err, "Failed to build the union")
+
+	activities, total, err := bunql.ExecuteUnion[Activity](ctx, union)
+	require.NoError(t, err, "Union failed")
+	require.Equal(t, 4, total)
+
+	var texts []string
+	for _, a := range activities {
+		texts = append(texts, a.Kind+":"+a.Text)
+	}
+	require.Equal(t, []string{"comment:Thanks", "post:Second post", "comment:First!"}, texts)
+
+	// The second page holds the last row
+	ql.WithPagination(&dto.Pagination{Page: 2, PageSize: 3})
+	union, err = ql.Union(ctx,
+		db.NewSelect().Model((*Post)(nil)).ColumnExpr("'post' AS kind, id, title AS text, created_at"),
+		db.NewSelect().Model((*Comment)(nil)).ColumnExpr("'comment' AS kind, id, body AS text, created_at"),
+	)
+	require.NoError(t, err, "Failed to build the union")
+	activities, total, err = bunql.ExecuteUnion[Activity](ctx, union)
+	require.NoError(t, err, "Union failed")
+	require.Equal(t, 4, total)
+	require.Len(t, activities, 1)
+	require.Equal(t, "Hello", activities[0].Text)
+
+	// A union needs queries to select from
+	_, err = ql.Union(ctx)
+	require.ErrorIs(t, err, bunql.ErrEmptyUnion)
+}
@@ -2,6 +2,9 @@ package e2e
 
 import (
 	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/fxnoob/bunql"
@@ -113,3 +116,72 @@ func TestFieldValidation(t *testing.T) {
 		require.Contains(t, err.Error(), "filter field 'email' is not allowed")
 	})
 }
+
+// TestFieldPatternValidation tests allowed fields given as patterns
+func TestFieldPatternValidation(t *testing.T) {
+	allowed := []string{"address_*", "profile.*", "age"}
+	parse := func(field string) error {
+		filterJSON := `{"filters": [{"field": "` + field + `", "operator": "eq", "value": "x"}]}`
+		_, err := bunql.ParseFromParamsWithAllowedFields(filterJSON, "", 0, 0, allowed, nil)
+		return err
+	}
+
+	for _, field := range []string{"address_city", "address_zip_code", "profile.city", "profile.zip_code", "age"} {
+		require.NoError(t, parse(field), field)
+	}
+	for _, field := range []string{"address", "home_address_city", "profiles", "profile", "email", "address_city OR 1=1"} {
+		require.EqualError(t, parse(field), "filter field '"+field+"' is not allowed", field)
+	}
+
+	// Sort fields and scopes take patterns too
+	_, err := bunql.ParseFromParamsWithAllowedFields("", "address_city:asc", 0, 0, nil, []string{"address_*"})
+	require.NoError(t, err)
+	_, err = bunql.ParseFromParamsWithAllowedFields("", "age:asc", 0, 0, nil, []string{"address_*"})
+	require.EqualError(t, err, "sort field 'age' is not allowed")
+
+	ql := bunql.NewWithAllowedFields(allowed, nil).WithFilterScope("address", "address_*")
+	scoped := `{"groups": [{"scope": "address", "filters": [{"field": "%s", "operator": "eq", "value": "x"}]}]}`
+	_, err = ql.ParseRequest(httptest.NewRequest("GET", "/?filter="+url.QueryEscape(fmt.Sprintf(scoped, "address_city")), nil))
+	require.NoError(t, err)
+	_, err = ql.ParseRequest(httptest.NewRequest("GET", "/?filter="+url.QueryEscape(fmt.Sprintf(scoped, "profile.city")), nil))
+	require.EqualError(t, err, "filter field 'profile.city' is not allowed in scope 'address'")
+}
+
+// TestDeniedFieldValidation tests that denied fields are rejected, even when allowed
+func TestDeniedFieldValidation(t *testing.T) {
+	filterJSON := func(field string) string {
+		return `{"filters": [{"field": "` + field + `", "operator": "eq", "value": "x"}]}`
+	}
+	parse := func(ql *bunql.BunQL, filter, sort string) error {
+		target := "/?filter=" + url.QueryEscape(filter) + "&sort=" + url.QueryEscape(sort)
+		_, err := ql.ParseRequest(httptest.NewRequest("GET", target, nil))
+		return err
+	}
+
+	// Everything except the denied fields
+	ql := bunql.New().WithDeniedFields([]string{"password_hash", "secret_*"}, []string{"ssn"})
+	require.NoError(t, parse(ql, filterJSON("email"), "age:asc"))
+	require.EqualError(t, parse(ql, filterJSON("password_hash"), ""), "filter field 'password_hash' is not allowed")
+	require.EqualError(t, parse(ql, filterJSON("secret_token"), ""), "filter field 'secret_token' is not allowed")
+	require.EqualError(t, parse(ql, "", "ssn:desc"), "sort field 'ssn' is not allowed")
+
+	// Denied fields can't be reached with another case or a table qualifier
+	require.EqualError(t, parse(ql, filterJSON("PASSWORD_HASH"), ""), "filter field 'PASSWORD_HASH' is not allowed")
+	require.EqualError(t, parse(ql, filterJSON("u.password_hash"), ""), "filter field 'u.password_hash' is not allowed")
+	require.EqualError(t, parse(ql, filterJSON("u.Secret_Token"), ""), "filter field 'u.Secret_Token' is not allowed")
+	require.EqualError(t, parse(ql, "", "u.SSN:desc"), "sort field 'u.SSN' is not allowed")
+
+	// Denied fields take precedence over the allowed ones
+	var denied []bunql.DeniedField
+	ql = bunql.NewWithAllowedFields([]string{"address_*"}, nil).
+		WithDeniedFields([]string{"address_private"}, nil).
+		WithDeniedFieldHook(func(_ context.Context, d bunql.DeniedField) { denied = append(denied, d) })
+	require.NoError(t, parse(ql, filterJSON("address_city"), ""))
+	require.EqualError(t, parse(ql, filterJSON("address_private"), ""), "filter field 'address_private' is not allowed")
+	require.Len(t, denied, 1)
+	require.Equal(t, "address_private", denied[0].Field)
+
+	// Policies carry denied fields
+	ql = bunql.NewWithPolicy(bunql.Policy{DeniedFilterFields: []string{"password_hash"}})
+	require.EqualError(t, parse(ql, filterJSON("password_hash"), ""), "filter field 'password_hash' is not allowed")
+}
@@ -0,0 +1,41 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnindexedFieldWarnings tests that filtering or sorting on unindexed fields raises warnings
+func TestUnindexedFieldWarnings(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+
+	filterJSON := `{
+		"filters": [{"field": "id", "operator": "gt", "value": 1}],
+		"groups": [{"logic": "or", "filters": [
+			{"field": "category", "operator": "eq", "value": "a"},
+			{"field": "price", "operator": "gt", "value": 20}
+		]}]
+	}`
+	sortJSON := `[{"field": "price", "dir": "asc"}, {"field": "name", "dir": "asc"}]`
+
+	ql, err := bunql.ParseFromParams(filterJSON, sortJSON, 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	var warnings []bunql.Warning
+	ql.WithIndexedFields("id", "category").WithWarningHook(func(ctx context.Context, warning bunql.Warning) {
+		warnings = append(warnings, warning)
+	})
+
+	ql.Apply(ctx, db.NewSelect().Model((*Item)(nil)))
+
+	require.Equal(t, []bunql.Warning{
+		{Code: bunql.WarningUnindexedField, Field: "price", Message: "filtering on field 'price', which has no index"},
+		{Code: bunql.WarningUnindexedField, Field: "name", Message: "sorting on field 'name', which has no index"},
+	}, warnings)
+}
@@ -0,0 +1,118 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/require"
+)
+
+// RankedItem is an item with the window columns of the test
+type RankedItem struct {
+	Item `bun:",extend"`
+
+	CategoryRank int  `bun:"category_rank,scanonly"`
+	RowNumber    int  `bun:"row_number,scanonly"`
+	PrevPrice    *int `bun:"prev_price,scanonly"`
+}
+
+// TestWindowColumns tests that window columns are computed over the filtered rows
+func TestWindowColumns(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 6)
+
+	ql, err := bunql.ParseFromParams(`{"filters": [{"field": "price", "operator": "gt", "value": 10}]}`, "price:desc", 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+
+	ql.WithWindowColumns(
+		bunql.WindowColumn{Alias: "category_rank", Function: bunql.WindowRank, PartitionBy: []string{"category"}},
+		bunql.WindowColumn{Alias: "row_number", Function: bunql.WindowRowNumber},
+		bunql.WindowColumn{
+			Alias:    "prev_price",
+			Function: bunql.WindowLag,
+			Field:    "price",
+			OrderBy:  []dto.SortField{{Field: "price", Direction: "asc"}},
+		},
+	)
+
+	var items []RankedItem
+	err = ql.Apply(ctx, db.NewSelect().Model(&items)).Scan(ctx)
+	require.NoError(t, err, "Query failed")
+
+	// Items 2 to 6, priced 20 to 60, alternate between the categories b and a
+	require.Len(t, items, 5)
+	expected := []struct {
+		name         string
+		categoryRank int
+		rowNumber    int
+		prevPrice    int
+	}{
+		{"Item6", 1, 1, 50},
+		{"Item5", 1, 2, 40},
+		{"Item4", 2, 3, 30},
+		{"Item3", 2, 4, 20},
+		{"Item2", 3, 5, 0},
+	}
+	for i, e := range expected {
+		require.Equal(t, e.name, items[i].Name)
+		require.Equal(t, e.categoryRank, items[i].CategoryRank, e.name)
+		require.Equal(t, e.rowNumber, items[i].RowNumber, e.name)
+		if e.prevPrice == 0 {
+			require.Nil(t, items[i].PrevPrice, e.name)
+		} else {
+			require.Equal(t, e.prevPrice, *items[i].PrevPrice, e.name)
+		}
+	}
+
+	// The count query ignores the window columns
+	_, countQuery := ql.ApplyWithCount(ctx, db.NewSelect().Model((*Item)(nil)))
+	count, err := countQuery.Count(ctx)
+	require.NoError(t, err, "Count failed")
+	require.Equal(t, 5, count)
+}
+
+// TestWindowColumnsSelectedFields tests that window columns are added to the selected fields, not to every column
+func TestWindowColumnsSelectedFields(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 4)
+
+	ql, err := bunql.ParseFromParams("", "price:desc", 0, 0)
+	require.NoError(t, err, "Failed to parse parameters")
+	ql.WithSelectedFields("id", "name").
+		WithWindowColumns(bunql.WindowColumn{Alias: "row_number", Function: bunql.WindowRowNumber})
+
+	var items []RankedItem
+	query := ql.Apply(ctx, db.NewSelect().Model(&items))
+	require.Equal(t, `SELECT "i"."id", "i"."name", ROW_NUMBER() OVER (ORDER BY "price" DESC) AS "row_number" FROM "items" AS "i" ORDER BY price DESC`,
+		query.String())
+	require.NoError(t, query.Scan(ctx), "Query failed")
+	require.Len(t, items, 4)
+	require.Equal(t, "Item4", items[0].Name)
+	require.Equal(t, 1, items[0].RowNumber)
+	require.Zero(t, items[0].Price)
+}
+
+// TestWindowColumnsInvalid tests that misconfigured window columns fail the execution instead of being left out
+func TestWindowColumnsInvalid(t *testing.T) {
+	// Get database connection
+	db = GetDB()
+
+	ctx := context.Background()
+	seedItems(t, ctx, 2)
+
+	ql := bunql.New().WithWindowColumns(bunql.WindowColumn{Alias: "category_rank", Function: "rnak"})
+	_, _, err := bunql.ExecutePage[RankedItem](ctx, ql, db.NewSelect().Model((*RankedItem)(nil)))
+	require.EqualError(t, err, "invalid window function 'rnak' of column 'category_rank'")
+
+	ql = bunql.New().WithWindowColumns(bunql.WindowColumn{Alias: "prev_price", Function: bunql.WindowLag})
+	_, _, err = bunql.ExecutePage[RankedItem](ctx, ql, db.NewSelect().Model((*RankedItem)(nil)))
+	require.EqualError(t, err, "window function 'lag' of column 'prev_price' requires a field")
+}
@@ -0,0 +1,76 @@
+package bunql
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/operator"
+	"sort"
+)
+
+// FilterDoc documents the filters accepted on a field, e.g. for self-documenting endpoints such as GET /users/_filters
+type FilterDoc struct {
+	Field     string        `json:"field"`
+	Type      dto.FieldType `json:"type,omitempty"`
+	Operators []OperatorDoc `json:"operators"`
+}
+
+// OperatorDoc documents an operator accepted on a field, with an example filter parameter
+type OperatorDoc struct {
+	Operator    string `json:"operator"`
+	Description string `json:"description"`
+	Value       string `json:"value"`   // Shape of the value, e.g. "a list of values"
+	Example     string `json:"example"` // Filter parameter using the operator on the field
+}
+
+// FilterDocs documents the filters accepted by the BunQL: one entry per allowed filter field, or per configured
+// field when any field is allowed, with the operators applying to the type of the field. Denied fields are left out
+func (q *BunQL) FilterDocs() []FilterDoc {
+	fields := q.AllowedFilterFields
+	if len(fields) == 0 {
+		for field := range q.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+	}
+
+	docs := make([]FilterDoc, 0, len(fields))
+	for _, field := range fields {
+		if deniesField(q.DeniedFilterFields, field) {
+			continue
+		}
+		cfg := q.Fields[field]
+		doc := FilterDoc{Field: field, Type: cfg.Type, Operators: []OperatorDoc{}}
+		for _, example := range operator.Examples(field, cfg.Type, cfg.Operators...) {
+			// The hierarchy operators only apply to the keys of self-referencing tables, configured with a parent
+			if isHierarchyOperator(example.Operator) && cfg.Parent == "" && len(cfg.Operators) == 0 {
+				continue
+			}
+			metadata, _ := operator.GetMetadata(example.Operator)
+			payload, err := ParseMultipleFilterParams([]Filter{example}, "and")
+			if err != nil {
+				continue
+			}
+			doc.Operators = append(doc.Operators, OperatorDoc{
+				Operator:    example.Operator,
+				Description: metadata.Description,
+				Value:       metadata.Arity.String(),
+				Example:     payload,
+			})
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// FilterDocsFor documents the filters accepted by the query policy registered for the model T
+func FilterDocsFor[T any]() ([]FilterDoc, error) {
+	ql, err := NewFor[T]()
+	if err != nil {
+		return nil, err
+	}
+	return ql.FilterDocs(), nil
+}
+
+// isHierarchyOperator reports whether an operator filters the nodes of a tree
+func isHierarchyOperator(op string) bool {
+	return op == "descendantof" || op == "ancestorof"
+}
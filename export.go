@@ -0,0 +1,197 @@
+package bunql
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ExportCSV streams the results of the query to w as CSV, writing a header row followed by one record per row
+// Only the given columns are exported, in the given order; rows are read one at a time so memory stays bounded
+// regardless of the size of the result set
+func ExportCSV(ctx context.Context, w io.Writer, query *bun.SelectQuery, columns []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	record := make([]string, len(columns))
+	err := exportRows(ctx, query, columns, func(values []interface{}) error {
+		for i, value := range values {
+			record[i] = formatCSVValue(value)
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportNDJSON streams the results of the query to w as newline-delimited JSON, writing one object per row
+// Only the given columns are exported, in the given order; rows are read one at a time so memory stays bounded
+// regardless of the size of the result set
+func ExportNDJSON(ctx context.Context, w io.Writer, query *bun.SelectQuery, columns []string) error {
+	writer := bufio.NewWriter(w)
+
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return fmt.Errorf("failed to marshal column name: %w", err)
+		}
+		keys[i] = key
+	}
+
+	var line []byte
+	err := exportRows(ctx, query, columns, func(values []interface{}) error {
+		line = append(line[:0], '{')
+		for i, value := range values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, keys[i]...)
+			line = append(line, ':')
+
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			jsonValue, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to marshal column '%s': %w", columns[i], err)
+			}
+			line = append(line, jsonValue...)
+		}
+		line = append(line, '}', '\n')
+
+		_, err := writer.Write(line)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// exportRows executes the query and calls write with the values of the exported columns for every row
+func exportRows(ctx context.Context, query *bun.SelectQuery, columns []string, write func(values []interface{}) error) error {
+	if len(columns) == 0 {
+		return errors.New("at least one column must be exported")
+	}
+
+	query, err := exportQuery(query, columns)
+	if err != nil {
+		return err
+	}
+
+	rows, err := query.Rows(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to execute export query: %w", err)
+	}
+	defer rows.Close()
+
+	resultColumns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to read result columns: %w", err)
+	}
+
+	// Map each exported column to its position in the result set
+	positions := make([]int, len(columns))
+	for i, column := range columns {
+		positions[i] = -1
+		for j, resultColumn := range resultColumns {
+			if resultColumn == column {
+				positions[i] = j
+				break
+			}
+		}
+		if positions[i] < 0 {
+			return fmt.Errorf("export column '%s' is not selected by the query", column)
+		}
+	}
+
+	raw := make([]interface{}, len(resultColumns))
+	dest := make([]interface{}, len(resultColumns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	values := make([]interface{}, len(columns))
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan export row: %w", err)
+		}
+		for i, position := range positions {
+			values[i] = raw[position]
+		}
+		if err := write(values); err != nil {
+			return fmt.Errorf("failed to write export row: %w", err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// exportQuery restricts a query selecting every column of its model to the exported columns, so the other columns
+// aren't read from the database. Queries selecting their own columns are left as is
+func exportQuery(query *bun.SelectQuery, columns []string) (*bun.SelectQuery, error) {
+	model, ok := query.GetModel().(bun.TableModel)
+	if !ok || selectsColumns(query) {
+		return query, nil
+	}
+
+	table := model.Table()
+	for _, column := range columns {
+		if !table.HasField(column) {
+			return nil, fmt.Errorf("export column '%s' is not selected by the query", column)
+		}
+		query = query.ColumnExpr("?TableAlias.?", bun.Ident(column))
+	}
+	return query, nil
+}
+
+// selectsColumns reports whether columns were added to a query, from the columns bun keeps on it, assuming they
+// were if bun doesn't keep them where expected
+func selectsColumns(query *bun.SelectQuery) bool {
+	if columns := reflect.ValueOf(query).Elem().FieldByName("columns"); columns.Kind() == reflect.Slice {
+		return columns.Len() > 0
+	}
+	return true
+}
+
+// formatCSVValue formats a value scanned from the database as a CSV field
+// Text starting with a character spreadsheets read as the start of a formula is prefixed with a quote, so opening
+// the export doesn't evaluate the values users stored, e.g. =HYPERLINK(...)
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return escapeFormula(string(v))
+	case string:
+		return escapeFormula(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeFormula prefixes text starting with =, +, -, @, a tab or a carriage return with a quote
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package expr
+
+import (
+	"encoding/json"
+	"github.com/uptrace/bun"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// decimalPattern matches the decimal numbers accepted for decimal fields, e.g. "10.10" or "-0.5"
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// Decimal returns the plain decimal form of a value for a decimal field: a decimal string, a json.Number or a
+// Go number. Floating point numbers use the shortest form that reads back as the same number, e.g. 10.1
+func Decimal(value interface{}) (string, bool) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case json.Number:
+		s = v.String()
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return "", false
+		}
+		s = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		rv := reflect.ValueOf(value)
+		switch {
+		case rv.CanInt():
+			s = strconv.FormatInt(rv.Int(), 10)
+		case rv.CanUint():
+			s = strconv.FormatUint(rv.Uint(), 10)
+		default:
+			return "", false
+		}
+	}
+
+	if !decimalPattern.MatchString(s) {
+		return "", false
+	}
+	return strings.TrimPrefix(s, "+"), true
+}
+
+// DecimalDigits returns the number of significant digits and of digits after the decimal point of a decimal
+// returned by Decimal, ignoring leading zeros of the integer part and trailing zeros of the fractional part
+func DecimalDigits(decimal string) (precision, scale int) {
+	decimal = strings.TrimPrefix(decimal, "-")
+	integer, fraction, _ := strings.Cut(decimal, ".")
+	integer = strings.TrimLeft(integer, "0")
+	fraction = strings.TrimRight(fraction, "0")
+	return len(integer) + len(fraction), len(fraction)
+}
+
+// decimalValue binds the values of a decimal field as SQL decimal literals rather than floating point numbers,
+// so that 10.10 is compared exactly with a DECIMAL or NUMERIC column. Values that are not decimals are left as is
+func decimalValue(value interface{}) interface{} {
+	switch values := value.(type) {
+	case []interface{}:
+		literals := make([]interface{}, len(values))
+		for i, v := range values {
+			literals[i] = decimalValue(v)
+		}
+		return literals
+	case []string:
+		literals := make([]interface{}, len(values))
+		for i, v := range values {
+			literals[i] = decimalValue(v)
+		}
+		return literals
+	}
+
+	if decimal, ok := Decimal(value); ok {
+		// The decimal only has digits, a sign and a point, so it is safe to inline
+		return bun.Safe(decimal)
+	}
+	return value
+}
@@ -0,0 +1,199 @@
+package expr
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+	"regexp"
+	"strings"
+)
+
+// Accent-insensitive collations used to fold accents on dialects without unaccent()
+const (
+	mysqlUnaccentCollation = "utf8mb4_0900_ai_ci"
+	mssqlUnaccentCollation = "Latin1_General_CI_AI"
+)
+
+// Case-sensitive collations used on the dialects comparing strings case-insensitively by default
+const (
+	mysqlCaseSensitiveCollation         = "utf8mb4_bin"
+	mssqlCaseSensitiveCollation         = "Latin1_General_CS_AS"
+	mssqlCaseSensitiveUnaccentCollation = "Latin1_General_CS_AI"
+)
+
+// likeEscaper escapes the LIKE wildcards of a string, using '!' as the escape character since it needs no escaping
+// in string literals on any dialect
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// EscapeLike escapes the LIKE wildcards of a string so that it matches literally in a pattern compared with
+// ESCAPE '!', e.g. "50%" in "%50!%%"
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// accents maps the accented Latin letters folded by the generic unaccent fallback to their base letter
+var accents = [][2]string{
+	{"á", "a"}, {"à", "a"}, {"â", "a"}, {"ä", "a"}, {"ã", "a"}, {"å", "a"},
+	{"é", "e"}, {"è", "e"}, {"ê", "e"}, {"ë", "e"},
+	{"í", "i"}, {"ì", "i"}, {"î", "i"}, {"ï", "i"},
+	{"ó", "o"}, {"ò", "o"}, {"ô", "o"}, {"ö", "o"}, {"õ", "o"},
+	{"ú", "u"}, {"ù", "u"}, {"û", "u"}, {"ü", "u"},
+	{"ñ", "n"}, {"ç", "c"}, {"ý", "y"}, {"ÿ", "y"},
+	{"Á", "A"}, {"À", "A"}, {"Â", "A"}, {"Ä", "A"}, {"Ã", "A"}, {"Å", "A"},
+	{"É", "E"}, {"È", "E"}, {"Ê", "E"}, {"Ë", "E"},
+	{"Í", "I"}, {"Ì", "I"}, {"Î", "I"}, {"Ï", "I"},
+	{"Ó", "O"}, {"Ò", "O"}, {"Ô", "O"}, {"Ö", "O"}, {"Õ", "O"},
+	{"Ú", "U"}, {"Ù", "U"}, {"Û", "U"}, {"Ü", "U"},
+	{"Ñ", "N"}, {"Ç", "C"}, {"Ý", "Y"},
+}
+
+// accentReplacer strips the accents of the accents table from Go strings
+var accentReplacer = func() *strings.Replacer {
+	pairs := make([]string, 0, 2*len(accents))
+	for _, accent := range accents {
+		pairs = append(pairs, accent[0], accent[1])
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// collationPattern matches the collation names that can be safely inlined in SQL
+var collationPattern = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+// Column returns the SQL expression of a field, applying the accent folding, case folding and collation of its
+// configuration. Accents are folded with unaccent() on Postgres, an accent-insensitive collation on MySQL and MSSQL
+// (unless a collation is configured) and a chain of REPLACE() calls over common Latin accents elsewhere
+// Case-insensitive fields are compared with LOWER(), and case-sensitive ones with a binary or case-sensitive
+// collation on MySQL and MSSQL, where strings compare case-insensitively by default
+func Column(d dialect.Name, field string, cfg dto.FieldConfig) schema.QueryAppender {
+	return ColumnExpr(d, bun.Ident(field), cfg)
+}
+
+// ColumnExpr returns the SQL expression of a field standing for an expression, e.g. a computed column, applying its
+// configuration like Column
+func ColumnExpr(d dialect.Name, column schema.QueryAppender, cfg dto.FieldConfig) schema.QueryAppender {
+	if cfg.Unaccent {
+		switch d {
+		case dialect.PG:
+			column = schema.SafeQuery("unaccent(?)", []interface{}{column})
+		case dialect.MySQL, dialect.MSSQL:
+			// Folded by the collation below
+		default:
+			column = replaceAccents(column)
+		}
+	}
+
+	if IsCaseInsensitive(cfg) {
+		column = schema.SafeQuery("LOWER(?)", []interface{}{column})
+	}
+
+	if collation := collationFor(d, cfg); collation != nil {
+		column = schema.SafeQuery("? COLLATE ?", []interface{}{column, collation})
+	}
+
+	return column
+}
+
+// Value returns the SQL expression of a value compared with a field, applying the accent and case folding of its
+// configuration. Only strings, and the strings of arrays, are affected; on the dialects folding accents with a
+// collation the accents are left untouched. The values of decimal fields are bound as decimal literals
+func Value(d dialect.Name, cfg dto.FieldConfig, value interface{}) interface{} {
+	if cfg.Type == dto.FieldDecimal {
+		return decimalValue(value)
+	}
+	if !cfg.Unaccent && !IsCaseInsensitive(cfg) {
+		return value
+	}
+
+	switch values := value.(type) {
+	case []interface{}:
+		folded := make([]interface{}, len(values))
+		for i, v := range values {
+			folded[i] = Value(d, cfg, v)
+		}
+		return folded
+	case []string:
+		folded := make([]interface{}, len(values))
+		for i, v := range values {
+			folded[i] = Value(d, cfg, v)
+		}
+		return folded
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if IsCaseInsensitive(cfg) {
+		str = strings.ToLower(str)
+	}
+	if !cfg.Unaccent {
+		return str
+	}
+
+	switch d {
+	case dialect.PG:
+		return schema.SafeQuery("unaccent(?)", []interface{}{str})
+	case dialect.MySQL, dialect.MSSQL:
+		return str
+	default:
+		return accentReplacer.Replace(str)
+	}
+}
+
+// IsPlain reports whether the configuration leaves the field expression untouched
+func IsPlain(cfg dto.FieldConfig) bool {
+	return !cfg.Unaccent && cfg.Collation == "" && cfg.CaseSensitive == nil
+}
+
+// IsCaseSensitive reports whether the field is configured to be compared case-sensitively
+func IsCaseSensitive(cfg dto.FieldConfig) bool {
+	return cfg.CaseSensitive != nil && *cfg.CaseSensitive
+}
+
+// IsCaseInsensitive reports whether the field is configured to be compared case-insensitively
+func IsCaseInsensitive(cfg dto.FieldConfig) bool {
+	return cfg.CaseSensitive != nil && !*cfg.CaseSensitive
+}
+
+// collationFor returns the collation applied to a field, or nil if there is none
+func collationFor(d dialect.Name, cfg dto.FieldConfig) schema.QueryAppender {
+	collation := cfg.Collation
+	if collation == "" && cfg.Unaccent {
+		switch d {
+		case dialect.MySQL:
+			// MySQL has no accent-insensitive and case-sensitive collation, so folding accents wins
+			collation = mysqlUnaccentCollation
+		case dialect.MSSQL:
+			collation = mssqlUnaccentCollation
+			if IsCaseSensitive(cfg) {
+				collation = mssqlCaseSensitiveUnaccentCollation
+			}
+		}
+	} else if collation == "" && IsCaseSensitive(cfg) {
+		switch d {
+		case dialect.MySQL:
+			collation = mysqlCaseSensitiveCollation
+		case dialect.MSSQL:
+			collation = mssqlCaseSensitiveCollation
+		}
+	}
+
+	if collation == "" || !collationPattern.MatchString(collation) {
+		return nil
+	}
+
+	// Postgres collations are identifiers, e.g. "und-x-icu", while the other dialects take bare names
+	if d == dialect.PG {
+		return bun.Ident(collation)
+	}
+	return bun.Safe(collation)
+}
+
+// replaceAccents wraps the column in REPLACE() calls folding the accents of the accents table
+func replaceAccents(column schema.QueryAppender) schema.QueryAppender {
+	for _, accent := range accents {
+		column = schema.SafeQuery("REPLACE(?, ?, ?)", []interface{}{column, accent[0], accent[1]})
+	}
+	return column
+}
@@ -0,0 +1,116 @@
+package filter
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/expr"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/schema"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// applyQuantified applies the ANY and ALL operators, matching when the comparison holds for any or for all the values
+// of a list. Postgres compares the column with an array (col = ANY('{1,2}')), which keeps the statement short for
+// long lists; other dialects, and lists mixing value types, expand to comparisons joined with OR or AND
+// As with in and notin, an empty list matches nothing for ANY and everything for ALL
+func applyQuantified(query *bun.SelectQuery, field string, column schema.QueryAppender, comparison string, all bool, value interface{}, opts Options) *bun.SelectQuery {
+	values := listValues(value)
+	if len(values) == 0 {
+		if all {
+			return query.Where("1 = 1")
+		}
+		return query.Where("1 = 0")
+	}
+
+	quantifier, separator := "ANY", " OR "
+	if all {
+		quantifier, separator = "ALL", " AND "
+	}
+
+	cfg := opts.Fields[field]
+	if opts.dialectName(query) == dialect.PG && expr.IsPlain(cfg) && cfg.Type != dto.FieldDecimal {
+		if array, ok := typedArray(values); ok {
+			return query.Where("? "+comparison+" "+quantifier+" (?)", column, opts.sensitive(field, pgdialect.Array(array)))
+		}
+	}
+
+	condition := comparisonQueries[comparison]
+	terms := make([]string, len(values))
+	args := make([]interface{}, 0, 2*len(values))
+	for i, v := range values {
+		terms[i] = condition
+		args = append(args, column, opts.bind(query, field, v))
+	}
+	return query.Where(strings.Join(terms, separator), args...)
+}
+
+// typedArray converts a list of strings, numbers or booleans to a slice of that type, as arrays are typed
+// It reports false for lists mixing types or holding other values, such as NULL
+func typedArray(values []interface{}) (interface{}, bool) {
+	switch values[0].(type) {
+	case string:
+		strs := make([]string, len(values))
+		for i, v := range values {
+			str, ok := v.(string)
+			if !ok {
+				return nil, false
+			}
+			strs[i] = str
+		}
+		return strs, true
+	case bool:
+		bools := make([]bool, len(values))
+		for i, v := range values {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, false
+			}
+			bools[i] = b
+		}
+		return bools, true
+	}
+
+	return numberArray(values)
+}
+
+// maxExactFloat is the largest integer from which on float64 can't represent every integer exactly, 2^53
+const maxExactFloat = 1 << 53
+
+// numberArray converts a list of numbers to a slice of int64 when they are all integers, of uint64 when some are
+// unsigned integers above the int64 range, and of float64 otherwise. It reports false for integers that can't be
+// represented exactly in the slice, e.g. 2^53 + 1 among floating point numbers, so that the list is expanded instead
+func numberArray(values []interface{}) (interface{}, bool) {
+	ints := make([]int64, len(values))
+	floats := make([]float64, len(values))
+	uints := make([]uint64, len(values))
+	hasFloat, hasBigUint, exactFloats, nonNegative := false, false, true, true
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		switch {
+		case rv.CanFloat():
+			hasFloat, floats[i] = true, rv.Float()
+		case rv.CanInt():
+			ints[i], floats[i], uints[i] = rv.Int(), float64(rv.Int()), uint64(rv.Int())
+			exactFloats = exactFloats && ints[i] <= maxExactFloat && ints[i] >= -maxExactFloat
+			nonNegative = nonNegative && ints[i] >= 0
+		case rv.CanUint():
+			uints[i], floats[i], ints[i] = rv.Uint(), float64(rv.Uint()), int64(rv.Uint())
+			hasBigUint = hasBigUint || rv.Uint() > math.MaxInt64
+			exactFloats = exactFloats && rv.Uint() <= maxExactFloat
+		default:
+			return nil, false
+		}
+	}
+
+	switch {
+	case hasFloat:
+		return floats, exactFloats && !hasBigUint
+	case hasBigUint:
+		return uints, nonNegative
+	default:
+		return ints, true
+	}
+}
@@ -0,0 +1,177 @@
+package filter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLocale is how the dates in filter values are read, as numeric dates are ambiguous: 01/02/2024 is the 2nd of
+// January in the United States and the 1st of February in most of Europe
+type DateLocale struct {
+	// DayFirst reads numeric dates with the day first (31/01/2024, 31.01.2024) rather than the month first
+	// (01/31/2024); dates with dashes (31-01-2024) are always read with the day first
+	DayFirst bool
+	// Months maps lowercase month names and abbreviations to months, in addition to the English ones
+	Months map[string]time.Month
+}
+
+// englishMonths are the month names recognized with every locale
+var englishMonths = monthNames(
+	"january", "february", "march", "april", "may", "june",
+	"july", "august", "september", "october", "november", "december")
+
+// Locales are the built-in date locales, by language or language-region tag
+var Locales = map[string]DateLocale{
+	"en-US": {},
+	"en":    {DayFirst: true},
+	"de": {DayFirst: true, Months: monthNames(
+		"januar", "februar", "märz", "april", "mai", "juni",
+		"juli", "august", "september", "oktober", "november", "dezember")},
+	"fr": {DayFirst: true, Months: monthNames(
+		"janvier", "février", "mars", "avril", "mai", "juin",
+		"juillet", "août", "septembre", "octobre", "novembre", "décembre")},
+	"es": {DayFirst: true, Months: monthNames(
+		"enero", "febrero", "marzo", "abril", "mayo", "junio",
+		"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre")},
+	"it": {DayFirst: true, Months: monthNames(
+		"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+		"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre")},
+	"nl": {DayFirst: true, Months: monthNames(
+		"januari", "februari", "maart", "april", "mei", "juni",
+		"juli", "augustus", "september", "oktober", "november", "december")},
+	"pt": {DayFirst: true, Months: monthNames(
+		"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+		"julho", "agosto", "setembro", "outubro", "novembro", "dezembro")},
+}
+
+// Locale returns the built-in date locale of a tag such as "en-GB" or "de-DE", falling back to the language
+// The second result is false for unknown languages, for which the zero DateLocale (month first) applies
+func Locale(tag string) (DateLocale, bool) {
+	tag = strings.ReplaceAll(tag, "_", "-")
+	for key, locale := range Locales {
+		if strings.EqualFold(key, tag) {
+			return locale, true
+		}
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	locale, ok := Locales[strings.ToLower(language)]
+	return locale, ok
+}
+
+// monthNames maps the names of the twelve months, and their first three letters, to the months
+// Abbreviations shared by two months, such as "jui" for juin and juillet, are left out
+func monthNames(names ...string) map[string]time.Month {
+	months := make(map[string]time.Month, 2*len(names))
+	abbreviations := map[string]time.Month{}
+	for i, name := range names {
+		months[name] = time.Month(i + 1)
+		abbreviation := string([]rune(name)[:3])
+		if m, ok := abbreviations[abbreviation]; ok && m != time.Month(i+1) {
+			abbreviations[abbreviation] = 0
+			continue
+		}
+		abbreviations[abbreviation] = time.Month(i + 1)
+	}
+	for abbreviation, m := range abbreviations {
+		if _, ok := months[abbreviation]; !ok && m != 0 {
+			months[abbreviation] = m
+		}
+	}
+	return months
+}
+
+// month returns the month of a lowercase month name or abbreviation
+func (l DateLocale) month(name string) (time.Month, bool) {
+	name = strings.TrimSuffix(name, ".")
+	if m, ok := l.Months[name]; ok {
+		return m, true
+	}
+	m, ok := englishMonths[name]
+	return m, ok
+}
+
+// isoDatePattern matches ISO 8601 dates and date-times, which are passed to the database unchanged
+var isoDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2})?`)
+
+// numericDatePattern matches the other numeric dates: YYYY/MM/DD, and DD/MM/YYYY or MM/DD/YYYY with slashes, dots or dashes
+var numericDatePattern = regexp.MustCompile(`^(\d{1,4})([/.-])(\d{1,2})([/.-])(\d{1,4})$`)
+
+// IsDate reports whether a string is a date or a date-time the date filters read in the locale, such as 2024-01-31,
+// 2024-01-31T10:00:00Z, 31/01/2024 with DayFirst or "31 January 2024"
+func (l DateLocale) IsDate(s string) bool {
+	if isoDatePattern.MatchString(s) {
+		for _, layout := range []string{time.DateOnly, time.RFC3339, "2006-01-02T15:04:05"} {
+			if _, err := time.Parse(layout, s); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	_, ok := l.dateValue(s)
+	return ok
+}
+
+// dateValue returns the ISO 8601 form of a date string read in the locale, reporting false if the string is not a date
+// ISO 8601 dates and date-times are returned unchanged, and impossible dates such as 02/30/2024 are not dates
+func (l DateLocale) dateValue(s string) (string, bool) {
+	if isoDatePattern.MatchString(s) {
+		return s, true
+	}
+
+	var year, month, day int
+	if parts := numericDatePattern.FindStringSubmatch(s); parts != nil {
+		if parts[2] != parts[4] {
+			return "", false
+		}
+		first, _ := strconv.Atoi(parts[1])
+		second, _ := strconv.Atoi(parts[3])
+		third, _ := strconv.Atoi(parts[5])
+		switch {
+		case len(parts[1]) == 4 && len(parts[5]) <= 2:
+			year, month, day = first, second, third
+		case len(parts[5]) == 4 && len(parts[1]) <= 2 && (l.DayFirst || parts[2] == "-"):
+			year, month, day = third, second, first
+		case len(parts[5]) == 4 && len(parts[1]) <= 2:
+			year, month, day = third, first, second
+		default:
+			return "", false
+		}
+	} else if !l.textDate(s, &year, &month, &day) {
+		return "", false
+	}
+
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day {
+		return "", false
+	}
+	return t.Format(time.DateOnly), true
+}
+
+// textDate reads a date with a month name, such as "31 January 2024", "January 31, 2024" or "31. März 2024"
+func (l DateLocale) textDate(s string, year, month, day *int) bool {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ' ' || r == ',' })
+	if len(words) != 3 {
+		return false
+	}
+
+	for _, word := range words {
+		if m, ok := l.month(word); ok && *month == 0 {
+			*month = int(m)
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(word, "."))
+		switch {
+		case err != nil:
+			return false
+		case len(word) == 4 && *year == 0:
+			*year = n
+		case len(strings.TrimSuffix(word, ".")) <= 2 && *day == 0:
+			*day = n
+		default:
+			return false
+		}
+	}
+	return *year != 0 && *month != 0 && *day != 0
+}
@@ -5,133 +5,593 @@ import (
 	"errors"
 	"fmt"
 	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/expr"
 	"github.com/fxnoob/bunql/operator"
 	"github.com/uptrace/bun"
-	"regexp"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 )
 
+// DefaultSimilarityThreshold is the minimum trigram similarity matched by the similar operator, as in pg_trgm
+const DefaultSimilarityThreshold = 0.3
+
+// Options configures how filters are translated to SQL
+type Options struct {
+	// Dialect forces the SQL dialect filters are generated for; by default the dialect of the query is used
+	Dialect dialect.Name
+	// SimilarityThreshold is the minimum similarity matched by the similar operator (DefaultSimilarityThreshold if zero)
+	SimilarityThreshold float64
+	// Fields holds the per-field configuration, such as collation and accent folding
+	Fields map[string]dto.FieldConfig
+	// InListChunkSize is the maximum number of values in a single IN list (DefaultInListChunkSize if zero)
+	InListChunkSize int
+	// InListStrategy is how IN lists larger than InListChunkSize are compared
+	InListStrategy InListStrategy
+	// SymmetricBetween makes between match when the bounds are given in reverse order ([high, low])
+	SymmetricBetween bool
+	// Templates overrides the SQL generated for operators on some dialects, e.g. like with ILIKE on Postgres
+	Templates Templates
+	// DateLocale is how date strings are read, e.g. Locales["de"] for 31.01.2024; month first (01/31/2024) if zero
+	DateLocale DateLocale
+	// LikeMatch is how like values without wildcards are matched on fields without a match mode; contains if empty
+	LikeMatch dto.MatchMode
+	// Expressions holds the SQL expressions of the computed fields, e.g. "SUM(price)" for total, filtered on instead
+	// of a column of the same name
+	Expressions map[string]string
+}
+
+// dialectName returns the dialect filters are generated for
+func (o Options) dialectName(query *bun.SelectQuery) dialect.Name {
+	if o.Dialect != dialect.Invalid {
+		return o.Dialect
+	}
+	return query.Dialect().Name()
+}
+
+// column returns the SQL expression of a field according to its configuration
+func (o Options) column(query *bun.SelectQuery, field string) schema.QueryAppender {
+	return expr.ColumnExpr(o.dialectName(query), o.ident(field), o.Fields[field])
+}
+
+// ident returns the column of a field, or the expression of a computed field
+func (o Options) ident(field string) schema.QueryAppender {
+	if expression, ok := o.Expressions[field]; ok {
+		return bun.Safe("(" + expression + ")")
+	}
+	return bun.Ident(field)
+}
+
+// bind returns the SQL expression of a value compared with a field according to its configuration
+func (o Options) bind(query *bun.SelectQuery, field string, value interface{}) interface{} {
+	return o.sensitive(field, expr.Value(o.dialectName(query), o.Fields[field], value))
+}
+
+// withCaseSensitivity returns the options with the case sensitivity of a field overridden, leaving the
+// configuration of the other fields shared
+func (o Options) withCaseSensitivity(field string, caseSensitive bool) Options {
+	fields := make(map[string]dto.FieldConfig, len(o.Fields)+1)
+	for name, cfg := range o.Fields {
+		fields[name] = cfg
+	}
+	cfg := fields[field]
+	cfg.CaseSensitive = &caseSensitive
+	fields[field] = cfg
+	o.Fields = fields
+	return o
+}
+
+// similarityThreshold returns the configured similarity threshold or the default one
+func (o Options) similarityThreshold() float64 {
+	if o.SimilarityThreshold > 0 {
+		return o.SimilarityThreshold
+	}
+	return DefaultSimilarityThreshold
+}
+
+// ErrEmptyList is returned in strict mode when in or notin is given an empty list
+var ErrEmptyList = errors.New("empty list of values")
+
+// ErrNullInList is returned in strict mode when the list of in or notin holds a null
+var ErrNullInList = errors.New("null in list of values")
+
+// ParseOptions configures how filters are parsed and validated
+type ParseOptions struct {
+	// RejectEmptyLists makes in and notin with an empty list an error (ErrEmptyList) instead of matching nothing
+	// and everything respectively
+	RejectEmptyLists bool
+	// RejectNullsInLists makes in and notin with a null in their list an error (ErrNullInList) instead of matching
+	// the NULL values of the field and not matching them respectively
+	RejectNullsInLists bool
+}
+
+// ParseFilters parses and validates a filter group from JSON, or a bare array of filters joined with AND
 func ParseFilters(jsonStr string) (dto.FilterGroup, error) {
-	var group dto.FilterGroup
-	err := json.Unmarshal([]byte(jsonStr), &group)
+	return ParseFiltersWithOptions(jsonStr, ParseOptions{})
+}
+
+// ParseFiltersWithOptions parses and validates a filter group from JSON using the given options
+// The payload is decoded with the handler of the format version it declares (see RegisterFormat)
+func ParseFiltersWithOptions(jsonStr string, opts ParseOptions) (dto.FilterGroup, error) {
+	group, err := decodeFilters([]byte(jsonStr))
 	if err != nil {
 		return dto.FilterGroup{}, err
 	}
 
-	// Default to AND logic if not specified
-	if group.Logic == "" {
-		group.Logic = "and"
+	resolveAliases(group)
+	if err := validateFilterGroup(group, opts); err != nil {
+		return dto.FilterGroup{}, err
 	}
 
+	normalizeLogic(&group)
 	return group, nil
 }
 
+// ParseFilterList parses several filter parameters, each a filter group, a bare array of filters or a single filter,
+// and combines them with the given logic ("and" if empty). Empty parameters are skipped, and a single group is
+// returned as is
+func ParseFilterList(params []string, logic string) (dto.FilterGroup, error) {
+	combined := dto.FilterGroup{Logic: logic, Filters: []dto.Filter{}, Groups: []dto.FilterGroup{}}
+
+	for _, param := range params {
+		if strings.TrimSpace(param) == "" {
+			continue
+		}
+
+		// Single conditions are added to the combined group directly
+		var fields map[string]json.RawMessage
+		if json.Unmarshal([]byte(param), &fields) == nil && fields["field"] != nil {
+			var filter dto.Filter
+			if err := json.Unmarshal([]byte(param), &filter); err != nil {
+				return dto.FilterGroup{}, err
+			}
+			combined.Filters = append(combined.Filters, filter)
+			continue
+		}
+
+		group, err := ParseFilters(param)
+		if err != nil {
+			return dto.FilterGroup{}, err
+		}
+		combined.Groups = append(combined.Groups, group)
+	}
+
+	if len(combined.Filters) == 0 && len(combined.Groups) == 1 {
+		return combined.Groups[0], nil
+	}
+	resolveAliases(combined)
+	if err := validateFilterGroup(combined, ParseOptions{}); err != nil {
+		return dto.FilterGroup{}, err
+	}
+	normalizeLogic(&combined)
+	return combined, nil
+}
+
 // ApplyFilterGroup applies a filter group to the query
 func ApplyFilterGroup(query *bun.SelectQuery, group dto.FilterGroup) *bun.SelectQuery {
+	return ApplyFilterGroupWithOptions(query, group, Options{})
+}
+
+// ApplyFilterGroupWithOptions applies a filter group to the query using the given options
+// The filters and nested groups of a group are joined by the group logic, and the group is ANDed with the
+// other conditions of the query
+func ApplyFilterGroupWithOptions(query *bun.SelectQuery, group dto.FilterGroup, opts Options) *bun.SelectQuery {
 	if len(group.Filters) == 0 && len(group.Groups) == 0 {
 		return query
 	}
 
-	// Get the logic from the group
-	logic := strings.ToLower(group.Logic)
-	if logic == "" {
-		logic = "and"
+	return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		return applyGroupMembers(q, group, opts)
+	})
+}
+
+// applyGroupMembers applies the filters and the nested groups of a group, joined by the group logic
+func applyGroupMembers(query *bun.SelectQuery, group dto.FilterGroup, opts Options) *bun.SelectQuery {
+	separator := groupSeparator(group)
+
+	// Apply all direct filters in this group
+	for _, filter := range group.Filters {
+		query = query.WhereGroup(separator, func(q *bun.SelectQuery) *bun.SelectQuery {
+			return ApplyFilterWithOptions(q, filter, opts)
+		})
 	}
 
-	// Apply the filter group
-	return query.WhereGroup(logic, func(q *bun.SelectQuery) *bun.SelectQuery {
-		// Apply all direct filters in this group
-		for _, filter := range group.Filters {
-			q = ApplyFilter(q, filter)
-		}
+	// Apply all nested filter groups, at any depth
+	for _, nestedGroup := range group.Groups {
+		query = query.WhereGroup(separator, func(q *bun.SelectQuery) *bun.SelectQuery {
+			return applyGroupMembers(q, nestedGroup, opts)
+		})
+	}
+	return query
+}
 
-		// Apply all nested filter groups
-		for _, nestedGroup := range group.Groups {
-			nestedLogic := strings.ToLower(nestedGroup.Logic)
-			if nestedLogic == "" {
-				nestedLogic = "and"
-			}
+// groupSeparator returns the separator bun expects between the members of a group
+// Logic is validated when parsing; anything other than OR joins the members with AND
+func groupSeparator(group dto.FilterGroup) string {
+	if group.IsOr() {
+		return " OR "
+	}
+	return " AND "
+}
 
-			// Apply the nested group as a sub-group with the correct logic
-			q = q.WhereGroup(nestedLogic, func(subq *bun.SelectQuery) *bun.SelectQuery {
-				for _, filter := range nestedGroup.Filters {
-					subq = ApplyFilter(subq, filter)
-				}
-				return subq
-			})
-		}
-		return q
-	})
+// comparisonQueries are the conditions of the comparison operators, built once rather than for every filter
+var comparisonQueries = map[string]string{
+	"=":  "? = ?",
+	"!=": "? != ?",
+	">":  "? > ?",
+	">=": "? >= ?",
+	"<":  "? < ?",
+	"<=": "? <= ?",
 }
 
 // ApplyFilter applies a single filter to the query
 func ApplyFilter(query *bun.SelectQuery, filter dto.Filter) *bun.SelectQuery {
+	return ApplyFilterWithOptions(query, filter, Options{})
+}
+
+// ApplyFilterWithOptions applies a single filter to the query using the given options
+func ApplyFilterWithOptions(query *bun.SelectQuery, filter dto.Filter, opts Options) *bun.SelectQuery {
+	filter.Operator = operator.Canonical(filter.Operator)
+	filter = resolveNullCheck(filter)
+
+	// A between with a null bound is open-ended, and matches every row without bounds
+	if open, ok := resolveOpenRange(filter); ok {
+		if open.Operator == "" {
+			return query.Where("1 = 1")
+		}
+		return ApplyFilterWithOptions(query, open, opts)
+	}
+
 	field := filter.Field
+	if filter.CaseSensitive != nil {
+		opts = opts.withCaseSensitivity(field, *filter.CaseSensitive)
+	}
 	op := operator.GetOperator(filter.Operator)
 	value := filter.Value
+	column := opts.column(query, field)
+
+	// A list given to a scalar operator, or a scalar given to a list operator, would produce invalid SQL,
+	// so such a filter matches nothing
+	if arity := operator.GetArity(filter.Operator); arity == operator.ArityScalar || arity == operator.ArityList {
+		if !hasShape(arity, value) {
+			return query.Where("1 = 0")
+		}
+	}
+
+	// Use the template overriding the operator on the dialect, if any
+	if template, ok := opts.Templates.template(opts.dialectName(query), filter.Operator); ok {
+		return applyTemplate(query, template, filter, column, opts)
+	}
+
+	// Treat empty strings as NULL on the fields configured so
+	if opts.Fields[field].EmptyIsNull {
+		if emptyQuery, ok := applyEmptyIsNull(query, column, op, value); ok {
+			return emptyQuery
+		}
+	}
+
+	// Compare with the values of a list for the ANY and ALL operators, e.g. "= ANY"
+	if comparison, quantifier, ok := strings.Cut(op, " "); ok && (quantifier == "ANY" || quantifier == "ALL") {
+		return applyQuantified(query, field, column, comparison, quantifier == "ALL", value, opts)
+	}
 
 	// Handle different operator
 	switch op {
 	case "=", "!=", ">", ">=", "<", "<=":
-		// Handle date string values by using CAST for date columns
+		// Compare the dates only, ignoring the time of day, when the value is a date string
 		if strValue, ok := value.(string); ok {
-			// Check if this might be a date string (simple heuristic)
-			if isDateString(strValue) {
-				// Use CONVERT function for MSSQL to handle date comparison
-				return query.Where(fmt.Sprintf("CONVERT(DATE, ?) %s CONVERT(DATE, ?)", op), bun.Ident(field), strValue)
+			if date, ok := opts.DateLocale.dateValue(strValue); ok {
+				d := opts.dialectName(query)
+				return query.Where(comparisonQueries[op], dateExpr(d, opts.ident(field)), dateExpr(d, opts.sensitive(field, date)))
 			}
 		}
-		return query.Where(fmt.Sprintf("? %s ?", op), bun.Ident(field), value)
+		return query.Where(comparisonQueries[op], column, opts.bind(query, field, value))
 	case "LIKE":
-		// Check if the value is a string
-		if strValue, ok := value.(string); ok {
-			// If the value doesn't already contain wildcards, add them
-			if !strings.Contains(strValue, "%") {
-				strValue = fmt.Sprintf("%%%s%%", strValue)
-			}
-			return query.Where("? LIKE ?", bun.Ident(field), strValue)
+		pattern := opts.likePattern(field, value)
+		// LIKE ignores the case of ASCII letters on SQLite, unlike GLOB
+		if opts.dialectName(query) == dialect.SQLite && expr.IsCaseSensitive(opts.Fields[field]) {
+			return query.Where("? GLOB ?", column, opts.bind(query, field, likeToGlob(pattern)))
+		}
+		return query.Where("? LIKE ?", column, opts.bind(query, field, pattern))
+	case "ILIKE":
+		pattern := opts.likePattern(field, value)
+		// Dialects without ILIKE compare the lowercased column and pattern
+		if opts.dialectName(query) == dialect.PG {
+			return query.Where("? ILIKE ?", column, opts.bind(query, field, pattern))
+		}
+		return query.Where("LOWER(?) LIKE LOWER(?)", column, opts.bind(query, field, pattern))
+	case "SIMILARITY":
+		// Use pg_trgm trigram similarity on Postgres for typo-tolerant matching
+		if opts.dialectName(query) == dialect.PG {
+			return query.Where("similarity(?, ?) > ?", column, opts.bind(query, field, value), opts.similarityThreshold())
 		}
-		// If the value is not a string, use the default behavior
-		likeValue := fmt.Sprintf("%%%v%%", value)
-		return query.Where("? LIKE ?", bun.Ident(field), likeValue)
+		// Other dialects have no trigram support, so fall back to a substring match, on the value taken literally
+		likeValue := "%" + expr.EscapeLike(fmt.Sprint(value)) + "%"
+		return query.Where("? LIKE ? ESCAPE '!'", column, opts.bind(query, field, likeValue))
+	case "REGEXP":
+		return applyRegex(query, column, opts.bind(query, field, value), opts)
+	case "NEARBY":
+		return applyNearby(query, field, value, opts)
+	case "WITHIN BBOX":
+		return applyWithinBoundingBox(query, field, value, opts)
+	case "IS DISTINCT FROM":
+		return applyDistinctFrom(query, column, opts.bind(query, field, value), false, opts)
+	case "IS NOT DISTINCT FROM":
+		return applyDistinctFrom(query, column, opts.bind(query, field, value), true, opts)
+	case "OVERLAPS":
+		return applyOverlaps(query, field, column, value, opts)
+	case "DESCENDANT OF":
+		return applyHierarchy(query, field, value, false, opts)
+	case "ANCESTOR OF":
+		return applyHierarchy(query, field, value, true, opts)
 	case "IN":
-		// Handle array values for IN operator
-		return query.Where("? IN (?)", bun.Ident(field), bun.In(value))
+		return applyInList(query, field, column, value, false, opts)
 	case "NOT IN":
-		// Handle array values for NOT IN operator
-		return query.Where("? NOT IN (?)", bun.Ident(field), bun.In(value))
+		return applyInList(query, field, column, value, true, opts)
 	case "IS NULL":
-		return query.Where("? IS NULL", bun.Ident(field))
+		return query.Where("? IS NULL", column)
 	case "IS NOT NULL":
-		return query.Where("? IS NOT NULL", bun.Ident(field))
+		return query.Where("? IS NOT NULL", column)
+	case "IS TRUE":
+		return applyBoolean(query, column, true, opts)
+	case "IS FALSE":
+		return applyBoolean(query, column, false, opts)
 	case "BETWEEN":
 		// Handle array values for BETWEEN operator
 		// The value should be an array or slice with two elements: [lowerBound, upperBound]
-		if arr, ok := value.([]interface{}); ok && len(arr) == 2 {
-			// Check if both values might be date strings
-			if strVal1, ok1 := arr[0].(string); ok1 {
-				if strVal2, ok2 := arr[1].(string); ok2 {
-					if isDateString(strVal1) && isDateString(strVal2) {
-						return query.Where("CONVERT(DATE, ?) BETWEEN CONVERT(DATE, ?) AND CONVERT(DATE, ?)",
-							bun.Ident(field), strVal1, strVal2)
+		if bounds := listValues(value); len(bounds) == 2 {
+			// Compare the dates only when both bounds are date strings
+			if str1, ok1 := bounds[0].(string); ok1 {
+				if str2, ok2 := bounds[1].(string); ok2 {
+					date1, ok1 := opts.DateLocale.dateValue(str1)
+					date2, ok2 := opts.DateLocale.dateValue(str2)
+					if ok1 && ok2 {
+						d := opts.dialectName(query)
+						return applyBetween(query, dateExpr(d, opts.ident(field)), dateExpr(d, opts.sensitive(field, date1)), dateExpr(d, opts.sensitive(field, date2)), opts)
 					}
 				}
 			}
-			return query.Where("? BETWEEN ? AND ?", bun.Ident(field), arr[0], arr[1])
+			return applyBetween(query, column, opts.bind(query, field, bounds[0]), opts.bind(query, field, bounds[1]), opts)
 		}
 		// If the value is not a valid array, return an error or default behavior
-		return query.Where("? = ?", bun.Ident(field), value)
+		return query.Where("? = ?", column, opts.bind(query, field, value))
 	default:
 		// If operator not recognized, default to equality
-		return query.Where("? = ?", bun.Ident(field), value)
+		return query.Where("? = ?", column, opts.bind(query, field, value))
+	}
+}
+
+// dateExpr returns the expression converting a column or a value to a date, dropping the time of day
+func dateExpr(d dialect.Name, value interface{}) schema.QueryAppender {
+	switch d {
+	case dialect.MySQL:
+		return schema.SafeQuery("DATE(?)", []interface{}{value})
+	case dialect.SQLite:
+		return schema.SafeQuery("date(?)", []interface{}{value})
+	case dialect.MSSQL:
+		return schema.SafeQuery("CONVERT(DATE, ?)", []interface{}{value})
+	default:
+		return schema.SafeQuery("CAST(? AS DATE)", []interface{}{value})
+	}
+}
+
+// applyBoolean restricts the query to the rows where a boolean column is true or false, NULL matching neither
+// MSSQL has no boolean literals and compares bit columns with 1 and 0
+func applyBoolean(query *bun.SelectQuery, column schema.QueryAppender, value bool, opts Options) *bun.SelectQuery {
+	if opts.dialectName(query) == dialect.MSSQL {
+		if value {
+			return query.Where("? = 1", column)
+		}
+		return query.Where("? = 0", column)
+	}
+	if value {
+		return query.Where("? = TRUE", column)
+	}
+	return query.Where("? = FALSE", column)
+}
+
+// applyRegex restricts the query to the rows where the column matches a regular expression
+// Postgres uses ~, MySQL and SQLite REGEXP (SQLite needs a regexp function to be registered) and MSSQL REGEXP_LIKE
+func applyRegex(query *bun.SelectQuery, column schema.QueryAppender, value interface{}, opts Options) *bun.SelectQuery {
+	switch opts.dialectName(query) {
+	case dialect.PG:
+		return query.Where("? ~ ?", column, value)
+	case dialect.MSSQL:
+		return query.Where("REGEXP_LIKE(?, ?)", column, value)
+	default:
+		return query.Where("? REGEXP ?", column, value)
+	}
+}
+
+// applyBetween applies a BETWEEN comparison. With SymmetricBetween, bounds given in reverse order still match:
+// numbers and times are swapped before building the query, Postgres uses BETWEEN SYMMETRIC, and other dialects
+// match the range in either order
+func applyBetween(query *bun.SelectQuery, column, lower, upper interface{}, opts Options) *bun.SelectQuery {
+	if !opts.SymmetricBetween {
+		return query.Where("? BETWEEN ? AND ?", column, lower, upper)
+	}
+
+	if reversed, ok := boundsReversed(lower, upper); ok {
+		if reversed {
+			lower, upper = upper, lower
+		}
+		return query.Where("? BETWEEN ? AND ?", column, lower, upper)
+	}
+
+	if opts.dialectName(query) == dialect.PG {
+		return query.Where("? BETWEEN SYMMETRIC ? AND ?", column, lower, upper)
+	}
+	return query.Where("? BETWEEN ? AND ? OR ? BETWEEN ? AND ?", column, lower, upper, column, upper, lower)
+}
+
+// boundsReversed reports whether the lower bound is greater than the upper bound
+// It reports false for ok when the bounds are not both numbers or both times, as other values (strings in particular)
+// are ordered by the database according to the collation
+func boundsReversed(lower, upper interface{}) (reversed bool, ok bool) {
+	if lowerTime, isTime := lower.(time.Time); isTime {
+		if upperTime, isTime := upper.(time.Time); isTime {
+			return lowerTime.After(upperTime), true
+		}
+		return false, false
+	}
+
+	lowerValue, upperValue := reflect.ValueOf(lower), reflect.ValueOf(upper)
+	if lowerValue.CanInt() && upperValue.CanInt() {
+		return lowerValue.Int() > upperValue.Int(), true
+	}
+	if lowerValue.CanUint() && upperValue.CanUint() {
+		return lowerValue.Uint() > upperValue.Uint(), true
+	}
+
+	lowerFloat, lowerOk := toFloat(lowerValue)
+	upperFloat, upperOk := toFloat(upperValue)
+	if lowerOk && upperOk {
+		return lowerFloat > upperFloat, true
+	}
+	return false, false
+}
+
+// toFloat converts a numeric value to a float64
+func toFloat(v reflect.Value) (float64, bool) {
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// applyEmptyIsNull applies the null checks, and the comparisons with an empty string, so that they match
+// both empty strings and NULL. It reports false when the filter is not affected
+func applyEmptyIsNull(query *bun.SelectQuery, column schema.QueryAppender, op string, value interface{}) (*bun.SelectQuery, bool) {
+	isEmpty := value == ""
+
+	switch {
+	case op == "IS NULL", op == "=" && isEmpty:
+		return query.Where("(? IS NULL OR ? = '')", column, column), true
+	case op == "IS NOT NULL", op == "!=" && isEmpty:
+		return query.Where("(? IS NOT NULL AND ? <> '')", column, column), true
+	default:
+		return query, false
+	}
+}
+
+// applyDistinctFrom applies a NULL-safe comparison, where NULL is considered equal to NULL and different from any value
+// Unlike != and =, rows where the column is NULL are not silently dropped
+func applyDistinctFrom(query *bun.SelectQuery, column schema.QueryAppender, value interface{}, equal bool, opts Options) *bun.SelectQuery {
+	switch opts.dialectName(query) {
+	case dialect.PG:
+		if equal {
+			return query.Where("? IS NOT DISTINCT FROM ?", column, value)
+		}
+		return query.Where("? IS DISTINCT FROM ?", column, value)
+	case dialect.SQLite:
+		// IS and IS NOT are the NULL-safe comparisons in SQLite
+		if equal {
+			return query.Where("? IS ?", column, value)
+		}
+		return query.Where("? IS NOT ?", column, value)
+	case dialect.MySQL:
+		if equal {
+			return query.Where("? <=> ?", column, value)
+		}
+		return query.Where("NOT (? <=> ?)", column, value)
+	default:
+		// Portable equivalent for dialects without a NULL-safe comparison
+		if equal {
+			return query.Where("(? = ? OR (? IS NULL AND ? IS NULL))", column, value, column, value)
+		}
+		return query.Where("NOT (? = ? OR (? IS NULL AND ? IS NULL))", column, value, column, value)
+	}
+}
+
+// resolveAliases replaces the operator aliases of the filters of a group and its nested groups, and the boolean
+// values of their null checks, in place
+func resolveAliases(group dto.FilterGroup) {
+	for i := range group.Filters {
+		group.Filters[i].Operator = operator.Resolve(group.Filters[i].Operator)
+		group.Filters[i] = resolveNullCheck(group.Filters[i])
+	}
+	for _, nestedGroup := range group.Groups {
+		resolveAliases(nestedGroup)
+	}
+}
+
+// normalizeLogic sets the logic of a validated filter group and its nested groups to LogicAnd or LogicOr
+func normalizeLogic(group *dto.FilterGroup) {
+	group.Logic, _ = dto.ParseLogic(group.Logic)
+	for i := range group.Groups {
+		normalizeLogic(&group.Groups[i])
+	}
+}
+
+// resolveOpenRange reads a between with a null bound as a comparison with the other bound, [100, null] as gte 100
+// and [null, 200] as lte 200, and a between without bounds as a filter without operator. It reports false for the
+// other filters
+func resolveOpenRange(filter dto.Filter) (dto.Filter, bool) {
+	if filter.Operator != "between" {
+		return filter, false
+	}
+	bounds := listValues(filter.Value)
+	if len(bounds) != 2 || (bounds[0] != nil && bounds[1] != nil) {
+		return filter, false
+	}
+
+	switch {
+	case bounds[0] != nil:
+		filter.Operator, filter.Value = "gte", bounds[0]
+	case bounds[1] != nil:
+		filter.Operator, filter.Value = "lte", bounds[1]
+	default:
+		filter.Operator, filter.Value = "", nil
+	}
+	return filter, true
+}
+
+// resolveNullCheck reads the boolean value filter builders send with the null checks, true or false, as strings too:
+// isnull with false means isnotnull, and isnotnull with false means isnull. The value is dropped either way
+func resolveNullCheck(filter dto.Filter) dto.Filter {
+	if filter.Operator != "isnull" && filter.Operator != "isnotnull" {
+		return filter
+	}
+
+	var isTrue bool
+	switch v := filter.Value.(type) {
+	case bool:
+		isTrue = v
+	case string:
+		if !strings.EqualFold(v, "true") && !strings.EqualFold(v, "false") {
+			return filter
+		}
+		isTrue = strings.EqualFold(v, "true")
+	default:
+		return filter
+	}
+
+	if !isTrue {
+		if filter.Operator == "isnull" {
+			filter.Operator = "isnotnull"
+		} else {
+			filter.Operator = "isnull"
+		}
 	}
+	filter.Value = nil
+	return filter
 }
 
 // validateFilterGroup validates a filter group and its nested filter
-func validateFilterGroup(group dto.FilterGroup) error {
-	logic := strings.ToLower(group.Logic)
-	if logic != "and" && logic != "or" {
-		return errors.New("filter group logic must be 'and' or 'or'")
+func validateFilterGroup(group dto.FilterGroup, opts ParseOptions) error {
+	// An empty logic defaults to AND
+	if _, ok := dto.ParseLogic(group.Logic); !ok {
+		return fmt.Errorf("invalid filter group logic '%s': must be 'and' or 'or'", group.Logic)
 	}
 
 	// Validate individual filter
@@ -139,11 +599,21 @@ func validateFilterGroup(group dto.FilterGroup) error {
 		if err := validateFilter(filter); err != nil {
 			return err
 		}
+		if opts.RejectEmptyLists && operator.GetArity(filter.Operator) == operator.ArityList {
+			if length, _ := listLength(filter.Value); length == 0 {
+				return fmt.Errorf("operator '%s' on field '%s': %w", filter.Operator, filter.Field, ErrEmptyList)
+			}
+		}
+		if opts.RejectNullsInLists && operator.GetArity(filter.Operator) == operator.ArityList {
+			if slices.Contains(listValues(filter.Value), nil) {
+				return fmt.Errorf("operator '%s' on field '%s': %w", filter.Operator, filter.Field, ErrNullInList)
+			}
+		}
 	}
 
 	// Validate nested groups
 	for _, nestedGroup := range group.Groups {
-		if err := validateFilterGroup(nestedGroup); err != nil {
+		if err := validateFilterGroup(nestedGroup, opts); err != nil {
 			return err
 		}
 	}
@@ -161,9 +631,90 @@ func validateFilter(filter dto.Filter) error {
 		return fmt.Errorf("invalid operator: %s", filter.Operator)
 	}
 
+	return validateValue(filter)
+}
+
+// validateValue validates that the value of a filter has the shape its operator expects
+func validateValue(filter dto.Filter) error {
+	arity := operator.GetArity(filter.Operator)
+	if !hasShape(arity, filter.Value) {
+		return fmt.Errorf("operator '%s' on field '%s' requires %s", filter.Operator, filter.Field, arity)
+	}
 	return nil
 }
 
+// hasShape reports whether a value has the shape expected by an operator arity
+func hasShape(arity operator.Arity, value interface{}) bool {
+	length, isList := listLength(value)
+
+	switch arity {
+	case operator.ArityNone:
+		return value == nil
+	case operator.ArityPair:
+		return isList && length == 2
+	case operator.ArityObject:
+		return isObject(value)
+	case operator.ArityList:
+		return isList
+	default:
+		// Structs such as time.Time are single values, maps are not
+		return !isList && reflect.ValueOf(value).Kind() != reflect.Map
+	}
+}
+
+// listLength returns the length of a value if it is a list (a slice or array other than []byte)
+func listLength(value interface{}) (int, bool) {
+	if value == nil {
+		return 0, false
+	}
+	if _, ok := value.([]byte); ok {
+		return 0, false
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// isObject reports whether a value is an object: a map, or a struct or pointer to a struct
+func isObject(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Map || v.Kind() == reflect.Struct
+}
+
+// ParseSimpleFilters creates a filter group from a map of fields to values, e.g. {"status": ["A", "B"], "team": 3}
+// for internal tools that don't need groups: each value is compared with eq, lists with in and nil with isnull, and
+// the filters are joined with AND, in the order of their fields
+func ParseSimpleFilters(fields map[string]any) (dto.FilterGroup, error) {
+	group := dto.FilterGroup{Logic: "and", Filters: []dto.Filter{}, Groups: []dto.FilterGroup{}}
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		if field == "" {
+			return dto.FilterGroup{}, errors.New("filter field cannot be empty")
+		}
+
+		filter := dto.Filter{Field: field, Operator: "eq", Value: fields[field]}
+		if _, ok := listLength(filter.Value); ok {
+			filter.Operator = "in"
+		} else if filter.Value == nil {
+			filter.Operator = "isnull"
+		}
+		if err := validateValue(filter); err != nil {
+			return dto.FilterGroup{}, err
+		}
+		group.Filters = append(group.Filters, filter)
+	}
+	return group, nil
+}
+
 // ParseFilterParam creates a FilterGroup from a key name, operator, value, and optional logic
 // This utility function allows creating a simple filter with a single condition
 // key: the field name to filter on
@@ -182,17 +733,20 @@ func ParseFilterParam(key string, op string, value interface{}, logic string) (d
 	}
 
 	// Default to "and" logic if not specified or invalid
-	logic = strings.ToLower(logic)
-	if logic != "and" && logic != "or" {
-		logic = "and"
-	}
+	logic, _ = dto.ParseLogic(logic)
 
 	// Create the filter
 	filter := dto.Filter{
 		Field:    key,
-		Operator: op,
+		Operator: operator.Resolve(op),
 		Value:    value,
 	}
+	filter = resolveNullCheck(filter)
+
+	// Validate the value shape
+	if err := validateValue(filter); err != nil {
+		return dto.FilterGroup{}, err
+	}
 
 	// Create and return the filter group
 	return dto.FilterGroup{
@@ -202,22 +756,25 @@ func ParseFilterParam(key string, op string, value interface{}, logic string) (d
 	}, nil
 }
 
-// isDateString checks if a string might be a date string
-// This is a simple heuristic and might need to be adjusted based on your date formats
-func isDateString(s string) bool {
-	// Common date formats: YYYY-MM-DD, MM/DD/YYYY, DD-MM-YYYY, etc.
-	datePatterns := []*regexp.Regexp{
-		regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),                  // YYYY-MM-DD
-		regexp.MustCompile(`^\d{1,2}/\d{1,2}/\d{4}$`),              // MM/DD/YYYY
-		regexp.MustCompile(`^\d{1,2}-\d{1,2}-\d{4}$`),              // DD-MM-YYYY
-		regexp.MustCompile(`^\d{4}/\d{1,2}/\d{1,2}$`),              // YYYY/MM/DD
-		regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`), // ISO format
+// ApplyHavingGroupWithOptions applies a filter group to the HAVING clause of the query using the given options, e.g.
+// for filters on aggregates of a grouped query
+func ApplyHavingGroupWithOptions(query *bun.SelectQuery, group dto.FilterGroup, opts Options) *bun.SelectQuery {
+	if len(group.Filters) == 0 && len(group.Groups) == 0 {
+		return query
 	}
 
-	for _, pattern := range datePatterns {
-		if pattern.MatchString(s) {
-			return true
-		}
+	// Render the conditions as the WHERE clause of a query without a table, then move them to HAVING
+	if opts.Dialect == dialect.Invalid {
+		opts.Dialect = query.Dialect().Name()
+	}
+	conditions := applyGroupMembers(query.DB().NewSelect(), group, opts)
+	sql, err := conditions.AppendQuery(query.DB().Formatter(), nil)
+	if err != nil {
+		return query.Err(err)
+	}
+	_, where, ok := strings.Cut(string(sql), " WHERE ")
+	if !ok {
+		return query
 	}
-	return false
+	return query.Having("?", bun.Safe(where))
 }
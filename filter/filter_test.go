@@ -1,11 +1,35 @@
 package filter
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/operator"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
 	"testing"
+	"time"
 )
 
+// newTestDB returns a database used to compile queries; the Dialect option is used to generate SQL for other dialects
+func newTestDB(t testing.TB) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { sqldb.Close() })
+	return bun.NewDB(sqldb, sqlitedialect.New())
+}
+
+// compileFilter returns the SQL generated for a single filter
+func compileFilter(t *testing.T, filter dto.Filter, opts Options) string {
+	db := newTestDB(t)
+	return ApplyFilterWithOptions(db.NewSelect().TableExpr(`"users"`), filter, opts).String()
+}
+
 func TestParseFilterParam(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -89,6 +113,22 @@ func TestParseFilterParam(t *testing.T) {
 			logic:       "and",
 			expectError: true,
 		},
+		{
+			name:        "Invalid between with a single value",
+			key:         "age",
+			op:          "between",
+			value:       30,
+			logic:       "and",
+			expectError: true,
+		},
+		{
+			name:        "Invalid isnull with a value",
+			key:         "email",
+			op:          "isnull",
+			value:       "test",
+			logic:       "and",
+			expectError: true,
+		},
 		{
 			name:  "Invalid logic defaults to AND",
 			key:   "name",
@@ -128,3 +168,1301 @@ func TestParseFilterParam(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFiltersValueShape(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		expectError string
+	}{
+		{
+			name: "Valid value shapes",
+			json: `{"filters": [
+				{"field": "age", "operator": "between", "value": [18, 30]},
+				{"field": "email", "operator": "isnull"},
+				{"field": "status", "operator": "in", "value": ["active"]},
+				{"field": "location", "operator": "nearby", "value": {"lat": 1, "lng": 2, "radius": 3}}
+			]}`,
+		},
+		{
+			name:        "Between with three values",
+			json:        `{"filters": [{"field": "age", "operator": "between", "value": [1, 2, 3]}]}`,
+			expectError: "operator 'between' on field 'age' requires a pair of values",
+		},
+		{
+			name:        "Between with a scalar",
+			json:        `{"filters": [{"field": "age", "operator": "between", "value": 18}]}`,
+			expectError: "operator 'between' on field 'age' requires a pair of values",
+		},
+		{
+			name:        "Isnotnull with a value",
+			json:        `{"filters": [{"field": "email", "operator": "isnotnull", "value": "x"}]}`,
+			expectError: "operator 'isnotnull' on field 'email' requires no value",
+		},
+		{
+			name:        "Eq with a list",
+			json:        `{"filters": [{"field": "name", "operator": "eq", "value": ["a", "b"]}]}`,
+			expectError: "operator 'eq' on field 'name' requires a single value",
+		},
+		{
+			name:        "Like with an object",
+			json:        `{"filters": [{"field": "name", "operator": "like", "value": {"a": 1}}]}`,
+			expectError: "operator 'like' on field 'name' requires a single value",
+		},
+		{
+			name:        "In with a scalar",
+			json:        `{"filters": [{"field": "status", "operator": "in", "value": "active"}]}`,
+			expectError: "operator 'in' on field 'status' requires a list of values",
+		},
+		{
+			name:        "Nearby with a scalar in a nested group",
+			json:        `{"groups": [{"filters": [{"field": "location", "operator": "nearby", "value": 5}]}]}`,
+			expectError: "operator 'nearby' on field 'location' requires an object value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilters(tt.json)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseFiltersRejectEmptyLists(t *testing.T) {
+	jsonStr := `{"groups": [{"filters": [{"field": "status", "operator": "notin", "value": []}]}]}`
+
+	_, err := ParseFilters(jsonStr)
+	assert.NoError(t, err)
+
+	_, err = ParseFiltersWithOptions(jsonStr, ParseOptions{RejectEmptyLists: true})
+	assert.ErrorIs(t, err, ErrEmptyList)
+	assert.EqualError(t, err, "operator 'notin' on field 'status': empty list of values")
+}
+
+func TestParseFiltersRejectNullsInLists(t *testing.T) {
+	jsonStr := `{"filters": [{"field": "status", "operator": "in", "value": ["A", null]}]}`
+
+	_, err := ParseFilters(jsonStr)
+	assert.NoError(t, err)
+
+	_, err = ParseFiltersWithOptions(jsonStr, ParseOptions{RejectNullsInLists: true})
+	assert.ErrorIs(t, err, ErrNullInList)
+	assert.EqualError(t, err, "operator 'in' on field 'status': null in list of values")
+}
+
+func TestApplyFilterNullInList(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "In with null",
+			filter:   dto.Filter{Field: "status", Operator: "in", Value: []interface{}{"A", "B", nil}},
+			expected: `SELECT * FROM "users" WHERE (("status" IN ('A', 'B')) OR ("status" IS NULL))`,
+		},
+		{
+			name:     "In with only null",
+			filter:   dto.Filter{Field: "status", Operator: "in", Value: []interface{}{nil}},
+			expected: `SELECT * FROM "users" WHERE ("status" IS NULL)`,
+		},
+		{
+			name:     "Not in with null",
+			filter:   dto.Filter{Field: "status", Operator: "notin", Value: []interface{}{"A", nil}},
+			expected: `SELECT * FROM "users" WHERE ("status" NOT IN ('A'))`,
+		},
+		{
+			name:     "Not in with only null",
+			filter:   dto.Filter{Field: "status", Operator: "notin", Value: []interface{}{nil}},
+			expected: `SELECT * FROM "users" WHERE ("status" IS NOT NULL)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, Options{}))
+		})
+	}
+}
+
+func TestApplyFilterValueShape(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "Eq with a list matches nothing",
+			filter:   dto.Filter{Field: "name", Operator: "eq", Value: []string{"a", "b"}},
+			expected: `SELECT * FROM "users" WHERE (1 = 0)`,
+		},
+		{
+			name:     "In with a scalar matches nothing",
+			filter:   dto.Filter{Field: "status", Operator: "notin", Value: "active"},
+			expected: `SELECT * FROM "users" WHERE (1 = 0)`,
+		},
+		{
+			name:     "Eq with a time is a single value",
+			filter:   dto.Filter{Field: "created_at", Operator: "gt", Value: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			expected: `SELECT * FROM "users" WHERE ("created_at" > '2024-01-02 00:00:00+00:00')`,
+		},
+		{
+			name:     "In with an empty list matches nothing",
+			filter:   dto.Filter{Field: "age", Operator: "in", Value: []interface{}{}},
+			expected: `SELECT * FROM "users" WHERE (1 = 0)`,
+		},
+		{
+			name:     "Notin with an empty list matches everything",
+			filter:   dto.Filter{Field: "age", Operator: "notin", Value: []int{}},
+			expected: `SELECT * FROM "users" WHERE (1 = 1)`,
+		},
+		{
+			name:     "In with a typed slice",
+			filter:   dto.Filter{Field: "age", Operator: "in", Value: []int{20, 30}},
+			expected: `SELECT * FROM "users" WHERE ("age" IN (20, 30))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, Options{}))
+		})
+	}
+}
+
+func TestApplyFilterSimilar(t *testing.T) {
+	filter := dto.Filter{Field: "name", Operator: "similar", Value: "jonh"}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Postgres with default threshold",
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE (similarity("name", 'jonh') > 0.3)`,
+		},
+		{
+			name:     "Postgres with custom threshold",
+			opts:     Options{Dialect: dialect.PG, SimilarityThreshold: 0.6},
+			expected: `SELECT * FROM "users" WHERE (similarity("name", 'jonh') > 0.6)`,
+		},
+		{
+			name:     "LIKE fallback on other dialects",
+			opts:     Options{},
+			expected: `SELECT * FROM "users" WHERE ("name" LIKE '%jonh%' ESCAPE '!')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, filter, tt.opts))
+		})
+	}
+
+	// The LIKE wildcards of the value are matched literally by the fallback
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("name" LIKE '%50!%!_off!!%' ESCAPE '!')`,
+		compileFilter(t, dto.Filter{Field: "name", Operator: "similar", Value: "50%_off!"}, Options{}))
+}
+
+func TestApplyFilterGeo(t *testing.T) {
+	nearby := map[string]interface{}{"lat": 52.5, "lng": 13.4, "radius": 1000.0}
+	box := dto.GeoBoundingBox{MinLat: 52.3, MinLng: 13.1, MaxLat: 52.7, MaxLng: 13.8}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Nearby on Postgres",
+			filter:   dto.Filter{Field: "location", Operator: "nearby", Value: nearby},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE (ST_DWithin("location"::geography, ST_SetSRID(ST_MakePoint(13.4, 52.5), 4326)::geography, 1000))`,
+		},
+		{
+			name:     "Nearby on MySQL",
+			filter:   dto.Filter{Field: "location", Operator: "nearby", Value: nearby},
+			opts:     Options{Dialect: dialect.MySQL},
+			expected: `SELECT * FROM "users" WHERE (ST_Distance_Sphere("location", POINT(13.4, 52.5)) <= 1000)`,
+		},
+		{
+			name:     "Within bounding box on Postgres",
+			filter:   dto.Filter{Field: "location", Operator: "withinbbox", Value: box},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("location" && ST_MakeEnvelope(13.1, 52.3, 13.8, 52.7, 4326))`,
+		},
+		{
+			name:     "Within bounding box on MSSQL",
+			filter:   dto.Filter{Field: "location", Operator: "withinbbox", Value: &box},
+			opts:     Options{Dialect: dialect.MSSQL},
+			expected: `SELECT * FROM "users" WHERE ("location".Lat BETWEEN 52.3 AND 52.7 AND "location".Long BETWEEN 13.1 AND 13.8)`,
+		},
+		{
+			name:     "Unsupported dialect matches nothing",
+			filter:   dto.Filter{Field: "location", Operator: "nearby", Value: nearby},
+			opts:     Options{},
+			expected: `SELECT * FROM "users" WHERE (1 = 0)`,
+		},
+		{
+			name:     "Invalid value matches nothing",
+			filter:   dto.Filter{Field: "location", Operator: "nearby", Value: "52.5,13.4"},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE (1 = 0)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterDistinctFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Postgres distinct from",
+			filter:   dto.Filter{Field: "status", Operator: "distinctfrom", Value: "active"},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("status" IS DISTINCT FROM 'active')`,
+		},
+		{
+			name:     "SQLite not distinct from",
+			filter:   dto.Filter{Field: "status", Operator: "notdistinctfrom", Value: nil},
+			opts:     Options{},
+			expected: `SELECT * FROM "users" WHERE ("status" IS NULL)`,
+		},
+		{
+			name:     "MySQL distinct from",
+			filter:   dto.Filter{Field: "status", Operator: "distinctfrom", Value: "active"},
+			opts:     Options{Dialect: dialect.MySQL},
+			expected: `SELECT * FROM "users" WHERE (NOT ("status" <=> 'active'))`,
+		},
+		{
+			name:     "MSSQL distinct from",
+			filter:   dto.Filter{Field: "status", Operator: "distinctfrom", Value: "active"},
+			opts:     Options{Dialect: dialect.MSSQL},
+			expected: `SELECT * FROM "users" WHERE (NOT ("status" = 'active' OR ("status" IS NULL AND 'active' IS NULL)))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterFieldConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:   "Unaccent on Postgres",
+			filter: dto.Filter{Field: "name", Operator: "eq", Value: "José"},
+			opts: Options{
+				Dialect: dialect.PG,
+				Fields:  map[string]dto.FieldConfig{"name": {Unaccent: true}},
+			},
+			expected: `SELECT * FROM "users" WHERE (unaccent("name") = unaccent('José'))`,
+		},
+		{
+			name:   "Unaccent with IN on Postgres",
+			filter: dto.Filter{Field: "name", Operator: "in", Value: []interface{}{"José", "Zoë"}},
+			opts: Options{
+				Dialect: dialect.PG,
+				Fields:  map[string]dto.FieldConfig{"name": {Unaccent: true}},
+			},
+			expected: `SELECT * FROM "users" WHERE (unaccent("name") IN (unaccent('José'), unaccent('Zoë')))`,
+		},
+		{
+			name:   "Unaccent on MySQL uses an accent-insensitive collation",
+			filter: dto.Filter{Field: "name", Operator: "like", Value: "jose"},
+			opts: Options{
+				Dialect: dialect.MySQL,
+				Fields:  map[string]dto.FieldConfig{"name": {Unaccent: true}},
+			},
+			expected: `SELECT * FROM "users" WHERE ("name" COLLATE utf8mb4_0900_ai_ci LIKE '%jose%')`,
+		},
+		{
+			name:   "Collation on Postgres",
+			filter: dto.Filter{Field: "name", Operator: "eq", Value: "jose"},
+			opts: Options{
+				Dialect: dialect.PG,
+				Fields:  map[string]dto.FieldConfig{"name": {Collation: "und-x-icu"}},
+			},
+			expected: `SELECT * FROM "users" WHERE ("name" COLLATE "und-x-icu" = 'jose')`,
+		},
+		{
+			name:   "Unsafe collation name is ignored",
+			filter: dto.Filter{Field: "name", Operator: "eq", Value: "jose"},
+			opts: Options{
+				Dialect: dialect.MSSQL,
+				Fields:  map[string]dto.FieldConfig{"name": {Collation: "x; DROP TABLE users"}},
+			},
+			expected: `SELECT * FROM "users" WHERE ("name" = 'jose')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterEmptyIsNull(t *testing.T) {
+	opts := Options{Fields: map[string]dto.FieldConfig{"nickname": {EmptyIsNull: true}}}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "Equal to empty string",
+			filter:   dto.Filter{Field: "nickname", Operator: "eq", Value: ""},
+			expected: `SELECT * FROM "users" WHERE (("nickname" IS NULL OR "nickname" = ''))`,
+		},
+		{
+			name:     "Is null",
+			filter:   dto.Filter{Field: "nickname", Operator: "isnull"},
+			expected: `SELECT * FROM "users" WHERE (("nickname" IS NULL OR "nickname" = ''))`,
+		},
+		{
+			name:     "Is not null",
+			filter:   dto.Filter{Field: "nickname", Operator: "isnotnull"},
+			expected: `SELECT * FROM "users" WHERE (("nickname" IS NOT NULL AND "nickname" <> ''))`,
+		},
+		{
+			name:     "Other values are unaffected",
+			filter:   dto.Filter{Field: "nickname", Operator: "eq", Value: "Bob"},
+			expected: `SELECT * FROM "users" WHERE ("nickname" = 'Bob')`,
+		},
+		{
+			name:     "Other fields are unaffected",
+			filter:   dto.Filter{Field: "email", Operator: "isnull"},
+			expected: `SELECT * FROM "users" WHERE ("email" IS NULL)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, opts))
+		})
+	}
+}
+
+func TestApplyFilterLargeInList(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Within the chunk size",
+			filter:   dto.Filter{Field: "age", Operator: "in", Value: []int{1, 2}},
+			opts:     Options{InListChunkSize: 2},
+			expected: `SELECT * FROM "users" WHERE ("age" IN (1, 2))`,
+		},
+		{
+			name:     "In split into chunks",
+			filter:   dto.Filter{Field: "age", Operator: "in", Value: []int{1, 2, 3, 4, 5}},
+			opts:     Options{InListChunkSize: 2},
+			expected: `SELECT * FROM "users" WHERE ("age" IN (1, 2) OR "age" IN (3, 4) OR "age" IN (5))`,
+		},
+		{
+			name:     "Not in split into chunks",
+			filter:   dto.Filter{Field: "age", Operator: "notin", Value: []interface{}{1, 2, 3}},
+			opts:     Options{InListChunkSize: 2},
+			expected: `SELECT * FROM "users" WHERE ("age" NOT IN (1, 2) AND "age" NOT IN (3))`,
+		},
+		{
+			name:     "Values table on Postgres",
+			filter:   dto.Filter{Field: "name", Operator: "in", Value: []string{"a", "b", "c"}},
+			opts:     Options{Dialect: dialect.PG, InListChunkSize: 2, InListStrategy: InListValues},
+			expected: `SELECT * FROM "users" WHERE ("name" IN (SELECT v FROM (VALUES ('a'), ('b'), ('c')) AS t (v)))`,
+		},
+		{
+			name:     "Values strategy falls back to chunks",
+			filter:   dto.Filter{Field: "age", Operator: "in", Value: []int{1, 2, 3}},
+			opts:     Options{InListChunkSize: 2, InListStrategy: InListValues},
+			expected: `SELECT * FROM "users" WHERE ("age" IN (1, 2) OR "age" IN (3))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterSymmetricBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Reversed bounds without the option",
+			filter:   dto.Filter{Field: "age", Operator: "between", Value: []interface{}{30, 20}},
+			expected: `SELECT * FROM "users" WHERE ("age" BETWEEN 30 AND 20)`,
+		},
+		{
+			name:     "Reversed numbers are swapped",
+			filter:   dto.Filter{Field: "age", Operator: "between", Value: []interface{}{30.0, 20}},
+			opts:     Options{SymmetricBetween: true},
+			expected: `SELECT * FROM "users" WHERE ("age" BETWEEN 20 AND 30)`,
+		},
+		{
+			name:     "Ordered numbers are kept",
+			filter:   dto.Filter{Field: "age", Operator: "between", Value: []int{20, 30}},
+			opts:     Options{SymmetricBetween: true},
+			expected: `SELECT * FROM "users" WHERE ("age" BETWEEN 20 AND 30)`,
+		},
+		{
+			name:     "Strings on Postgres",
+			filter:   dto.Filter{Field: "name", Operator: "between", Value: []interface{}{"m", "a"}},
+			opts:     Options{Dialect: dialect.PG, SymmetricBetween: true},
+			expected: `SELECT * FROM "users" WHERE ("name" BETWEEN SYMMETRIC 'm' AND 'a')`,
+		},
+		{
+			name:     "Strings on other dialects",
+			filter:   dto.Filter{Field: "name", Operator: "between", Value: []interface{}{"m", "a"}},
+			opts:     Options{SymmetricBetween: true},
+			expected: `SELECT * FROM "users" WHERE ("name" BETWEEN 'm' AND 'a' OR "name" BETWEEN 'a' AND 'm')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterOpenBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "Without upper bound",
+			filter:   dto.Filter{Field: "price", Operator: "between", Value: []interface{}{100, nil}},
+			expected: `SELECT * FROM "users" WHERE ("price" >= 100)`,
+		},
+		{
+			name:     "Without lower bound",
+			filter:   dto.Filter{Field: "price", Operator: "between", Value: []interface{}{nil, 200}},
+			expected: `SELECT * FROM "users" WHERE ("price" <= 200)`,
+		},
+		{
+			name:     "Without bounds",
+			filter:   dto.Filter{Field: "price", Operator: "between", Value: []interface{}{nil, nil}},
+			expected: `SELECT * FROM "users" WHERE (1 = 1)`,
+		},
+		{
+			name:     "Date without upper bound",
+			filter:   dto.Filter{Field: "created_at", Operator: "between", Value: []interface{}{"2024-01-31", nil}},
+			expected: `SELECT * FROM "users" WHERE (date("created_at") >= date('2024-01-31'))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, Options{}))
+		})
+	}
+}
+
+func TestApplyFilterGroupLogic(t *testing.T) {
+	group, err := ParseFilters(`{
+		"logic": "and",
+		"filters": [{"field": "age", "operator": "gt", "value": 21}],
+		"groups": [{
+			"logic": "OR",
+			"filters": [
+				{"field": "first_name", "operator": "eq", "value": "User1"},
+				{"field": "age", "operator": "gt", "value": 55}
+			],
+			"groups": [{"filters": [{"field": "last_name", "operator": "eq", "value": "Last3"}]}]
+		}]
+	}`)
+	require.NoError(t, err)
+
+	db := newTestDB(t)
+	query := ApplyFilterGroup(db.NewSelect().TableExpr(`"users"`), group)
+	assert.Equal(t, `SELECT * FROM "users" WHERE ((("age" > 21)) AND ((("first_name" = 'User1')) OR (("age" > 55)) OR ((("last_name" = 'Last3')))))`,
+		query.String())
+}
+
+func TestParseFiltersGroupLogic(t *testing.T) {
+	for _, logic := range []string{"xor", "AND OR", "and;"} {
+		t.Run(logic, func(t *testing.T) {
+			_, err := ParseFilters(`{"logic": "and", "groups": [{"logic": "` + logic + `", "filters": []}]}`)
+			assert.EqualError(t, err, "invalid filter group logic '"+logic+"': must be 'and' or 'or'")
+		})
+	}
+}
+
+func TestParseFiltersNormalizesLogic(t *testing.T) {
+	group, err := ParseFilters(`{"logic": " OR ", "filters": [{"field": "age", "operator": "gt", "value": 20}],
+		"groups": [{"logic": "And", "filters": [{"field": "age", "operator": "lt", "value": 10}]}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "or", group.Logic)
+	assert.Equal(t, "and", group.Groups[0].Logic)
+
+	group, err = ParseFilterParam("age", "gt", 20, " Or")
+	require.NoError(t, err)
+	assert.Equal(t, "or", group.Logic)
+
+	// Groups built in code are joined the same way
+	db := newTestDB(t)
+	query := ApplyFilterGroup(db.NewSelect().TableExpr(`"users"`), dto.FilterGroup{Logic: " or ", Filters: []dto.Filter{
+		{Field: "age", Operator: "gt", Value: 20},
+		{Field: "age", Operator: "lt", Value: 10},
+	}})
+	assert.Equal(t, `SELECT * FROM "users" WHERE ((("age" > 20)) OR (("age" < 10)))`, query.String())
+}
+
+func TestParseFiltersArray(t *testing.T) {
+	group, err := ParseFilters(` [{"field": "id", "operator": "notin", "value": [1, 2]}, {"field": "age", "operator": "gt", "value": 20}]`)
+	require.NoError(t, err)
+	assert.Equal(t, "and", group.Logic)
+	assert.Len(t, group.Filters, 2)
+	assert.Empty(t, group.Groups)
+
+	_, err = ParseFilters(`[{"field": "age", "operator": "in", "value": 20}]`)
+	assert.EqualError(t, err, "operator 'in' on field 'age' requires a list of values")
+}
+
+func TestParseFiltersAliases(t *testing.T) {
+	require.NoError(t, operator.RegisterAlias("contains", "like"))
+	require.NoError(t, operator.RegisterAlias("not_in", "notin"))
+
+	group, err := ParseFilters(`{"filters": [{"field": "name", "operator": "contains", "value": "oh"}], "groups": [
+		{"filters": [{"field": "id", "operator": "NOT_IN", "value": [1, 2]}]}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "like", group.Filters[0].Operator)
+	assert.Equal(t, "notin", group.Groups[0].Filters[0].Operator)
+
+	// The shape is validated for the aliased operator
+	_, err = ParseFilters(`[{"field": "id", "operator": "not_in", "value": 1}]`)
+	assert.EqualError(t, err, "operator 'notin' on field 'id' requires a list of values")
+
+	// Filters built in code may use aliases too
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("name" LIKE '%oh%')`,
+		compileFilter(t, dto.Filter{Field: "name", Operator: "contains", Value: "oh"}, Options{}))
+}
+
+func TestParseFiltersFormatVersion(t *testing.T) {
+	// A format where the filters are listed under "all"
+	RegisterFormat("test", func(data []byte) (dto.FilterGroup, error) {
+		var payload struct {
+			All []dto.Filter `json:"all"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return dto.FilterGroup{}, err
+		}
+		return dto.FilterGroup{Filters: payload.All}, nil
+	})
+
+	group, err := ParseFilters(`{"version": "test", "all": [{"field": "age", "operator": "gt", "value": 20}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "and", group.Logic)
+	assert.Equal(t, []dto.Filter{{Field: "age", Operator: "gt", Value: int64(20)}}, group.Filters)
+
+	_, err = ParseFilters(`{"version": "test", "all": [{"field": "age", "operator": "in", "value": 20}]}`)
+	assert.EqualError(t, err, "operator 'in' on field 'age' requires a list of values")
+
+	group, err = ParseFilters(`{"version": 1, "filters": [{"field": "age", "operator": "gt", "value": 20}]}`)
+	require.NoError(t, err)
+	assert.Len(t, group.Filters, 1)
+
+	_, err = ParseFilters(`{"version": 3, "filters": []}`)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.EqualError(t, err, "unknown filter format version '3'")
+}
+
+func TestParseFilterList(t *testing.T) {
+	group, err := ParseFilterList([]string{
+		`{"field": "age", "operator": "gt", "value": 20}`,
+		`{"logic": "or", "filters": [{"field": "first_name", "operator": "eq", "value": "User1"}, {"field": "age", "operator": "gt", "value": 55}]}`,
+		``,
+	}, "and")
+	require.NoError(t, err)
+
+	db := newTestDB(t)
+	query := ApplyFilterGroup(db.NewSelect().TableExpr(`"users"`), group)
+	assert.Equal(t, `SELECT * FROM "users" WHERE ((("age" > 20)) AND ((("first_name" = 'User1')) OR (("age" > 55))))`,
+		query.String())
+
+	// A single group is used as is
+	group, err = ParseFilterList([]string{`{"logic": "or", "filters": [{"field": "age", "operator": "gt", "value": 20}]}`}, "and")
+	require.NoError(t, err)
+	assert.Equal(t, "or", group.Logic)
+
+	_, err = ParseFilterList([]string{`{"field": "age", "operator": "in", "value": 20}`, `[]`}, "and")
+	assert.EqualError(t, err, "operator 'in' on field 'age' requires a list of values")
+
+	_, err = ParseFilterList([]string{`{"field": "age", "operator": "gt", "value": 20}`, `[]`}, "xor")
+	assert.EqualError(t, err, "invalid filter group logic 'xor': must be 'and' or 'or'")
+}
+
+func TestParseSimpleFilters(t *testing.T) {
+	group, err := ParseSimpleFilters(map[string]any{"status": []string{"A", "B"}, "team": 3, "deleted_at": nil})
+	require.NoError(t, err)
+	assert.Equal(t, dto.FilterGroup{Logic: "and", Filters: []dto.Filter{
+		{Field: "deleted_at", Operator: "isnull"},
+		{Field: "status", Operator: "in", Value: []string{"A", "B"}},
+		{Field: "team", Operator: "eq", Value: 3},
+	}, Groups: []dto.FilterGroup{}}, group)
+
+	db := newTestDB(t)
+	assert.Equal(t, `SELECT * FROM "users" WHERE ((("deleted_at" IS NULL)) AND (("status" IN ('A', 'B'))) AND (("team" = 3)))`,
+		ApplyFilterGroup(db.NewSelect().TableExpr(`"users"`), group).String())
+
+	_, err = ParseSimpleFilters(map[string]any{"address": map[string]any{"city": "Paris"}})
+	assert.EqualError(t, err, "operator 'eq' on field 'address' requires a single value")
+	_, err = ParseSimpleFilters(map[string]any{"": 1})
+	assert.EqualError(t, err, "filter field cannot be empty")
+}
+
+func TestApplyFilterMySQL(t *testing.T) {
+	opts := Options{Dialect: dialect.MySQL}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "Date comparison",
+			filter:   dto.Filter{Field: "created_at", Operator: "gte", Value: "2024-01-02"},
+			expected: `SELECT * FROM "users" WHERE (DATE("created_at") >= DATE('2024-01-02'))`,
+		},
+		{
+			name:     "Date between",
+			filter:   dto.Filter{Field: "created_at", Operator: "between", Value: []interface{}{"2024-01-01", "2024-01-31"}},
+			expected: `SELECT * FROM "users" WHERE (DATE("created_at") BETWEEN DATE('2024-01-01') AND DATE('2024-01-31'))`,
+		},
+		{
+			name:     "Regex",
+			filter:   dto.Filter{Field: "email", Operator: "regex", Value: "^admin@"},
+			expected: `SELECT * FROM "users" WHERE ("email" REGEXP '^admin@')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, opts))
+		})
+	}
+}
+
+func TestApplyFilterRegex(t *testing.T) {
+	filter := dto.Filter{Field: "email", Operator: "regex", Value: "^admin@"}
+
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("email" ~ '^admin@')`, compileFilter(t, filter, Options{Dialect: dialect.PG}))
+	assert.Equal(t, `SELECT * FROM "users" WHERE (REGEXP_LIKE("email", '^admin@'))`, compileFilter(t, filter, Options{Dialect: dialect.MSSQL}))
+}
+
+func TestApplyFilterLikeMatch(t *testing.T) {
+	fields := map[string]dto.FieldConfig{"email": {Match: dto.MatchPrefix}, "code": {Match: dto.MatchExact}}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Contains by default",
+			filter:   dto.Filter{Field: "name", Operator: "like", Value: "jo"},
+			expected: `SELECT * FROM "users" WHERE ("name" LIKE '%jo%')`,
+		},
+		{
+			name:     "Prefix on the field",
+			filter:   dto.Filter{Field: "email", Operator: "like", Value: "jo"},
+			opts:     Options{Fields: fields},
+			expected: `SELECT * FROM "users" WHERE ("email" LIKE 'jo%')`,
+		},
+		{
+			name:     "Exact on the field",
+			filter:   dto.Filter{Field: "code", Operator: "like", Value: 42},
+			opts:     Options{Fields: fields, LikeMatch: dto.MatchSuffix},
+			expected: `SELECT * FROM "users" WHERE ("code" LIKE '42')`,
+		},
+		{
+			name:     "Suffix on the request",
+			filter:   dto.Filter{Field: "name", Operator: "like", Value: "jo"},
+			opts:     Options{Fields: fields, LikeMatch: dto.MatchSuffix},
+			expected: `SELECT * FROM "users" WHERE ("name" LIKE '%jo')`,
+		},
+		{
+			name:     "Wildcards of the value are kept",
+			filter:   dto.Filter{Field: "email", Operator: "like", Value: "%jo"},
+			opts:     Options{Fields: fields},
+			expected: `SELECT * FROM "users" WHERE ("email" LIKE '%jo')`,
+		},
+		{
+			name:     "Templates use the match mode",
+			filter:   dto.Filter{Field: "email", Operator: "like", Value: "jo"},
+			opts:     Options{Dialect: dialect.PG, Fields: fields, Templates: Templates{dialect.PG: {"like": "? ILIKE ?"}}},
+			expected: `SELECT * FROM "users" WHERE ("email" ILIKE 'jo%')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterILike(t *testing.T) {
+	filter := dto.Filter{Field: "name", Operator: "ilike", Value: "Jo"}
+
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("name" ILIKE '%Jo%')`, compileFilter(t, filter, Options{Dialect: dialect.PG}))
+	assert.Equal(t, `SELECT * FROM "users" WHERE (LOWER("name") LIKE LOWER('%Jo%'))`, compileFilter(t, filter, Options{Dialect: dialect.MySQL}))
+	assert.Equal(t, `SELECT * FROM "users" WHERE (LOWER("name") LIKE LOWER('Jo%'))`,
+		compileFilter(t, filter, Options{LikeMatch: dto.MatchPrefix}))
+}
+
+func TestApplyFilterCaseSensitive(t *testing.T) {
+	sensitive, insensitive := true, false
+	fields := map[string]dto.FieldConfig{"code": {CaseSensitive: &sensitive}, "email": {CaseSensitive: &insensitive}}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Case-insensitive field",
+			filter:   dto.Filter{Field: "email", Operator: "eq", Value: "John@Example.com"},
+			opts:     Options{Fields: fields},
+			expected: `SELECT * FROM "users" WHERE (LOWER("email") = 'john@example.com')`,
+		},
+		{
+			name:     "Case-insensitive list",
+			filter:   dto.Filter{Field: "email", Operator: "in", Value: []interface{}{"A", "b"}},
+			opts:     Options{Fields: fields},
+			expected: `SELECT * FROM "users" WHERE (LOWER("email") IN ('a', 'b'))`,
+		},
+		{
+			name:     "Case-sensitive field on MySQL",
+			filter:   dto.Filter{Field: "code", Operator: "eq", Value: "AbC"},
+			opts:     Options{Dialect: dialect.MySQL, Fields: fields},
+			expected: `SELECT * FROM "users" WHERE ("code" COLLATE utf8mb4_bin = 'AbC')`,
+		},
+		{
+			name:     "Case-sensitive like on SQLite",
+			filter:   dto.Filter{Field: "code", Operator: "like", Value: "A_c*"},
+			opts:     Options{Fields: fields},
+			expected: `SELECT * FROM "users" WHERE ("code" GLOB '*A?c[*]*')`,
+		},
+		{
+			name:     "Filter overriding the field",
+			filter:   dto.Filter{Field: "code", Operator: "like", Value: "AbC", CaseSensitive: &insensitive},
+			opts:     Options{Fields: fields},
+			expected: `SELECT * FROM "users" WHERE (LOWER("code") LIKE '%abc%')`,
+		},
+		{
+			name:     "Filter on an unconfigured field",
+			filter:   dto.Filter{Field: "name", Operator: "like", Value: "Jo", CaseSensitive: &sensitive},
+			expected: `SELECT * FROM "users" WHERE ("name" GLOB '*Jo*')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+
+	// The configuration of the field is left untouched
+	assert.True(t, *fields["code"].CaseSensitive)
+}
+
+func TestApplyFilterTemplates(t *testing.T) {
+	opts := Options{
+		Dialect: dialect.PG,
+		Templates: Templates{
+			dialect.PG: {
+				"like":   "? ILIKE ?",
+				"eq":     "?->>'value' = ?",
+				"in":     "? = ANY(ARRAY[?])",
+				"isnull": "coalesce(?, '') = ''",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Like with wildcards",
+			filter:   dto.Filter{Field: "name", Operator: "LIKE", Value: "john"},
+			opts:     opts,
+			expected: `SELECT * FROM "users" WHERE ("name" ILIKE '%john%')`,
+		},
+		{
+			name:     "Custom accessor",
+			filter:   dto.Filter{Field: "data", Operator: "eq", Value: "x"},
+			opts:     opts,
+			expected: `SELECT * FROM "users" WHERE ("data"->>'value' = 'x')`,
+		},
+		{
+			name:     "List operator",
+			filter:   dto.Filter{Field: "age", Operator: "in", Value: []int{1, 2}},
+			opts:     opts,
+			expected: `SELECT * FROM "users" WHERE ("age" = ANY(ARRAY[1, 2]))`,
+		},
+		{
+			name:     "Operator without a value",
+			filter:   dto.Filter{Field: "name", Operator: "isnull"},
+			opts:     opts,
+			expected: `SELECT * FROM "users" WHERE (coalesce("name", '') = '')`,
+		},
+		{
+			name:     "Other dialects are not affected",
+			filter:   dto.Filter{Field: "name", Operator: "like", Value: "john"},
+			opts:     Options{Templates: opts.Templates},
+			expected: `SELECT * FROM "users" WHERE ("name" LIKE '%john%')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+}
+
+func TestApplyFilterDateDialects(t *testing.T) {
+	filter := dto.Filter{Field: "created_at", Operator: "lt", Value: "1/31/2024"}
+
+	tests := map[dialect.Name]string{
+		dialect.PG:     `SELECT * FROM "users" WHERE (CAST("created_at" AS DATE) < CAST('2024-01-31' AS DATE))`,
+		dialect.MSSQL:  `SELECT * FROM "users" WHERE (CONVERT(DATE, "created_at") < CONVERT(DATE, '2024-01-31'))`,
+		dialect.SQLite: `SELECT * FROM "users" WHERE (date("created_at") < date('2024-01-31'))`,
+	}
+
+	for d, expected := range tests {
+		t.Run(d.String(), func(t *testing.T) {
+			assert.Equal(t, expected, compileFilter(t, filter, Options{Dialect: d}))
+		})
+	}
+}
+
+func TestApplyFilterDateLocale(t *testing.T) {
+	german, ok := Locale("de-DE")
+	require.True(t, ok)
+	british, ok := Locale("en_GB")
+	require.True(t, ok)
+	_, ok = Locale("xx")
+	require.False(t, ok)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		locale   DateLocale
+		expected string
+	}{
+		{name: "US by default", value: "01/02/2024", expected: "date('2024-01-02')"},
+		{name: "Day first", value: "01/02/2024", locale: british, expected: "date('2024-02-01')"},
+		{name: "Dots", value: "31.01.2024", locale: german, expected: "date('2024-01-31')"},
+		{name: "Dashes are day first", value: "2-1-2024", expected: "date('2024-01-02')"},
+		{name: "Year first", value: "2024/1/31", locale: german, expected: "date('2024-01-31')"},
+		{name: "English month name", value: "January 31, 2024", expected: "date('2024-01-31')"},
+		{name: "English abbreviation", value: "31 Jan 2024", locale: german, expected: "date('2024-01-31')"},
+		{name: "Localized month name", value: "31. März 2024", locale: german, expected: "date('2024-03-31')"},
+		{name: "ISO date time", value: "2024-01-31T10:00:00Z", expected: "date('2024-01-31T10:00:00Z')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Dialect: dialect.SQLite, DateLocale: tt.locale}
+			sql := compileFilter(t, dto.Filter{Field: "created_at", Operator: "gte", Value: tt.value}, opts)
+			assert.Equal(t, `SELECT * FROM "users" WHERE (date("created_at") >= `+tt.expected+`)`, sql)
+		})
+	}
+
+	// Impossible dates and other strings are compared as they are
+	opts := Options{Dialect: dialect.SQLite, DateLocale: british}
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("created_at" >= '12/31/2024')`,
+		compileFilter(t, dto.Filter{Field: "created_at", Operator: "gte", Value: "12/31/2024"}, opts))
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("name" = '31 Smith 2024')`,
+		compileFilter(t, dto.Filter{Field: "name", Operator: "eq", Value: "31 Smith 2024"}, opts))
+
+	// Both bounds of between are read in the locale
+	assert.Equal(t, `SELECT * FROM "users" WHERE (date("created_at") BETWEEN date('2024-02-01') AND date('2024-03-01'))`,
+		compileFilter(t, dto.Filter{Field: "created_at", Operator: "between", Value: []interface{}{"01/02/2024", "1 mars 2024"}},
+			Options{Dialect: dialect.SQLite, DateLocale: Locales["fr"]}))
+}
+
+func TestDateLocaleIsDate(t *testing.T) {
+	german := Locales["de"]
+	assert.True(t, german.IsDate("2024-01-31"))
+	assert.True(t, german.IsDate("2024-01-31T10:00:00Z"))
+	assert.True(t, german.IsDate("2024-01-31T10:00:00"))
+	assert.True(t, german.IsDate("31.01.2024"))
+	assert.True(t, german.IsDate("31. März 2024"))
+	assert.False(t, german.IsDate("2024-01-31 and later"))
+	assert.False(t, german.IsDate("31.02.2024"))
+	assert.False(t, DateLocale{}.IsDate("31/01/2024"))
+}
+
+func TestApplyFilterDecimal(t *testing.T) {
+	opts := Options{Dialect: dialect.MySQL, Fields: map[string]dto.FieldConfig{"amount": {Type: dto.FieldDecimal}}}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "Float",
+			filter:   dto.Filter{Field: "amount", Operator: "gt", Value: 10.1},
+			expected: `SELECT * FROM "users" WHERE ("amount" > 10.1)`,
+		},
+		{
+			name:     "String keeps the scale",
+			filter:   dto.Filter{Field: "amount", Operator: "eq", Value: "+10.10"},
+			expected: `SELECT * FROM "users" WHERE ("amount" = 10.10)`,
+		},
+		{
+			name:     "Between",
+			filter:   dto.Filter{Field: "amount", Operator: "between", Value: []interface{}{json.Number("0.1"), "0.30"}},
+			expected: `SELECT * FROM "users" WHERE ("amount" BETWEEN 0.1 AND 0.30)`,
+		},
+		{
+			name:     "Other strings are bound as strings",
+			filter:   dto.Filter{Field: "amount", Operator: "eq", Value: "1; DROP TABLE users"},
+			expected: `SELECT * FROM "users" WHERE ("amount" = '1; DROP TABLE users')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, opts))
+		})
+	}
+}
+
+func TestApplyFilterBoolean(t *testing.T) {
+	tests := map[dialect.Name][2]string{
+		dialect.PG:     {`SELECT * FROM "users" WHERE ("active" = TRUE)`, `SELECT * FROM "users" WHERE ("active" = FALSE)`},
+		dialect.SQLite: {`SELECT * FROM "users" WHERE ("active" = TRUE)`, `SELECT * FROM "users" WHERE ("active" = FALSE)`},
+		dialect.MSSQL:  {`SELECT * FROM "users" WHERE ("active" = 1)`, `SELECT * FROM "users" WHERE ("active" = 0)`},
+	}
+
+	for d, expected := range tests {
+		t.Run(d.String(), func(t *testing.T) {
+			opts := Options{Dialect: d}
+			assert.Equal(t, expected[0], compileFilter(t, dto.Filter{Field: "active", Operator: "istrue"}, opts))
+			assert.Equal(t, expected[1], compileFilter(t, dto.Filter{Field: "active", Operator: "isfalse"}, opts))
+		})
+	}
+
+	_, err := ParseFilters(`[{"field": "active", "operator": "istrue", "value": true}]`)
+	assert.EqualError(t, err, "operator 'istrue' on field 'active' requires no value")
+}
+
+func TestApplyFilterNullCheckValues(t *testing.T) {
+	isNull := `SELECT * FROM "users" WHERE ("email" IS NULL)`
+	isNotNull := `SELECT * FROM "users" WHERE ("email" IS NOT NULL)`
+
+	tests := []struct {
+		operator string
+		value    interface{}
+		expected string
+	}{
+		{"isnull", nil, isNull},
+		{"isnull", true, isNull},
+		{"isnull", false, isNotNull},
+		{"isnull", "FALSE", isNotNull},
+		{"isnotnull", true, isNotNull},
+		{"isnotnull", false, isNull},
+		{"isnotnull", "false", isNull},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.operator, tt.value), func(t *testing.T) {
+			filter := dto.Filter{Field: "email", Operator: tt.operator, Value: tt.value}
+			assert.Equal(t, tt.expected, compileFilter(t, filter, Options{}))
+		})
+	}
+
+	// Parsed filters carry the operator the value stands for, without the value
+	group, err := ParseFilters(`[{"field": "email", "operator": "isnull", "value": false}]`)
+	require.NoError(t, err)
+	assert.Equal(t, dto.Filter{Field: "email", Operator: "isnotnull"}, group.Filters[0])
+
+	group, err = ParseFilterParam("email", "isnotnull", "false", "and")
+	require.NoError(t, err)
+	assert.Equal(t, dto.Filter{Field: "email", Operator: "isnull"}, group.Filters[0])
+
+	_, err = ParseFilters(`[{"field": "email", "operator": "isnull", "value": "yes"}]`)
+	assert.EqualError(t, err, "operator 'isnull' on field 'email' requires no value")
+}
+
+func TestApplyFilterQuantified(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Postgres array of numbers",
+			filter:   dto.Filter{Field: "age", Operator: "anyeq", Value: []interface{}{float64(20), float64(30)}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("age" = ANY ('{20,30}'))`,
+		},
+		{
+			name:     "Postgres array of integers above 2^53",
+			filter:   dto.Filter{Field: "id", Operator: "anyeq", Value: []interface{}{int64(9007199254740993), int64(1)}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("id" = ANY ('{9007199254740993,1}'))`,
+		},
+		{
+			name:     "Postgres array of unsigned integers above int64",
+			filter:   dto.Filter{Field: "id", Operator: "anyeq", Value: []interface{}{uint64(18446744073709551615), int64(1)}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("id" = ANY ('{18446744073709551615,1}'))`,
+		},
+		{
+			name:     "Postgres integers above 2^53 mixed with floats are expanded",
+			filter:   dto.Filter{Field: "id", Operator: "anyeq", Value: []interface{}{int64(9007199254740993), 1.5}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("id" = 9007199254740993 OR "id" = 1.5)`,
+		},
+		{
+			name:     "Postgres array of strings",
+			filter:   dto.Filter{Field: "name", Operator: "allneq", Value: []string{"O'Brien", "Smith"}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("name" != ALL ('{"O''Brien","Smith"}'))`,
+		},
+		{
+			name:     "Postgres mixed types are expanded",
+			filter:   dto.Filter{Field: "age", Operator: "anygt", Value: []interface{}{float64(20), "30"}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE ("age" > 20 OR "age" > '30')`,
+		},
+		{
+			name:     "Other dialects are expanded",
+			filter:   dto.Filter{Field: "age", Operator: "allgte", Value: []interface{}{float64(20), float64(30)}},
+			opts:     Options{Dialect: dialect.SQLite},
+			expected: `SELECT * FROM "users" WHERE ("age" >= 20 AND "age" >= 30)`,
+		},
+		{
+			name:     "Empty ANY matches nothing",
+			filter:   dto.Filter{Field: "age", Operator: "anylt", Value: []interface{}{}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE (1 = 0)`,
+		},
+		{
+			name:     "Empty ALL matches everything",
+			filter:   dto.Filter{Field: "age", Operator: "alllt", Value: []interface{}{}},
+			opts:     Options{Dialect: dialect.PG},
+			expected: `SELECT * FROM "users" WHERE (1 = 1)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, tt.opts))
+		})
+	}
+
+	_, err := ParseFilters(`[{"field": "age", "operator": "anyeq", "value": 20}]`)
+	assert.EqualError(t, err, "operator 'anyeq' on field 'age' requires a list of values")
+}
+
+// benchmarkFilterJSON is a typical filter payload received by list endpoints
+const benchmarkFilterJSON = `{
+	"logic": "and",
+	"filters": [
+		{"field": "age", "operator": "gte", "value": 18},
+		{"field": "status", "operator": "in", "value": ["active", "pending", "trial"]},
+		{"field": "created_at", "operator": "between", "value": ["2024-01-01", "2024-12-31"]}
+	],
+	"groups": [{"logic": "or", "filters": [
+		{"field": "first_name", "operator": "like", "value": "jo"},
+		{"field": "last_name", "operator": "like", "value": "jo"}
+	]}]
+}`
+
+func BenchmarkParseFilters(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseFilters(benchmarkFilterJSON); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkApplyFilterGroup(b *testing.B) {
+	group, err := ParseFilters(benchmarkFilterJSON)
+	require.NoError(b, err)
+	db := newTestDB(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := ApplyFilterGroup(db.NewSelect().TableExpr(`"users"`), group)
+		if _, err := query.AppendQuery(db.Formatter(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestApplyFilterHierarchy(t *testing.T) {
+	type node struct {
+		bun.BaseModel `bun:"table:categories,alias:c"`
+
+		ID       int64  `bun:"id,pk"`
+		ParentID *int64 `bun:"parent_id"`
+	}
+	db := newTestDB(t)
+	compile := func(filter dto.Filter, opts Options) string {
+		return ApplyFilterWithOptions(db.NewSelect().Model((*node)(nil)), filter, opts).String()
+	}
+
+	assert.Equal(t,
+		`SELECT "c"."id", "c"."parent_id" FROM "categories" AS "c" WHERE ("c"."id" IN (WITH RECURSIVE bunql_tree (node) AS (`+
+			`SELECT "id" FROM "categories" WHERE "parent_id" = 1 `+
+			`UNION SELECT bunql_node."id" FROM "categories" AS bunql_node JOIN bunql_tree ON bunql_node."parent_id" = bunql_tree.node`+
+			`) SELECT node FROM bunql_tree WHERE node IS NOT NULL))`,
+		compile(dto.Filter{Field: "c.id", Operator: "descendantof", Value: 1}, Options{}))
+
+	opts := Options{Fields: map[string]dto.FieldConfig{"id": {Parent: "manager_id"}}}
+	assert.Equal(t,
+		`SELECT "c"."id", "c"."parent_id" FROM "categories" AS "c" WHERE ("id" IN (WITH RECURSIVE bunql_tree (node) AS (`+
+			`SELECT "manager_id" FROM "categories" WHERE "id" = 7 `+
+			`UNION SELECT bunql_node."manager_id" FROM "categories" AS bunql_node JOIN bunql_tree ON bunql_node."id" = bunql_tree.node`+
+			`) SELECT node FROM bunql_tree WHERE node IS NOT NULL))`,
+		compile(dto.Filter{Field: "id", Operator: "ancestorof", Value: 7}, opts))
+
+	// SQL Server does not allow CTEs in subqueries, and queries with no model have no table to walk
+	assert.Equal(t, `SELECT "c"."id", "c"."parent_id" FROM "categories" AS "c" WHERE (1 = 0)`,
+		compile(dto.Filter{Field: "id", Operator: "descendantof", Value: 1}, Options{Dialect: dialect.MSSQL}))
+	assert.Equal(t, `SELECT * FROM "users" WHERE (1 = 0)`,
+		compileFilter(t, dto.Filter{Field: "id", Operator: "descendantof", Value: 1}, Options{}))
+}
+
+func TestApplyFilterOverlaps(t *testing.T) {
+	opts := Options{Fields: map[string]dto.FieldConfig{"starts_at": {End: "ends_at"}}}
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+	}{
+		{
+			name:     "Range",
+			filter:   dto.Filter{Field: "starts_at", Operator: "overlaps", Value: []interface{}{10, 20}},
+			expected: `SELECT * FROM "users" WHERE ("starts_at" <= 20) AND ("ends_at" >= 10)`,
+		},
+		{
+			name:     "Dates",
+			filter:   dto.Filter{Field: "starts_at", Operator: "overlaps", Value: []interface{}{"2024-01-01", "2024-01-10"}},
+			expected: `SELECT * FROM "users" WHERE (date("starts_at") <= date('2024-01-10')) AND (date("ends_at") >= date('2024-01-01'))`,
+		},
+		{
+			name:     "Open range",
+			filter:   dto.Filter{Field: "starts_at", Operator: "overlaps", Value: []interface{}{nil, 20}},
+			expected: `SELECT * FROM "users" WHERE ("starts_at" <= 20)`,
+		},
+		{
+			name:     "Field without end",
+			filter:   dto.Filter{Field: "day", Operator: "overlaps", Value: []interface{}{10, 20}},
+			expected: `SELECT * FROM "users" WHERE ("day" <= 20) AND ("day" >= 10)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compileFilter(t, tt.filter, opts))
+		})
+	}
+}
+
+func TestApplyFilterSensitive(t *testing.T) {
+	opts := Options{Fields: map[string]dto.FieldConfig{"email": {Sensitive: true}, "born_on": {Sensitive: true}}}
+	db := newTestDB(t)
+
+	tests := []struct {
+		name     string
+		filter   dto.Filter
+		expected string
+		redacted string
+	}{
+		{
+			name:     "Comparison",
+			filter:   dto.Filter{Field: "email", Operator: "eq", Value: "it's@example.com"},
+			expected: `SELECT * FROM "users" WHERE ("email" = 'it''s@example.com')`,
+			redacted: `SELECT * FROM "users" WHERE ("email" = '[REDACTED]')`,
+		},
+		{
+			name:     "List",
+			filter:   dto.Filter{Field: "email", Operator: "in", Value: []interface{}{"a@example.com", "b@example.com"}},
+			expected: `SELECT * FROM "users" WHERE ("email" IN ('a@example.com', 'b@example.com'))`,
+			redacted: `SELECT * FROM "users" WHERE ("email" IN ('[REDACTED]', '[REDACTED]'))`,
+		},
+		{
+			name:     "Date",
+			filter:   dto.Filter{Field: "born_on", Operator: "lt", Value: "2000-01-02"},
+			expected: `SELECT * FROM "users" WHERE (date("born_on") < date('2000-01-02'))`,
+			redacted: `SELECT * FROM "users" WHERE (date("born_on") < date('[REDACTED]'))`,
+		},
+		{
+			name:     "Time",
+			filter:   dto.Filter{Field: "born_on", Operator: "gte", Value: time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)},
+			expected: `SELECT * FROM "users" WHERE ("born_on" >= '2000-01-02 03:04:05+00:00')`,
+			redacted: `SELECT * FROM "users" WHERE ("born_on" >= '[REDACTED]')`,
+		},
+		{
+			name:     "Other fields",
+			filter:   dto.Filter{Field: "age", Operator: "eq", Value: 21},
+			expected: `SELECT * FROM "users" WHERE ("age" = 21)`,
+			redacted: `SELECT * FROM "users" WHERE ("age" = 21)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := ApplyFilterWithOptions(db.NewSelect().TableExpr(`"users"`), tt.filter, opts)
+			assert.Equal(t, tt.expected, query.String())
+
+			redacted, err := query.AppendQuery(RedactingFormatter(db.Formatter(), "[REDACTED]"), nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.redacted, string(redacted))
+		})
+	}
+}
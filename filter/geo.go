@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// applyNearby restricts the query to the rows whose point column lies within a radius (in meters) of a point
+// Postgres uses PostGIS, MySQL its spatial functions and MSSQL the geography type; other dialects have no
+// spatial support, and the filter matches no rows, as it does when the value is not a valid GeoRadius
+func applyNearby(query *bun.SelectQuery, field string, value interface{}, opts Options) *bun.SelectQuery {
+	var radius dto.GeoRadius
+	if !decodeGeoValue(value, &radius) {
+		return query.Where("1 = 0")
+	}
+	lat, lng, within := opts.sensitive(field, radius.Lat), opts.sensitive(field, radius.Lng), opts.sensitive(field, radius.Radius)
+
+	switch opts.dialectName(query) {
+	case dialect.PG:
+		return query.Where("ST_DWithin(?::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)",
+			bun.Ident(field), lng, lat, within)
+	case dialect.MySQL:
+		return query.Where("ST_Distance_Sphere(?, POINT(?, ?)) <= ?",
+			bun.Ident(field), lng, lat, within)
+	case dialect.MSSQL:
+		return query.Where("?.STDistance(geography::Point(?, ?, 4326)) <= ?",
+			bun.Ident(field), lat, lng, within)
+	default:
+		return query.Where("1 = 0")
+	}
+}
+
+// applyWithinBoundingBox restricts the query to the rows whose point column lies within a bounding box
+// Postgres uses PostGIS, MySQL its spatial functions and MSSQL the geography type; other dialects have no
+// spatial support, and the filter matches no rows, as it does when the value is not a valid GeoBoundingBox
+func applyWithinBoundingBox(query *bun.SelectQuery, field string, value interface{}, opts Options) *bun.SelectQuery {
+	var box dto.GeoBoundingBox
+	if !decodeGeoValue(value, &box) {
+		return query.Where("1 = 0")
+	}
+	minLat, minLng := opts.sensitive(field, box.MinLat), opts.sensitive(field, box.MinLng)
+	maxLat, maxLng := opts.sensitive(field, box.MaxLat), opts.sensitive(field, box.MaxLng)
+
+	switch opts.dialectName(query) {
+	case dialect.PG:
+		return query.Where("? && ST_MakeEnvelope(?, ?, ?, ?, 4326)",
+			bun.Ident(field), minLng, minLat, maxLng, maxLat)
+	case dialect.MySQL:
+		return query.Where("MBRContains(ST_MakeEnvelope(POINT(?, ?), POINT(?, ?)), ?)",
+			minLng, minLat, maxLng, maxLat, bun.Ident(field))
+	case dialect.MSSQL:
+		return query.Where("?.Lat BETWEEN ? AND ? AND ?.Long BETWEEN ? AND ?",
+			bun.Ident(field), minLat, maxLat, bun.Ident(field), minLng, maxLng)
+	default:
+		return query.Where("1 = 0")
+	}
+}
+
+// decodeGeoValue decodes a filter value, either the dto type itself or its JSON object form, into dest
+func decodeGeoValue(value interface{}, dest interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	// Re-encode the value so typed values and decoded JSON objects are handled alike
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dest) == nil
+}
@@ -0,0 +1,121 @@
+package filter
+
+import (
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DefaultInListChunkSize is the maximum number of values compared in a single IN list
+const DefaultInListChunkSize = 1000
+
+// InListStrategy is how IN lists larger than the chunk size are compared
+type InListStrategy int
+
+const (
+	// InListChunks splits the list into IN lists joined with OR (NOT IN lists joined with AND)
+	InListChunks InListStrategy = iota
+	// InListValues compares the column with a VALUES table on Postgres and MSSQL, and falls back to chunks elsewhere
+	InListValues
+)
+
+// inListChunkSize returns the configured IN list chunk size or the default one
+func (o Options) inListChunkSize() int {
+	if o.InListChunkSize > 0 {
+		return o.InListChunkSize
+	}
+	return DefaultInListChunkSize
+}
+
+// applyInList applies an IN or NOT IN comparison with a list of values
+// IN () is invalid in most dialects, so an empty list matches nothing for IN and everything for NOT IN
+// Lists larger than the chunk size are rewritten according to the InListStrategy, since multi-thousand-element lists
+// exceed the limits of some dialects
+// A null in the list matches the NULL values of the column, which IN never matches and NOT IN never leaves out
+func applyInList(query *bun.SelectQuery, field string, column schema.QueryAppender, value interface{}, negate bool, opts Options) *bun.SelectQuery {
+	values := listValues(value)
+	if nonNull := slices.DeleteFunc(slices.Clone(values), isNull); len(nonNull) < len(values) {
+		// NOT IN already leaves out the NULL values, except for an empty list
+		if negate {
+			if len(nonNull) == 0 {
+				return query.Where("? IS NOT NULL", column)
+			}
+			return applyInList(query, field, column, nonNull, true, opts)
+		}
+		if len(nonNull) == 0 {
+			return query.Where("? IS NULL", column)
+		}
+		return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return applyInList(q, field, column, nonNull, false, opts).WhereOr("? IS NULL", column)
+		})
+	}
+
+	if len(values) == 0 {
+		if negate {
+			return query.Where("1 = 1")
+		}
+		return query.Where("1 = 0")
+	}
+
+	op, condition := "IN", "? IN (?)"
+	if negate {
+		op, condition = "NOT IN", "? NOT IN (?)"
+	}
+
+	chunkSize := opts.inListChunkSize()
+	if len(values) <= chunkSize {
+		return query.Where(condition, column, bun.In(opts.bind(query, field, values)))
+	}
+
+	if opts.InListStrategy == InListValues {
+		switch opts.dialectName(query) {
+		case dialect.PG, dialect.MSSQL:
+			rows := strings.Repeat(", (?)", len(values))[2:]
+			args := append([]interface{}{column}, opts.bind(query, field, values).([]interface{})...)
+			return query.Where(fmt.Sprintf("? %s (SELECT v FROM (VALUES %s) AS t (v))", op, rows), args...)
+		}
+	}
+
+	separator := " OR "
+	if negate {
+		separator = " AND "
+	}
+
+	chunks := (len(values) + chunkSize - 1) / chunkSize
+	terms := make([]string, 0, chunks)
+	args := make([]interface{}, 0, 2*chunks)
+	for start := 0; start < len(values); start += chunkSize {
+		end := min(start+chunkSize, len(values))
+		terms = append(terms, condition)
+		args = append(args, column, bun.In(opts.bind(query, field, values[start:end])))
+	}
+	return query.Where(strings.Join(terms, separator), args...)
+}
+
+// isNull reports whether a value of a list is null
+func isNull(value interface{}) bool {
+	return value == nil
+}
+
+// listValues returns the elements of a list value
+func listValues(value interface{}) []interface{} {
+	if values, ok := value.([]interface{}); ok {
+		return values
+	}
+
+	length, ok := listLength(value)
+	if !ok {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	values := make([]interface{}, length)
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
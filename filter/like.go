@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"strings"
+)
+
+// likePattern returns the LIKE pattern of a value, adding the wildcards of the match mode of the field unless the
+// value already has some
+func (o Options) likePattern(field string, value interface{}) string {
+	pattern := fmt.Sprintf("%v", value)
+	if strings.Contains(pattern, "%") {
+		return pattern
+	}
+
+	mode := o.Fields[field].Match
+	if mode == "" {
+		mode = o.LikeMatch
+	}
+	switch mode {
+	case dto.MatchPrefix:
+		return pattern + "%"
+	case dto.MatchSuffix:
+		return "%" + pattern
+	case dto.MatchExact:
+		return pattern
+	default:
+		return "%" + pattern + "%"
+	}
+}
+
+// likeToGlob translates a LIKE pattern to a GLOB pattern, which SQLite matches case-sensitively
+func likeToGlob(pattern string) string {
+	var glob strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			glob.WriteByte('*')
+		case '_':
+			glob.WriteByte('?')
+		case '*', '?', '[':
+			glob.WriteString("[" + string(r) + "]")
+		default:
+			glob.WriteRune(r)
+		}
+	}
+	return glob.String()
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// applyOverlaps restricts the query to the rows whose range, from the field to its end column (FieldConfig.End, or
+// the field itself), overlaps the range of the value, bounds included: the range starts before the upper bound and
+// ends after the lower bound. A null bound leaves the range of the value open, and date strings compare dates only
+func applyOverlaps(query *bun.SelectQuery, field string, column schema.QueryAppender, value interface{}, opts Options) *bun.SelectQuery {
+	bounds := listValues(value)
+	if len(bounds) != 2 {
+		return query.Where("1 = 0")
+	}
+
+	end := opts.Fields[field].End
+	if end == "" {
+		end = field
+	}
+
+	if bounds[0] == nil && bounds[1] == nil {
+		return query.Where("1 = 1")
+	}
+	if bounds[1] != nil {
+		query = query.Where("?", opts.rangeBound(query, field, column, "? <= ?", bounds[1]))
+	}
+	if bounds[0] != nil {
+		query = query.Where("?", opts.rangeBound(query, end, opts.column(query, end), "? >= ?", bounds[0]))
+	}
+	return query
+}
+
+// rangeBound returns the comparison of a column of a range with a bound, comparing dates only for date strings
+func (o Options) rangeBound(query *bun.SelectQuery, field string, column schema.QueryAppender, comparison string, bound interface{}) schema.QueryAppender {
+	if str, ok := bound.(string); ok {
+		if date, ok := o.DateLocale.dateValue(str); ok {
+			d := o.dialectName(query)
+			return schema.SafeQuery(comparison, []interface{}{dateExpr(d, o.ident(field)), dateExpr(d, o.sensitive(field, date))})
+		}
+	}
+	return schema.SafeQuery(comparison, []interface{}{column, o.bind(query, field, bound)})
+}
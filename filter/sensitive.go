@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"github.com/uptrace/bun/schema"
+)
+
+// redactedArg is the named argument of the formatters rendering the values of sensitive fields redacted
+const redactedArg = "bunql_redacted"
+
+// RedactingFormatter returns a formatter rendering the values bound to sensitive fields as the redacted string,
+// e.g. to log the SQL of a query. Queries rendered with any other formatter bind the actual values
+func RedactingFormatter(fmter schema.Formatter, redacted string) schema.Formatter {
+	return fmter.WithNamedArg(redactedArg, redacted)
+}
+
+// sensitiveValue is a value bound to a sensitive field, rendered redacted by the formatters of RedactingFormatter
+type sensitiveValue struct {
+	value interface{}
+}
+
+// AppendQuery implements schema.QueryAppender
+func (v sensitiveValue) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if redacted := fmter.FormatQuery("?" + redactedArg); redacted != "?"+redactedArg {
+		return append(b, redacted...), nil
+	}
+	return fmter.AppendQuery(b, "?", v.value), nil
+}
+
+// sensitive returns a value bound to a field, marked to be rendered redacted if the field is sensitive
+// The elements of lists are marked one by one, as lists are expanded into several values
+func (o Options) sensitive(field string, value interface{}) interface{} {
+	if !o.Fields[field].Sensitive {
+		return value
+	}
+	if values, ok := value.([]interface{}); ok {
+		marked := make([]interface{}, len(values))
+		for i, v := range values {
+			marked[i] = sensitiveValue{value: v}
+		}
+		return marked
+	}
+	return sensitiveValue{value: value}
+}
@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/operator"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+	"strings"
+)
+
+// Templates overrides the SQL generated for operators, per dialect and operator name (e.g. "like")
+// A template is a WHERE condition with ? placeholders for the column followed by the value: "? ILIKE ?" for most
+// operators, "? IN (?)" for list operators, whose value is expanded, "? BETWEEN ? AND ?" for the lower and upper
+// bounds of between, and "? IS NULL" for operators without a value
+type Templates map[dialect.Name]map[string]string
+
+// template returns the template overriding an operator on a dialect, if any
+func (t Templates) template(d dialect.Name, op string) (string, bool) {
+	template, ok := t[d][strings.ToLower(op)]
+	return template, ok
+}
+
+// applyTemplate applies a filter with an operator template
+func applyTemplate(query *bun.SelectQuery, template string, filter dto.Filter, column schema.QueryAppender, opts Options) *bun.SelectQuery {
+	value := filter.Value
+
+	switch operator.GetArity(filter.Operator) {
+	case operator.ArityNone:
+		return query.Where(template, column)
+	case operator.ArityList:
+		return query.Where(template, column, bun.In(opts.bind(query, filter.Field, listValues(value))))
+	case operator.ArityPair:
+		bounds := listValues(value)
+		if len(bounds) != 2 {
+			return query.Where("1 = 0")
+		}
+		return query.Where(template, column, opts.bind(query, filter.Field, bounds[0]), opts.bind(query, filter.Field, bounds[1]))
+	}
+
+	// Patterns get the same wildcards as with the built-in like
+	if op := strings.ToLower(filter.Operator); op == "like" || op == "ilike" {
+		value = opts.likePattern(filter.Field, value)
+	}
+	return query.Where(template, column, opts.bind(query, filter.Field, value))
+}
@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strings"
+)
+
+// DefaultParentField is the column referencing the parent row in self-referencing tables, unless configured otherwise
+const DefaultParentField = "parent_id"
+
+// applyHierarchy restricts the query to the descendants of a node of a self-referencing table, or to its ancestors,
+// the node itself excluded. The field is the key of the rows and the value the key of the node; the tree is walked
+// with a recursive CTE over the parent column of the field (FieldConfig.Parent, or parent_id). UNION stops at rows
+// already visited, so cycles in the data do not loop forever. SQL Server does not allow CTEs in subqueries, and the
+// filter matches no rows there, as it does on queries with no model
+func applyHierarchy(query *bun.SelectQuery, field string, value interface{}, ancestors bool, opts Options) *bun.SelectQuery {
+	model, ok := query.GetModel().(bun.TableModel)
+	if !ok || opts.dialectName(query) == dialect.MSSQL {
+		return query.Where("1 = 0")
+	}
+	table := model.Table().SQLName
+
+	parent := opts.Fields[field].Parent
+	if parent == "" {
+		parent = DefaultParentField
+	}
+
+	// The key is qualified with the alias of the query outside the CTE only
+	key := field
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		key = field[i+1:]
+	}
+
+	// Descendants are the rows whose parent is the node or a descendant, ancestors the parent of the node or of an ancestor
+	selected, linked := bun.Ident(key), bun.Ident(parent)
+	if ancestors {
+		selected, linked = bun.Ident(parent), bun.Ident(key)
+	}
+
+	return query.Where("? IN (WITH RECURSIVE bunql_tree (node) AS ("+
+		"SELECT ? FROM ? WHERE ? = ? "+
+		"UNION SELECT bunql_node.? FROM ? AS bunql_node JOIN bunql_tree ON bunql_node.? = bunql_tree.node"+
+		") SELECT node FROM bunql_tree WHERE node IS NOT NULL)",
+		bun.Ident(field),
+		selected, table, linked, opts.bind(query, field, value),
+		selected, table, linked,
+	)
+}
@@ -0,0 +1,111 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"strings"
+	"sync"
+)
+
+// FormatV1 is the version of the original filter JSON format, assumed when a payload declares no version
+const FormatV1 = "1"
+
+// ErrUnknownFormat is returned when a payload declares a version of the filter JSON format with no registered handler
+var ErrUnknownFormat = errors.New("unknown filter format version")
+
+// FormatHandler decodes a payload in one version of the filter JSON format into a filter group
+// The group is validated after decoding, so a handler only has to translate the shape of the payload
+type FormatHandler func(data []byte) (dto.FilterGroup, error)
+
+// formats holds the handlers of the versions of the filter JSON format registered with RegisterFormat
+// Version 1 is decoded by decodeV1 unless a handler is registered for it
+var formats = struct {
+	sync.RWMutex
+	handlers map[string]FormatHandler
+}{handlers: map[string]FormatHandler{}}
+
+// RegisterFormat registers the handler of a version of the filter JSON format, replacing any previous one
+// Payloads select the version with a top-level "version" field, e.g. {"version": 2, ...}
+func RegisterFormat(version string, handler FormatHandler) {
+	formats.Lock()
+	defer formats.Unlock()
+	formats.handlers[version] = handler
+}
+
+// v1Payload is a version 1 filter group along with the version field shared by all the formats
+type v1Payload struct {
+	Version interface{} `json:"version"`
+	dto.FilterGroup
+}
+
+// decodeFilters decodes a payload with the handler of the version it declares
+// Version 1 payloads, by far the most common, are decoded in a single pass
+func decodeFilters(data []byte) (dto.FilterGroup, error) {
+	trimmed := bytes.TrimSpace(data)
+	isObject := len(trimmed) > 0 && trimmed[0] == '{'
+
+	var payload v1Payload
+	var v1Err error
+	version := FormatV1
+	if isObject {
+		if v1Err = json.Unmarshal(data, &payload); v1Err == nil {
+			version = versionString(payload.Version)
+		} else {
+			// Payloads in other versions may not fit the version 1 shape, so only the version is decoded then
+			version = formatVersion(data)
+		}
+	}
+
+	formats.RLock()
+	handler, ok := formats.handlers[version]
+	formats.RUnlock()
+	switch {
+	case ok:
+		return handler(data)
+	case version != FormatV1:
+		return dto.FilterGroup{}, fmt.Errorf("%w '%s'", ErrUnknownFormat, version)
+	case isObject:
+		return payload.FilterGroup, v1Err
+	default:
+		return decodeV1(data)
+	}
+}
+
+// formatVersion returns the version declared by the "version" field of an object payload, or FormatV1 if there is none
+func formatVersion(data []byte) string {
+	var header struct {
+		Version interface{} `json:"version"`
+	}
+	if json.Unmarshal(data, &header) != nil {
+		return FormatV1
+	}
+	return versionString(header.Version)
+}
+
+// versionString returns a version given as a string or a number as a string, FormatV1 if there is none
+func versionString(version interface{}) string {
+	if version == nil {
+		return FormatV1
+	}
+	return fmt.Sprint(version)
+}
+
+// decodeV1 decodes the original format: a filter group, or a bare array of filters joined with AND
+func decodeV1(data []byte) (dto.FilterGroup, error) {
+	var group dto.FilterGroup
+
+	// A bare array of filters is accepted as an AND group, as sent by older clients
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &group.Filters); err != nil {
+			return dto.FilterGroup{}, err
+		}
+		return group, nil
+	}
+	if err := json.Unmarshal(data, &group); err != nil {
+		return dto.FilterGroup{}, err
+	}
+	return group, nil
+}
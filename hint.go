@@ -0,0 +1,78 @@
+package bunql
+
+import (
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strings"
+)
+
+// Hint is a planner hint for the queries of an endpoint where the planner picks the wrong index for user-driven
+// filters. Each field only applies to its dialect, so one Hint can carry the hints of every supported database
+// Hints are inserted into the SQL as is and must never be built from user input
+type Hint struct {
+	UseIndex    []string // MySQL: indexes of the USE INDEX hint
+	ForceIndex  []string // MySQL: indexes of the FORCE INDEX hint
+	IgnoreIndex []string // MySQL: indexes of the IGNORE INDEX hint
+	Plan        string   // Postgres: pg_hint_plan hints, sent as a leading /*+ ... */ comment, e.g. "IndexScan(u users_email_idx)"
+	Option      string   // MSSQL: query hints of the OPTION clause, e.g. "RECOMPILE"
+}
+
+// WithHint sets the planner hints of the query
+// Index hints are added by Apply; the Postgres and MSSQL hints wrap the whole statement, and are added by
+// ExecutePage or by running the query returned by Hinted
+func (q *BunQL) WithHint(hint Hint) *BunQL {
+	q.Hint = &hint
+	return q
+}
+
+// applyIndexHints adds the MySQL index hints to the query
+func (q *BunQL) applyIndexHints(query *bun.SelectQuery) *bun.SelectQuery {
+	if q.Hint == nil {
+		return query
+	}
+	if len(q.Hint.UseIndex) > 0 {
+		query = query.UseIndex(q.Hint.UseIndex...)
+	}
+	if len(q.Hint.ForceIndex) > 0 {
+		query = query.ForceIndex(q.Hint.ForceIndex...)
+	}
+	if len(q.Hint.IgnoreIndex) > 0 {
+		query = query.IgnoreIndex(q.Hint.IgnoreIndex...)
+	}
+	return query
+}
+
+// statementHint returns the template wrapping the statement with the Postgres or MSSQL hints, if any
+func (q *BunQL) statementHint(d dialect.Name) (string, bool) {
+	if q.Hint == nil {
+		return "", false
+	}
+
+	switch {
+	case d == dialect.PG && q.Hint.Plan != "":
+		// A */ would end the comment early
+		return "/*+ " + strings.ReplaceAll(q.Hint.Plan, "*/", "") + " */ ?", true
+	case d == dialect.MSSQL && q.Hint.Option != "":
+		return "? OPTION (" + q.Hint.Option + ")", true
+	default:
+		return "", false
+	}
+}
+
+// Hinted returns the query wrapped with the Postgres or MSSQL statement hints, to be run with Scan on the same
+// connection as the query. Without such hints the statement is left unchanged
+func (q *BunQL) Hinted(query *bun.SelectQuery) *bun.RawQuery {
+	template, ok := q.statementHint(q.dialectName(query))
+	if !ok {
+		template = "?"
+	}
+	return query.NewRaw(template, query).Conn(query.GetConn())
+}
+
+// dialectName returns the dialect the SQL is generated for
+func (q *BunQL) dialectName(query *bun.SelectQuery) dialect.Name {
+	if q.FilterOptions.Dialect != dialect.Invalid {
+		return q.FilterOptions.Dialect
+	}
+	return query.Dialect().Name()
+}
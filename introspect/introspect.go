@@ -0,0 +1,176 @@
+package introspect
+
+import (
+	"context"
+	"fmt"
+	"github.com/fxnoob/bunql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strings"
+)
+
+// Column describes a column of a table as reported by the database
+type Column struct {
+	Name     string        // Column name
+	DataType string        // Data type as reported by the database, e.g. "integer" or "character varying"
+	Type     dto.FieldType // Field type the data type maps to; empty if it has no equivalent
+	Nullable bool          // Whether the column accepts NULL
+	Indexed  bool          // Whether the column is the leading column of an index or of the primary key
+}
+
+// Table describes a table as reported by the database
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// columnRow is a row of the column catalog queries
+type columnRow struct {
+	Name       string `bun:"name"`
+	DataType   string `bun:"data_type"`
+	IsNullable string `bun:"is_nullable"`
+}
+
+// Inspect reads the columns of a table from information_schema, or from the table_info pragma on SQLite
+// It is meant to be called once at startup, e.g. to build a query policy for tables that have no Go model
+func Inspect(ctx context.Context, db bun.IDB, table string) (*Table, error) {
+	var query string
+	switch db.Dialect().Name() {
+	case dialect.SQLite:
+		query = `SELECT name, type AS data_type, CASE WHEN "notnull" = 0 THEN 'YES' ELSE 'NO' END AS is_nullable
+			FROM pragma_table_info(?) ORDER BY cid`
+	case dialect.PG:
+		query = `SELECT column_name AS name, data_type, is_nullable FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? ORDER BY ordinal_position`
+	case dialect.MySQL:
+		query = `SELECT column_name AS name, data_type, is_nullable FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`
+	case dialect.MSSQL:
+		query = `SELECT column_name AS name, data_type, is_nullable FROM information_schema.columns
+			WHERE table_schema = SCHEMA_NAME() AND table_name = ? ORDER BY ordinal_position`
+	default:
+		return nil, fmt.Errorf("schema introspection is not supported on %s", db.Dialect().Name())
+	}
+
+	var rows []columnRow
+	if err := db.NewRaw(query, table).Scan(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to read the columns of table '%s': %w", table, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("table '%s' not found", table)
+	}
+
+	indexed, err := indexedColumns(ctx, db, table)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Name: table}
+	for _, row := range rows {
+		t.Columns = append(t.Columns, Column{
+			Name:     row.Name,
+			DataType: row.DataType,
+			Type:     FieldType(row.DataType),
+			Nullable: strings.EqualFold(row.IsNullable, "YES"),
+			Indexed:  indexed[row.Name],
+		})
+	}
+	return t, nil
+}
+
+// indexedColumns returns the columns of a table that lead an index, including the primary key
+// Only the leading column of a multi-column index can be used on its own to avoid a full scan
+func indexedColumns(ctx context.Context, db bun.IDB, table string) (map[string]bool, error) {
+	var query string
+	switch db.Dialect().Name() {
+	case dialect.SQLite:
+		// An INTEGER PRIMARY KEY aliases the rowid and has no index of its own
+		query = `SELECT ii.name FROM pragma_index_list(?0) AS il, pragma_index_info(il.name) AS ii WHERE ii.seqno = 0
+			UNION SELECT name FROM pragma_table_info(?0) WHERE pk = 1`
+	case dialect.PG:
+		query = `SELECT a.attname FROM pg_index AS i
+			JOIN pg_attribute AS a ON a.attrelid = i.indrelid AND a.attnum = i.indkey[0]
+			WHERE i.indrelid = to_regclass(?)`
+	case dialect.MySQL:
+		query = `SELECT column_name FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = ? AND seq_in_index = 1`
+	case dialect.MSSQL:
+		query = `SELECT c.name FROM sys.index_columns AS ic
+			JOIN sys.columns AS c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+			WHERE ic.object_id = OBJECT_ID(?) AND ic.key_ordinal = 1`
+	}
+
+	var names []string
+	if err := db.NewRaw(query, table).Scan(ctx, &names); err != nil {
+		return nil, fmt.Errorf("failed to read the indexes of table '%s': %w", table, err)
+	}
+
+	indexed := make(map[string]bool, len(names))
+	for _, name := range names {
+		indexed[name] = true
+	}
+	return indexed, nil
+}
+
+// FieldType returns the field type a database data type maps to, or an empty type if it has no equivalent
+func FieldType(dataType string) dto.FieldType {
+	dataType = strings.ToLower(dataType)
+
+	switch {
+	case strings.Contains(dataType, "interval"), strings.Contains(dataType, "point"):
+		return ""
+	case strings.Contains(dataType, "bool"), dataType == "bit":
+		return dto.FieldBoolean
+	case strings.Contains(dataType, "numeric"), strings.Contains(dataType, "decimal"), strings.Contains(dataType, "money"):
+		return dto.FieldDecimal
+	case strings.Contains(dataType, "int"), strings.Contains(dataType, "real"), strings.Contains(dataType, "double"),
+		strings.Contains(dataType, "float"):
+		return dto.FieldNumber
+	case strings.Contains(dataType, "date"), strings.Contains(dataType, "time"):
+		return dto.FieldTime
+	case strings.Contains(dataType, "char"), strings.Contains(dataType, "text"), strings.Contains(dataType, "clob"),
+		dataType == "uuid", dataType == "uniqueidentifier":
+		return dto.FieldString
+	default:
+		return ""
+	}
+}
+
+// ColumnNames returns the names of the columns of the table
+func (t *Table) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// IndexedColumnNames returns the names of the indexed columns of the table
+func (t *Table) IndexedColumnNames() []string {
+	var names []string
+	for _, column := range t.Columns {
+		if column.Indexed {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
+
+// Policy returns a query policy allowing to filter and sort on every column of the table,
+// with filter values checked against the column types and warnings for the unindexed columns. Use it with bunql.NewWithPolicy, or register it for a model
+func (t *Table) Policy() bunql.Policy {
+	fields := map[string]dto.FieldConfig{}
+	for _, column := range t.Columns {
+		if column.Type != "" {
+			fields[column.Name] = dto.FieldConfig{Type: column.Type}
+		}
+	}
+
+	return bunql.Policy{
+		FilterFields:  t.ColumnNames(),
+		SortFields:    t.ColumnNames(),
+		Fields:        fields,
+		IndexedFields: t.IndexedColumnNames(),
+	}
+}
@@ -0,0 +1,76 @@
+package introspect
+
+import (
+	"context"
+	"database/sql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { sqldb.Close() })
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE products (
+		id INTEGER PRIMARY KEY NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		price DECIMAL(10, 2),
+		in_stock BOOLEAN,
+		released_at TIMESTAMP,
+		data BLOB
+	)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `CREATE INDEX products_name_price ON products (name, price)`)
+	require.NoError(t, err)
+
+	table, err := Inspect(ctx, db, "products")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Column{
+		{Name: "id", DataType: "INTEGER", Type: dto.FieldNumber, Nullable: false, Indexed: true},
+		{Name: "name", DataType: "VARCHAR(100)", Type: dto.FieldString, Nullable: false, Indexed: true},
+		{Name: "price", DataType: "DECIMAL(10, 2)", Type: dto.FieldDecimal, Nullable: true},
+		{Name: "in_stock", DataType: "BOOLEAN", Type: dto.FieldBoolean, Nullable: true},
+		{Name: "released_at", DataType: "TIMESTAMP", Type: dto.FieldTime, Nullable: true},
+		{Name: "data", DataType: "BLOB", Type: "", Nullable: true},
+	}, table.Columns)
+
+	policy := table.Policy()
+	assert.Equal(t, []string{"id", "name", "price", "in_stock", "released_at", "data"}, policy.FilterFields)
+	assert.Equal(t, policy.FilterFields, policy.SortFields)
+	assert.Equal(t, dto.FieldConfig{Type: dto.FieldDecimal}, policy.Fields["price"])
+	assert.NotContains(t, policy.Fields, "data")
+	assert.Equal(t, []string{"id", "name"}, policy.IndexedFields)
+
+	_, err = Inspect(ctx, db, "missing")
+	assert.EqualError(t, err, "table 'missing' not found")
+}
+
+func TestFieldType(t *testing.T) {
+	tests := map[string]dto.FieldType{
+		"character varying":           dto.FieldString,
+		"uuid":                        dto.FieldString,
+		"bigint":                      dto.FieldNumber,
+		"double precision":            dto.FieldNumber,
+		"numeric(10,2)":               dto.FieldDecimal,
+		"money":                       dto.FieldDecimal,
+		"timestamp without time zone": dto.FieldTime,
+		"date":                        dto.FieldTime,
+		"bit":                         dto.FieldBoolean,
+		"interval":                    "",
+		"point":                       "",
+		"jsonb":                       "",
+	}
+
+	for dataType, expected := range tests {
+		assert.Equal(t, expected, FieldType(dataType), dataType)
+	}
+}
@@ -0,0 +1,137 @@
+package bunql
+
+import (
+	"context"
+	"fmt"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"iter"
+)
+
+// DefaultIteratePageSize is the page size used by the iterators when the BunQL has no pagination
+const DefaultIteratePageSize = 100
+
+// Iterate returns an iterator over every row matched by the BunQL, fetching successive pages with LIMIT/OFFSET until exhausted
+// The iteration starts at the configured page and uses the configured page size (DefaultIteratePageSize if not set)
+// The first error stops the iteration and is yielded along with a zero value
+func Iterate[T any](ctx context.Context, db bun.IDB, ql *BunQL) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page, pageSize := iteratePaging(ql)
+
+		for {
+			pageQL := *ql
+			pageQL.Pagination = &dto.Pagination{Page: page, PageSize: pageSize}
+
+			items, err := fetchPage[T](ctx, db, &pageQL)
+			if !yieldPage(items, err, yield) {
+				return
+			}
+
+			if len(items) < pageSize {
+				return
+			}
+			page++
+		}
+	}
+}
+
+// IterateKeyset returns an iterator over every row matched by the BunQL, fetching successive pages with keyset pagination
+// Instead of an offset, each page is fetched with a WHERE condition on the sort values of the last row seen, so the cost
+// of a page does not grow with the position in the dataset. column must be unique; it is appended to the sort (ascending)
+// as a tie-breaker unless the BunQL already sorts by it
+func IterateKeyset[T any](ctx context.Context, db bun.IDB, ql *BunQL, column string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		sortFields := ql.Sort
+		if !containsSortField(sortFields, column) {
+			sortFields = append(append([]dto.SortField{}, sortFields...), dto.SortField{Field: column, Direction: "asc"})
+		}
+
+		fields, err := sortFieldsOf[T](db.Dialect(), sortFields)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		_, pageSize := iteratePaging(ql)
+
+		var position *cursor.Cursor
+		for {
+			pageQL := *ql
+			pageQL.Sort = sortFields
+			pageQL.Cursor = position
+			pageQL.Pagination = &dto.Pagination{Page: 1, PageSize: pageSize}
+
+			items, err := fetchPage[T](ctx, db, &pageQL)
+			if !yieldPage(items, err, yield) {
+				return
+			}
+
+			if len(items) < pageSize {
+				return
+			}
+			last := cursorAt(sortFields, fields, &items[len(items)-1])
+			position = &last
+		}
+	}
+}
+
+// containsSortField checks if a field is in a list of sort fields
+func containsSortField(sortFields []dto.SortField, field string) bool {
+	for _, sort := range sortFields {
+		if sort.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// iteratePaging returns the starting page and the page size used by the iterators
+func iteratePaging(ql *BunQL) (int, int) {
+	var paging dto.Pagination
+	if ql.Pagination != nil {
+		paging = *ql.Pagination
+	}
+	paging = paging.Normalize(dto.Pagination{Page: 1, PageSize: DefaultIteratePageSize}, 0)
+	return paging.Page, paging.PageSize
+}
+
+// fetchPage applies the BunQL to a new query over T and scans one page of results
+func fetchPage[T any](ctx context.Context, db bun.IDB, ql *BunQL) ([]T, error) {
+	if err := ql.validateWindowColumns(); err != nil {
+		return nil, err
+	}
+	release, err := ql.ExecutionLimiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx = ql.ContextWithSummary(ctx)
+	query := ql.onReplica(ql.Apply(ctx, db.NewSelect().Model((*T)(nil))))
+
+	var items []T
+	if err := query.Scan(ctx, &items); err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	return items, nil
+}
+
+// yieldPage yields the items of a page, or the error that prevented fetching it
+// It reports whether the iteration should continue
+func yieldPage[T any](items []T, err error, yield func(T, error) bool) bool {
+	if err != nil {
+		var zero T
+		yield(zero, err)
+		return false
+	}
+
+	for _, item := range items {
+		if !yield(item, nil) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/sorting"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+	"reflect"
+	"slices"
+)
+
+// ExecuteCursorPage applies the BunQL to the query and fetches one page with keyset pagination
+// It returns the results along with signed tokens for the previous and next pages, which are nil at either end.
+// When the cursor is a backward cursor, the rows before it are fetched with the inverted sort and re-reversed,
+// so the results are always in the requested order.
+// The sort must end with a unique field (e.g. the primary key) so that every row has a distinct position
+// With a context tracking the debug information (see TrackDebug), the metadata includes it
+func ExecuteCursorPage[T any](ctx context.Context, ql *BunQL, query *bun.SelectQuery, secret []byte) ([]T, CursorPaginationMetadataOutput, error) {
+	results, metadata, err := executeCursorPage[T](ctx, ql, query, secret)
+	if err == nil {
+		metadata.Debug = DebugFromContext(ctx)
+	}
+	ql.audit(ctx, len(results), -1, err)
+	ql.afterExecute(ctx, len(results), -1, err)
+	return results, metadata, err
+}
+
+// executeCursorPage fetches a page with keyset pagination and the cursors around it
+func executeCursorPage[T any](ctx context.Context, ql *BunQL, query *bun.SelectQuery, secret []byte) ([]T, CursorPaginationMetadataOutput, error) {
+	ctx = ql.ContextWithSummary(ctx)
+	var metadata CursorPaginationMetadataOutput
+
+	if err := ql.admit(ctx); err != nil {
+		return nil, metadata, err
+	}
+	if err := ql.beforeExecute(ctx); err != nil {
+		return nil, metadata, err
+	}
+	release, err := ql.ExecutionLimiter.acquire(ctx)
+	if err != nil {
+		return nil, metadata, err
+	}
+	defer release()
+	if len(ql.Sort) == 0 {
+		return nil, metadata, errors.New("cursor pagination requires a sort")
+	}
+	// Cursors hold the columns the sort aliases expand to, and the primary key breaking ties
+	sort := tieBreak(query, sorting.ExpandAliases(ql.Sort, ql.SortAliases))
+	if ql.Cursor != nil && !ql.Cursor.Matches(sort) {
+		return nil, metadata, errors.New("cursor does not match the requested sort")
+	}
+
+	fields, err := sortFieldsOf[T](query.Dialect(), sort)
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	// Fetch one extra row to know whether there is a page beyond this one
+	// The row cap bounds the page size rather than the extra row, which is never returned
+	_, pageSize := iteratePaging(ql)
+	if ql.MaxRows > 0 && pageSize > ql.MaxRows {
+		pageSize = ql.MaxRows
+	}
+	pageQL := *ql
+	pageQL.Pagination = &dto.Pagination{Page: 1, PageSize: pageSize + 1}
+	pageQL.MaxRows = 0
+
+	var results []T
+	if err := ql.onReplica(pageQL.Apply(context.WithValue(ctx, keysetKey{}, true), query)).Scan(ctx, &results); err != nil {
+		return nil, metadata, fmt.Errorf("failed to execute main query: %w", err)
+	}
+
+	more := len(results) > pageSize
+	if more {
+		results = results[:pageSize]
+	}
+
+	backward := ql.Cursor != nil && ql.Cursor.Backward
+	if backward {
+		slices.Reverse(results)
+	}
+	if len(results) == 0 {
+		return results, metadata, nil
+	}
+
+	// Going forward there are rows before the page whenever it started from a cursor, and going backward
+	// there are rows after it; the extra row tells whether there are rows in the direction of travel
+	hasPrev, hasNext := ql.Cursor != nil, more
+	if backward {
+		hasPrev, hasNext = more, true
+	}
+
+	if hasPrev {
+		prev := cursorAt(sort, fields, &results[0])
+		prev.Backward = true
+		token, err := cursor.Encode(prev, secret)
+		if err != nil {
+			return nil, metadata, err
+		}
+		metadata.Prev = &token
+	}
+
+	if hasNext {
+		token, err := cursor.Encode(cursorAt(sort, fields, &results[len(results)-1]), secret)
+		if err != nil {
+			return nil, metadata, err
+		}
+		metadata.Next = &token
+	}
+
+	return results, metadata, nil
+}
+
+// sortFieldsOf looks up the model fields of T, a struct or a pointer to one, for the sort fields
+// Nullable fields must replace their NULLs with nullsAs
+func sortFieldsOf[T any](d schema.Dialect, sortFields []dto.SortField) ([]*schema.Field, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cursor pagination requires a struct model, got %s", typ)
+	}
+	table := d.Tables().Get(typ)
+
+	fields := make([]*schema.Field, len(sortFields))
+	for i, sort := range sortFields {
+		fields[i] = table.LookupField(sort.Field)
+		if fields[i] == nil {
+			return nil, fmt.Errorf("sort field '%s' is not a field of %s", sort.Field, table.TypeName)
+		}
+		// A NULL key compares with nothing, so the rows after it would never be reached
+		if sort.NullsAs == nil && isNullable(fields[i]) {
+			return nil, fmt.Errorf("cursor pagination cannot sort on the nullable field '%s' without nullsAs", sort.Field)
+		}
+	}
+
+	return fields, nil
+}
+
+// isNullable reports whether the column of a model field may hold NULLs: pointers, fields storing their zero value
+// as NULL and the sql.Null types, unless the field is the primary key or tagged notnull
+func isNullable(field *schema.Field) bool {
+	if field.IsPK || field.NotNull {
+		return false
+	}
+	if field.IsPtr || field.NullZero || field.IndirectType == reflect.TypeOf(bun.NullTime{}) {
+		return true
+	}
+	if field.IndirectType.Kind() == reflect.Struct {
+		valid, ok := field.IndirectType.FieldByName("Valid")
+		return ok && valid.Type.Kind() == reflect.Bool
+	}
+	return false
+}
+
+// cursorAt creates the cursor positioned at the given row, dereferencing rows that are pointers to structs
+// Pointer values are dereferenced too, NULLs standing for the nullsAs of their sort field
+func cursorAt[T any](sortFields []dto.SortField, fields []*schema.Field, row *T) cursor.Cursor {
+	strct := reflect.ValueOf(row).Elem()
+	for strct.Kind() == reflect.Pointer {
+		strct = strct.Elem()
+	}
+
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value := field.Value(strct)
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				values[i] = sortFields[i].NullsAs
+				continue
+			}
+			value = value.Elem()
+		}
+		values[i] = value.Interface()
+	}
+
+	return cursor.Cursor{Sort: sortFields, Values: values}
+}
@@ -0,0 +1,56 @@
+package bunql
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecutionLimiter bounds the number of executions running at once, e.g. to protect the connection pool when a
+// dashboard fires dozens of filtered widgets simultaneously. Executions beyond the limit wait for a slot until their
+// context is done. An ExecutionLimiter is safe for concurrent use: the clones of a template share its limiter, and
+// BunQLs given the same limiter share the limit
+type ExecutionLimiter struct {
+	slots chan struct{}
+}
+
+// NewExecutionLimiter creates a limiter letting n executions run at once, at least one
+func NewExecutionLimiter(n int) *ExecutionLimiter {
+	return &ExecutionLimiter{slots: make(chan struct{}, max(n, 1))}
+}
+
+// WithExecutionLimiter makes ExecutePage, ExecuteCursorPage and the pages of Iterate and IterateKeyset wait for a
+// slot of the limiter before executing. Queries executed by the caller, e.g. with ExecuteWithCount, don't wait for a
+// slot unless they are executed through the limiter's Do
+func (q *BunQL) WithExecutionLimiter(l *ExecutionLimiter) *BunQL {
+	q.ExecutionLimiter = l
+	return q
+}
+
+// InFlight returns the number of executions holding a slot
+func (l *ExecutionLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Do waits for a slot of the limiter and calls fn holding it, e.g. to bound ExecuteWithCount and the other executions
+// of queries applied by the caller. A nil limiter calls fn right away
+func (l *ExecutionLimiter) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// acquire waits for a slot of the limiter, if any, returning the function releasing it
+func (l *ExecutionLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for an execution slot: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,42 @@
+package bunql
+
+import (
+	"context"
+	"github.com/uptrace/bun"
+	"slices"
+)
+
+// Applier applies a BunQL to a query
+type Applier func(ctx context.Context, ql *BunQL, query *bun.SelectQuery) *bun.SelectQuery
+
+// Middleware wraps the application of a BunQL to a query, for cross-cutting concerns such as injecting tenant
+// filters, rewriting fields or recording metrics. It can change the query or a copy of the BunQL before calling
+// next, and the resulting query after
+type Middleware func(next Applier) Applier
+
+// WithMiddleware adds middleware run by Apply and ApplyWithCount, the first one added being the outermost
+// With ApplyWithCount the middleware runs for both the main query and the count query, where only the filters
+// are applied
+func (q *BunQL) WithMiddleware(middleware ...Middleware) *BunQL {
+	q.Middleware = append(slices.Clip(q.Middleware), middleware...)
+	return q
+}
+
+// chain wraps an Applier with the middleware of the apply plugins and of the BunQL, the plugins being outermost
+func (q *BunQL) chain(applier Applier) Applier {
+	middleware := append(pluginMiddleware(), q.Middleware...)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		applier = middleware[i](applier)
+	}
+	return applier
+}
+
+// countKey is the context key marking the application of a BunQL to a count query
+type countKey struct{}
+
+// IsCount reports whether the context is the one of the count query of ApplyWithCount, for middleware that
+// treats the count query differently
+func IsCount(ctx context.Context) bool {
+	count, _ := ctx.Value(countKey{}).(bool)
+	return count
+}
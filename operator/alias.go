@@ -0,0 +1,109 @@
+package operator
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Alias is another spelling of an operator, such as "ne" for "neq", accepted from clients that emit it
+type Alias struct {
+	Name       string `json:"name"`                 // Spelling accepted in filters
+	Operator   string `json:"operator"`             // Operator the alias stands for
+	Deprecated bool   `json:"deprecated,omitempty"` // Whether using the alias logs a deprecation warning
+}
+
+// aliases holds the registered aliases, by lowercase name, and the logger of the deprecation warnings
+var aliases = struct {
+	sync.RWMutex
+	byName map[string]Alias
+	logger *slog.Logger
+}{byName: map[string]Alias{}}
+
+// RegisterAlias registers an alias of a supported operator, replacing any alias with the same name
+func RegisterAlias(name, op string) error {
+	return registerAlias(Alias{Name: name, Operator: op})
+}
+
+// RegisterDeprecatedAlias registers an alias of a supported operator whose use logs a deprecation warning,
+// to find the clients still sending it
+func RegisterDeprecatedAlias(name, op string) error {
+	return registerAlias(Alias{Name: name, Operator: op, Deprecated: true})
+}
+
+// registerAlias validates and registers an alias
+func registerAlias(alias Alias) error {
+	alias.Name = strings.ToLower(alias.Name)
+	alias.Operator = strings.ToLower(alias.Operator)
+	if _, ok := operatorMap[alias.Operator]; !ok {
+		return fmt.Errorf("cannot alias unknown operator '%s'", alias.Operator)
+	}
+	if _, ok := operatorMap[alias.Name]; ok || alias.Name == "" {
+		return fmt.Errorf("invalid alias name '%s'", alias.Name)
+	}
+
+	aliases.Lock()
+	defer aliases.Unlock()
+	aliases.byName[alias.Name] = alias
+	return nil
+}
+
+// Aliases returns the registered aliases, sorted by name
+func Aliases() []Alias {
+	aliases.RLock()
+	defer aliases.RUnlock()
+	list := make([]Alias, 0, len(aliases.byName))
+	for _, alias := range aliases.byName {
+		list = append(list, alias)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// SetDeprecationLogger sets the logger of the deprecation warnings, slog.Default() being used if nil
+func SetDeprecationLogger(logger *slog.Logger) {
+	aliases.Lock()
+	defer aliases.Unlock()
+	aliases.logger = logger
+}
+
+// Canonical returns the lowercase name of an operator, resolving aliases
+func Canonical(op string) string {
+	op = strings.ToLower(op)
+	if _, ok := operatorMap[op]; ok {
+		return op
+	}
+	aliases.RLock()
+	defer aliases.RUnlock()
+	if alias, ok := aliases.byName[op]; ok {
+		return alias.Operator
+	}
+	return op
+}
+
+// Resolve returns the lowercase name of an operator like Canonical, logging a warning for deprecated aliases
+// It is used when parsing filters sent by clients
+func Resolve(op string) string {
+	name := strings.ToLower(op)
+	if _, ok := operatorMap[name]; ok {
+		return name
+	}
+
+	aliases.RLock()
+	alias, ok := aliases.byName[name]
+	logger := aliases.logger
+	aliases.RUnlock()
+	if !ok {
+		return name
+	}
+
+	if alias.Deprecated {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Warn("deprecated filter operator alias", "alias", op, "operator", alias.Operator)
+	}
+	return alias.Operator
+}
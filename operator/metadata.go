@@ -0,0 +1,158 @@
+package operator
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"sort"
+)
+
+// Metadata documents an operator
+type Metadata struct {
+	Arity       Arity           `json:"arity"`                // Shape of the value
+	ValueTypes  []dto.FieldType `json:"valueTypes,omitempty"` // Types of the fields the operator applies to; any type if empty
+	Description string          `json:"description"`          // Short description, e.g. "Greater than"
+	Example     interface{}     `json:"example,omitempty"`    // Example value, for a field of any type
+}
+
+// comparableTypes are the field types that are ordered
+var comparableTypes = []dto.FieldType{dto.FieldNumber, dto.FieldDecimal, dto.FieldTime, dto.FieldString}
+
+// textTypes are the field types matched against patterns
+var textTypes = []dto.FieldType{dto.FieldString}
+
+// booleanTypes are the field types that are true or false
+var booleanTypes = []dto.FieldType{dto.FieldBoolean}
+
+// Metadata of the known operators, the arity being taken from arityMap
+var metadataMap = map[string]Metadata{
+	"eq":              {Description: "Equal to", Example: 30},
+	"neq":             {Description: "Not equal to", Example: 30},
+	"gt":              {ValueTypes: comparableTypes, Description: "Greater than", Example: 20},
+	"gte":             {ValueTypes: comparableTypes, Description: "Greater than or equal to", Example: 21},
+	"lt":              {ValueTypes: comparableTypes, Description: "Less than", Example: 50},
+	"lte":             {ValueTypes: comparableTypes, Description: "Less than or equal to", Example: 49},
+	"like":            {ValueTypes: textTypes, Description: "Matches a LIKE pattern", Example: "J%"},
+	"ilike":           {ValueTypes: textTypes, Description: "Matches a LIKE pattern, ignoring case", Example: "j%"},
+	"regex":           {ValueTypes: textTypes, Description: "Matches a regular expression", Example: "^J"},
+	"similar":         {ValueTypes: textTypes, Description: "Trigram similarity (typo-tolerant)", Example: "Jhon"},
+	"in":              {Description: "In a list of values", Example: []interface{}{20, 30, 40}},
+	"notin":           {Description: "Not in a list of values", Example: []interface{}{20, 30, 40}},
+	"isnull":          {Description: "Is NULL"},
+	"isnotnull":       {Description: "Is NOT NULL"},
+	"istrue":          {ValueTypes: booleanTypes, Description: "Is true"},
+	"isfalse":         {ValueTypes: booleanTypes, Description: "Is false"},
+	"between":         {ValueTypes: comparableTypes, Description: "Between two values, inclusive; a null bound leaves the range open", Example: []interface{}{20, 30}},
+	"overlaps":        {ValueTypes: comparableTypes, Description: "Range from the field to its end field overlapping two values, inclusive", Example: []interface{}{20, 30}},
+	"distinctfrom":    {Description: "Not equal, treating NULL as a value", Example: 30},
+	"notdistinctfrom": {Description: "Equal, treating NULL as a value", Example: 30},
+	"anyeq":           {Description: "Equal to any of a list of values", Example: []interface{}{20, 30, 40}},
+	"anyneq":          {Description: "Not equal to any of a list of values", Example: []interface{}{20, 30, 40}},
+	"anygt":           {ValueTypes: comparableTypes, Description: "Greater than any of a list of values", Example: []interface{}{20, 30}},
+	"anygte":          {ValueTypes: comparableTypes, Description: "Greater than or equal to any of a list of values", Example: []interface{}{20, 30}},
+	"anylt":           {ValueTypes: comparableTypes, Description: "Less than any of a list of values", Example: []interface{}{20, 30}},
+	"anylte":          {ValueTypes: comparableTypes, Description: "Less than or equal to any of a list of values", Example: []interface{}{20, 30}},
+	"alleq":           {Description: "Equal to all of a list of values", Example: []interface{}{20, 20}},
+	"allneq":          {Description: "Not equal to any value of a list", Example: []interface{}{20, 30, 40}},
+	"allgt":           {ValueTypes: comparableTypes, Description: "Greater than all of a list of values", Example: []interface{}{20, 30}},
+	"allgte":          {ValueTypes: comparableTypes, Description: "Greater than or equal to all of a list of values", Example: []interface{}{20, 30}},
+	"alllt":           {ValueTypes: comparableTypes, Description: "Less than all of a list of values", Example: []interface{}{20, 30}},
+	"alllte":          {ValueTypes: comparableTypes, Description: "Less than or equal to all of a list of values", Example: []interface{}{20, 30}},
+	"descendantof":    {Description: "Descendant of a node of a tree", Example: 1},
+	"ancestorof":      {Description: "Ancestor of a node of a tree", Example: 7},
+	"nearby": {
+		Description: "Point within a radius (meters)",
+		Example:     map[string]interface{}{"lat": 52.5, "lng": 13.4, "radius": 1000},
+	},
+	"withinbbox": {
+		Description: "Point within a bounding box",
+		Example:     map[string]interface{}{"minLat": 52.3, "minLng": 13.1, "maxLat": 52.7, "maxLng": 13.8},
+	},
+}
+
+// Example values of each field type, the first one being used for single values
+var sampleValues = map[dto.FieldType][]interface{}{
+	dto.FieldString:  {"John", "Jane"},
+	dto.FieldNumber:  {20, 30},
+	dto.FieldBoolean: {true, false},
+	dto.FieldTime:    {"2024-01-01", "2024-01-31"},
+	dto.FieldDecimal: {"10.10", "20.00"},
+}
+
+// GetMetadata returns the metadata of an operator
+func GetMetadata(op string) (Metadata, bool) {
+	op = Canonical(op)
+	metadata, ok := metadataMap[op]
+	if !ok {
+		return Metadata{}, false
+	}
+	metadata.Arity = GetArity(op)
+	return metadata, true
+}
+
+// AppliesTo reports whether an operator applies to fields of the given type
+// Every operator applies to fields with no type, while object values, such as those of the geospatial operators,
+// are only suggested for them
+func AppliesTo(op string, fieldType dto.FieldType) bool {
+	metadata, ok := GetMetadata(op)
+	if !ok {
+		return false
+	}
+	if fieldType == "" {
+		return true
+	}
+	if metadata.Arity == ArityObject {
+		return false
+	}
+	if len(metadata.ValueTypes) == 0 {
+		return true
+	}
+	for _, t := range metadata.ValueTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// ExampleValue returns an example value of an operator for a field of the given type
+func ExampleValue(op string, fieldType dto.FieldType) interface{} {
+	metadata, ok := GetMetadata(op)
+	if !ok {
+		return nil
+	}
+	samples, typed := sampleValues[fieldType]
+	switch {
+	case metadata.Arity == ArityNone:
+		return nil
+	case !typed, metadata.Arity == ArityObject, isPatternOperator(metadata):
+		return metadata.Example
+	case metadata.Arity == ArityList, metadata.Arity == ArityPair:
+		return append([]interface{}{}, samples...)
+	default:
+		return samples[0]
+	}
+}
+
+// isPatternOperator reports whether an operator only applies to text, its value being a pattern rather than a value
+// of the field
+func isPatternOperator(metadata Metadata) bool {
+	return len(metadata.ValueTypes) == 1 && metadata.ValueTypes[0] == dto.FieldString
+}
+
+// Examples returns an example filter on a field for each operator applying to the field type, sorted by operator
+// Only the given operators are considered, or every supported operator if none is given
+func Examples(field string, fieldType dto.FieldType, operators ...string) []dto.Filter {
+	if len(operators) == 0 {
+		operators = operatorNames()
+	}
+
+	var examples []dto.Filter
+	for _, op := range operators {
+		op = Canonical(op)
+		if !AppliesTo(op, fieldType) {
+			continue
+		}
+		examples = append(examples, dto.Filter{Field: field, Operator: op, Value: ExampleValue(op, fieldType)})
+	}
+	sort.Slice(examples, func(i, j int) bool { return examples[i].Operator < examples[j].Operator })
+	return examples
+}
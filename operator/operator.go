@@ -1,26 +1,135 @@
 package operator
 
-import "strings"
+import (
+	"github.com/uptrace/bun/dialect"
+	"sort"
+)
+
+// Arity describes the shape of the value an operator expects
+type Arity int
+
+const (
+	// ArityScalar operators compare against a single value
+	ArityScalar Arity = iota
+	// ArityList operators compare against a list of values
+	ArityList
+	// ArityPair operators compare against a list of exactly two values
+	ArityPair
+	// ArityNone operators take no value
+	ArityNone
+	// ArityObject operators take an object value
+	ArityObject
+)
+
+// String returns a human-readable description of the value shape
+func (a Arity) String() string {
+	switch a {
+	case ArityList:
+		return "a list of values"
+	case ArityPair:
+		return "a pair of values"
+	case ArityNone:
+		return "no value"
+	case ArityObject:
+		return "an object value"
+	default:
+		return "a single value"
+	}
+}
+
+// MarshalText encodes the value shape as "scalar", "list", "pair", "none" or "object"
+func (a Arity) MarshalText() ([]byte, error) {
+	switch a {
+	case ArityList:
+		return []byte("list"), nil
+	case ArityPair:
+		return []byte("pair"), nil
+	case ArityNone:
+		return []byte("none"), nil
+	case ArityObject:
+		return []byte("object"), nil
+	default:
+		return []byte("scalar"), nil
+	}
+}
+
+// OperatorInfo describes a supported operator, so that clients can build filters from the capabilities of the server
+type OperatorInfo struct {
+	Name     string   `json:"name"`     // Operator name used in filters, e.g. "gte"
+	SQL      string   `json:"sql"`      // SQL operator, e.g. ">="
+	Dialects []string `json:"dialects"` // Dialects supporting the operator, e.g. "pg" or "mysql"
+	Metadata
+}
 
 // Known operator map
 var operatorMap = map[string]string{
-	"eq":        "=",
-	"neq":       "!=",
-	"gt":        ">",
-	"gte":       ">=",
-	"lt":        "<",
-	"lte":       "<=",
-	"like":      "LIKE",
-	"in":        "IN",
-	"notin":     "NOT IN",
-	"isnull":    "IS NULL",
-	"isnotnull": "IS NOT NULL",
-	"between":   "BETWEEN",
+	"eq":              "=",
+	"neq":             "!=",
+	"gt":              ">",
+	"gte":             ">=",
+	"lt":              "<",
+	"lte":             "<=",
+	"like":            "LIKE",
+	"ilike":           "ILIKE",
+	"regex":           "REGEXP",
+	"in":              "IN",
+	"notin":           "NOT IN",
+	"isnull":          "IS NULL",
+	"isnotnull":       "IS NOT NULL",
+	"istrue":          "IS TRUE",
+	"isfalse":         "IS FALSE",
+	"between":         "BETWEEN",
+	"overlaps":        "OVERLAPS",
+	"similar":         "SIMILARITY",
+	"nearby":          "NEARBY",
+	"withinbbox":      "WITHIN BBOX",
+	"distinctfrom":    "IS DISTINCT FROM",
+	"notdistinctfrom": "IS NOT DISTINCT FROM",
+	"anyeq":           "= ANY",
+	"anyneq":          "!= ANY",
+	"anygt":           "> ANY",
+	"anygte":          ">= ANY",
+	"anylt":           "< ANY",
+	"anylte":          "<= ANY",
+	"alleq":           "= ALL",
+	"allneq":          "!= ALL",
+	"allgt":           "> ALL",
+	"allgte":          ">= ALL",
+	"alllt":           "< ALL",
+	"alllte":          "<= ALL",
+	"descendantof":    "DESCENDANT OF",
+	"ancestorof":      "ANCESTOR OF",
+}
+
+// Value shapes of the operators that don't take a single value
+var arityMap = map[string]Arity{
+	"in":         ArityList,
+	"notin":      ArityList,
+	"between":    ArityPair,
+	"overlaps":   ArityPair,
+	"isnull":     ArityNone,
+	"isnotnull":  ArityNone,
+	"istrue":     ArityNone,
+	"isfalse":    ArityNone,
+	"anyeq":      ArityList,
+	"anyneq":     ArityList,
+	"anygt":      ArityList,
+	"anygte":     ArityList,
+	"anylt":      ArityList,
+	"anylte":     ArityList,
+	"alleq":      ArityList,
+	"allneq":     ArityList,
+	"allgt":      ArityList,
+	"allgte":     ArityList,
+	"alllt":      ArityList,
+	"alllte":     ArityList,
+	"nearby":     ArityObject,
+	"withinbbox": ArityObject,
 }
 
 // GetOperator returns the SQL operator for a given operator name
 func GetOperator(op string) string {
-	op = strings.ToLower(op)
+	op = Canonical(op)
 	if sqlOp, ok := operatorMap[op]; ok {
 		return sqlOp
 	}
@@ -29,16 +138,77 @@ func GetOperator(op string) string {
 
 // IsValidOperator checks if an operator is valid
 func IsValidOperator(op string) bool {
-	op = strings.ToLower(op)
+	op = Canonical(op)
 	_, ok := operatorMap[op]
 	return ok
 }
 
-// GetSupportedOperators returns a list of all supported operator
-func GetSupportedOperators() []string {
-	operators := make([]string, 0, len(operatorMap))
-	for op := range operatorMap {
-		operators = append(operators, op)
+// Dialects supported by the operators that are not supported by every dialect
+// SQLite has no similarity or spatial support, and needs a regexp function to be registered for regex
+// MSSQL does not allow the recursive CTEs of the hierarchy operators in subqueries
+var dialectMap = map[string][]dialect.Name{
+	"similar":      {dialect.PG},
+	"nearby":       {dialect.PG, dialect.MySQL, dialect.MSSQL},
+	"withinbbox":   {dialect.PG, dialect.MySQL, dialect.MSSQL},
+	"descendantof": {dialect.PG, dialect.MySQL, dialect.SQLite},
+	"ancestorof":   {dialect.PG, dialect.MySQL, dialect.SQLite},
+}
+
+// allDialects are the dialects supporting the other operators
+var allDialects = []dialect.Name{dialect.PG, dialect.MySQL, dialect.SQLite, dialect.MSSQL}
+
+// GetSupportedOperators returns the supported operators with their details, sorted by name
+func GetSupportedOperators() []OperatorInfo {
+	names := operatorNames()
+	operators := make([]OperatorInfo, 0, len(names))
+	for _, op := range names {
+		metadata, _ := GetMetadata(op)
+		info := OperatorInfo{Name: op, SQL: operatorMap[op], Metadata: metadata}
+		for _, d := range dialectsOf(op) {
+			info.Dialects = append(info.Dialects, d.String())
+		}
+		operators = append(operators, info)
 	}
 	return operators
 }
+
+// IsSupportedOn reports whether an operator is supported by a dialect
+// Unsupported operators compile to a fallback, e.g. a LIKE match for similar, or to a condition matching no rows
+func IsSupportedOn(op string, d dialect.Name) bool {
+	for _, supported := range dialectsOf(Canonical(op)) {
+		if supported == d {
+			return true
+		}
+	}
+	return false
+}
+
+// dialectsOf returns the dialects supporting a known operator
+func dialectsOf(op string) []dialect.Name {
+	if dialects, ok := dialectMap[op]; ok {
+		return dialects
+	}
+	if _, ok := operatorMap[op]; ok {
+		return allDialects
+	}
+	return nil
+}
+
+// operatorNames returns the names of the known operators, sorted
+func operatorNames() []string {
+	names := make([]string, 0, len(operatorMap))
+	for op := range operatorMap {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetArity returns the shape of the value expected by an operator
+func GetArity(op string) Arity {
+	op = Canonical(op)
+	if arity, ok := arityMap[op]; ok {
+		return arity
+	}
+	return ArityScalar
+}
@@ -0,0 +1,87 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun/dialect"
+	"log/slog"
+	"testing"
+)
+
+func TestGetSupportedOperators(t *testing.T) {
+	operators := GetSupportedOperators()
+	require.Len(t, operators, len(operatorMap))
+	for i := 1; i < len(operators); i++ {
+		assert.Less(t, operators[i-1].Name, operators[i].Name)
+	}
+
+	byName := map[string]OperatorInfo{}
+	for _, op := range operators {
+		assert.NotEmpty(t, op.Description, op.Name)
+		assert.NotEmpty(t, op.Dialects, op.Name)
+		byName[op.Name] = op
+	}
+
+	assert.Equal(t, ">=", byName["gte"].SQL)
+	assert.Equal(t, ArityPair, byName["between"].Arity)
+	assert.Equal(t, []dto.FieldType{dto.FieldString}, byName["like"].ValueTypes)
+	assert.Equal(t, []string{"pg"}, byName["similar"].Dialects)
+
+	data, err := json.Marshal(byName["in"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"in","sql":"IN","dialects":["pg","mysql","sqlite","mssql"],"arity":"list",
+		"description":"In a list of values","example":[20,30,40]}`, string(data))
+}
+
+func TestIsSupportedOn(t *testing.T) {
+	assert.True(t, IsSupportedOn("EQ", dialect.SQLite))
+	assert.True(t, IsSupportedOn("nearby", dialect.PG))
+	assert.False(t, IsSupportedOn("nearby", dialect.SQLite))
+	assert.False(t, IsSupportedOn("unknown", dialect.PG))
+}
+
+func TestExamples(t *testing.T) {
+	examples := Examples("age", dto.FieldNumber, "gt", "like", "in", "isnull")
+	assert.Equal(t, []dto.Filter{
+		{Field: "age", Operator: "gt", Value: 20},
+		{Field: "age", Operator: "in", Value: []interface{}{20, 30}},
+		{Field: "age", Operator: "isnull", Value: nil},
+	}, examples)
+
+	examples = Examples("name", dto.FieldString, "like", "eq")
+	assert.Equal(t, []dto.Filter{
+		{Field: "name", Operator: "eq", Value: "John"},
+		{Field: "name", Operator: "like", Value: "J%"},
+	}, examples)
+
+	// Fields with no type get every operator
+	assert.Len(t, Examples("location", ""), len(operatorMap))
+}
+
+func TestAliases(t *testing.T) {
+	require.NoError(t, RegisterAlias("ne", "neq"))
+	require.NoError(t, RegisterDeprecatedAlias("NOT_IN", "NotIn"))
+	assert.EqualError(t, RegisterAlias("contains", "unknown"), "cannot alias unknown operator 'unknown'")
+	assert.EqualError(t, RegisterAlias("eq", "neq"), "invalid alias name 'eq'")
+
+	assert.Equal(t, []Alias{{Name: "ne", Operator: "neq"}, {Name: "not_in", Operator: "notin", Deprecated: true}}, Aliases())
+	assert.Equal(t, "neq", Canonical("NE"))
+	assert.Equal(t, "gt", Canonical("GT"))
+	assert.Equal(t, "unknown", Canonical("unknown"))
+	assert.True(t, IsValidOperator("ne"))
+	assert.Equal(t, "NOT IN", GetOperator("not_in"))
+	assert.Equal(t, ArityList, GetArity("not_in"))
+
+	// Only deprecated aliases are logged
+	var logs bytes.Buffer
+	SetDeprecationLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer SetDeprecationLogger(nil)
+
+	assert.Equal(t, "neq", Resolve("ne"))
+	assert.Empty(t, logs.String())
+	assert.Equal(t, "notin", Resolve("not_in"))
+	assert.Contains(t, logs.String(), `msg="deprecated filter operator alias" alias=not_in operator=notin`)
+}
@@ -7,13 +7,10 @@ import (
 
 // ApplyPagination applies pagination to the query
 func ApplyPagination(query *bun.SelectQuery, p *dto.Pagination) *bun.SelectQuery {
-	if p.PageSize > 0 {
-		query = query.Limit(p.PageSize)
-
-		// Calculate offset
-		if p.Page > 0 {
-			offset := (p.Page - 1) * p.PageSize
-			query = query.Offset(offset)
+	if p.Limit() > 0 {
+		query = query.Limit(p.Limit())
+		if p.Offset() > 0 {
+			query = query.Offset(p.Offset())
 		}
 	}
 
@@ -0,0 +1,161 @@
+package bunql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"sync"
+)
+
+// Plugin is an extension registered with Use, such as an alternative filter syntax, faceting or caching
+// A plugin implements the hook interfaces it needs among ParsePlugin, ValidatePlugin, ApplyPlugin and ExecutePlugin
+type Plugin interface {
+	// Name identifies the plugin; registering a plugin replaces any plugin with the same name
+	Name() string
+}
+
+// ParsePlugin parses filter parameters in another syntax than the filter JSON, e.g. OData $filter expressions
+type ParsePlugin interface {
+	Plugin
+	// ParseFilter parses a filter parameter, reporting false if the parameter is not in the syntax of the plugin
+	// The group is then validated like any other filter
+	ParseFilter(param string) (dto.FilterGroup, bool, error)
+}
+
+// ValidatePlugin validates parsed queries, after the built-in validation
+type ValidatePlugin interface {
+	Plugin
+	// Validate returns an error to reject the query
+	Validate(ql *BunQL) error
+}
+
+// ApplyPlugin takes part in applying the BunQL to queries
+type ApplyPlugin interface {
+	Plugin
+	// Middleware returns the middleware run by Apply, around the middleware of the BunQL
+	Middleware() Middleware
+}
+
+// ExecutePlugin is notified around the executions of ExecutePage and ExecuteCursorPage
+type ExecutePlugin interface {
+	Plugin
+	// BeforeExecute is called before the query is executed; returning an error fails the execution
+	BeforeExecute(ctx context.Context, ql *BunQL) error
+	// AfterExecute is called with the number of rows returned, the total count (-1 if not counted) and the error
+	AfterExecute(ctx context.Context, ql *BunQL, rows, total int, err error)
+}
+
+// plugins holds the registered plugins, in registration order
+var plugins = struct {
+	sync.RWMutex
+	list []Plugin
+}{}
+
+// Use registers plugins used by every BunQL, replacing the registered plugins with the same names
+// It is typically called from an init function or at startup, before handling requests
+func Use(plugin ...Plugin) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	for _, p := range plugin {
+		replaced := false
+		for i, registered := range plugins.list {
+			if registered.Name() == p.Name() {
+				plugins.list[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			plugins.list = append(plugins.list, p)
+		}
+	}
+}
+
+// Plugins returns the registered plugins, in registration order
+func Plugins() []Plugin {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	return append([]Plugin{}, plugins.list...)
+}
+
+// pluginsOf returns the registered plugins implementing the hook interface H
+func pluginsOf[H Plugin]() []H {
+	var hooks []H
+	for _, p := range Plugins() {
+		if hook, ok := p.(H); ok {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// parseFilterParams gives the parse plugins the first chance to parse each filter parameter
+// Parameters parsed by a plugin are converted to the filter JSON, so that all the filters are combined and
+// validated the same way
+func parseFilterParams(params []string) ([]string, error) {
+	parsers := pluginsOf[ParsePlugin]()
+	if len(parsers) == 0 {
+		return params, nil
+	}
+
+	converted := make([]string, len(params))
+	for i, param := range params {
+		converted[i] = param
+		for _, parser := range parsers {
+			group, ok, err := parser.ParseFilter(param)
+			if err != nil {
+				return nil, fmt.Errorf("plugin '%s': %w", parser.Name(), err)
+			}
+			if !ok {
+				continue
+			}
+			data, err := json.Marshal(group)
+			if err != nil {
+				return nil, fmt.Errorf("plugin '%s': %w", parser.Name(), err)
+			}
+			converted[i] = string(data)
+			break
+		}
+	}
+	return converted, nil
+}
+
+// validatePlugins runs the validate plugins on the query
+func (q *BunQL) validatePlugins() error {
+	for _, validator := range pluginsOf[ValidatePlugin]() {
+		if err := validator.Validate(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pluginMiddleware returns the middleware of the apply plugins
+func pluginMiddleware() []Middleware {
+	var middleware []Middleware
+	for _, p := range pluginsOf[ApplyPlugin]() {
+		middleware = append(middleware, p.Middleware())
+	}
+	return middleware
+}
+
+// beforeExecute validates the configuration of the query and notifies the execute plugins that it is about to be
+// executed
+func (q *BunQL) beforeExecute(ctx context.Context) error {
+	if err := q.validateWindowColumns(); err != nil {
+		return err
+	}
+	for _, p := range pluginsOf[ExecutePlugin]() {
+		if err := p.BeforeExecute(ctx, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterExecute notifies the execute plugins of the outcome of an execution
+func (q *BunQL) afterExecute(ctx context.Context, rows, total int, err error) {
+	for _, p := range pluginsOf[ExecutePlugin]() {
+		p.AfterExecute(ctx, q, rows, total, err)
+	}
+}
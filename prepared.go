@@ -0,0 +1,348 @@
+package bunql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatementCache keeps the prepared statements of the query shapes executed through it, so that pages differing
+// only by their filter values, e.g. the same listing for different customers, are parsed and planned once
+// A StatementCache is safe for concurrent use and is meant to be shared by the BunQLs of a process. It keeps at most
+// its size statements per cache, closing the least recently used ones
+type StatementCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	stmts map[statementKey]*list.Element
+}
+
+// statementKey identifies a prepared statement by its database and its parameterized SQL
+type statementKey struct {
+	db    *sql.DB
+	query string
+}
+
+// preparedStatement is a statement of a StatementCache, closed once evicted and no longer in use
+type preparedStatement struct {
+	key     statementKey
+	stmt    *sql.Stmt
+	users   int
+	evicted bool
+}
+
+// NewStatementCache creates a StatementCache keeping at most size prepared statements
+func NewStatementCache(size int) *StatementCache {
+	if size <= 0 {
+		size = 1
+	}
+	return &StatementCache{size: size, order: list.New(), stmts: map[statementKey]*list.Element{}}
+}
+
+// WithPreparedStatements makes ExecutePage execute its main and count queries as prepared statements of the cache
+// The queries are normalized to a parameterized form, their literals being replaced by placeholders, and the
+// statements are keyed by that form. Query hooks still see the queries with their values. Queries running on a
+// transaction or a dedicated connection, and thus PG queries with a timeout, are executed as usual
+func (q *BunQL) WithPreparedStatements(cache *StatementCache) *BunQL {
+	q.Statements = cache
+	return q
+}
+
+// Len returns the number of prepared statements in the cache
+func (c *StatementCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.stmts)
+}
+
+// Close closes the prepared statements of the cache, once they are no longer in use
+func (c *StatementCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for key, elem := range c.stmts {
+		if err := c.evict(key, elem); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// acquire returns the prepared statement of a parameterized query on a database, preparing it if needed, and a
+// function releasing it once the statement has been executed
+func (c *StatementCache) acquire(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, func(), error) {
+	key := statementKey{db: db, query: query}
+
+	c.mu.Lock()
+	if elem, ok := c.stmts[key]; ok {
+		c.order.MoveToFront(elem)
+		return c.use(elem.Value.(*preparedStatement))
+	}
+	c.mu.Unlock()
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	c.mu.Lock()
+	// Another execution may have prepared the same statement in the meantime
+	if elem, ok := c.stmts[key]; ok {
+		_ = stmt.Close()
+		c.order.MoveToFront(elem)
+		return c.use(elem.Value.(*preparedStatement))
+	}
+	elem := c.order.PushFront(&preparedStatement{key: key, stmt: stmt})
+	c.stmts[key] = elem
+	for len(c.stmts) > c.size {
+		oldest := c.order.Back()
+		_ = c.evict(oldest.Value.(*preparedStatement).key, oldest)
+	}
+	return c.use(elem.Value.(*preparedStatement))
+}
+
+// use marks a statement as in use and unlocks the cache, which must be locked
+func (c *StatementCache) use(prepared *preparedStatement) (*sql.Stmt, func(), error) {
+	prepared.users++
+	c.mu.Unlock()
+
+	release := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		prepared.users--
+		if prepared.evicted && prepared.users == 0 {
+			_ = prepared.stmt.Close()
+		}
+	}
+	return prepared.stmt, release, nil
+}
+
+// evict removes a statement from the cache, which must be locked, closing it unless it is in use
+func (c *StatementCache) evict(key statementKey, elem *list.Element) error {
+	prepared := elem.Value.(*preparedStatement)
+	c.order.Remove(elem)
+	delete(c.stmts, key)
+	prepared.evicted = true
+	if prepared.users > 0 {
+		return nil
+	}
+	return prepared.stmt.Close()
+}
+
+// preparedConn executes the queries of a database as prepared statements of a statement cache, keyed by their
+// parameterized form. Queries that fail to be prepared are executed as they are, so that their errors are reported
+// as usual
+type preparedConn struct {
+	*sql.DB
+	statements *StatementCache
+	dialect    dialect.Name
+}
+
+// onPrepared routes a query running on a database pool to the prepared statements of the statement cache, if any
+func (q *BunQL) onPrepared(query *bun.SelectQuery) *bun.SelectQuery {
+	db, ok := baseConn(query.GetConn()).(*sql.DB)
+	if q.Statements == nil || !ok {
+		return query
+	}
+	return query.Conn(preparedConn{DB: db, statements: q.Statements, dialect: query.Dialect().Name()})
+}
+
+// QueryContext executes a query as a prepared statement
+func (c preparedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	shape, values := parameterize(c.dialect, query)
+	stmt, release, err := c.statements.acquire(ctx, c.DB, shape)
+	if err != nil {
+		return c.DB.QueryContext(ctx, query, args...)
+	}
+	// The rows keep the statement open until they are closed, even if it is evicted in the meantime
+	defer release()
+	return stmt.QueryContext(ctx, append(values, args...)...)
+}
+
+// QueryRowContext executes a query returning at most one row as a prepared statement
+func (c preparedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	shape, values := parameterize(c.dialect, query)
+	stmt, release, err := c.statements.acquire(ctx, c.DB, shape)
+	if err != nil {
+		return c.DB.QueryRowContext(ctx, query, args...)
+	}
+	defer release()
+	return stmt.QueryRowContext(ctx, append(values, args...)...)
+}
+
+// Parameterized returns the parameterized form of a query, the one its prepared statements are keyed by, and the
+// values of its literals in the order they are bound as arguments
+func Parameterized(query *bun.SelectQuery) (string, []interface{}, error) {
+	rendered, err := query.AppendQuery(query.DB().Formatter(), nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render query: %w", err)
+	}
+	shape, args := parameterize(query.Dialect().Name(), string(rendered))
+	return shape, args, nil
+}
+
+// parameterize replaces the string and number literals of SQL with the placeholders of the dialect, returning the
+// parameterized SQL and the values of the literals in order. Identifiers, comments, typed literals such as X'00',
+// column positions and the literals of ESCAPE, INTERVAL and MSSQL OPTION clauses, which can't be parameters, are kept
+// as is
+func parameterize(name dialect.Name, query string) (string, []interface{}) {
+	var b strings.Builder
+	var args []interface{}
+	placeholder := func(value interface{}) {
+		args = append(args, value)
+		switch name {
+		case dialect.PG:
+			b.WriteString("$" + strconv.Itoa(len(args)))
+		case dialect.MSSQL:
+			b.WriteString("@p" + strconv.Itoa(len(args)))
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	// keyword is the last keyword or identifier, which tells whether the next literal may be a parameter
+	var keyword string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			end, value := stringLiteral(name, query, i)
+			if keyword == "ESCAPE" || keyword == "INTERVAL" {
+				b.WriteString(query[i:end])
+			} else {
+				placeholder(value)
+			}
+			i = end
+			keyword = ""
+		case c == '"' || c == '`' || (c == '[' && name == dialect.MSSQL):
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(query[i+1:], closing)
+			if end < 0 {
+				end = len(query) - i - 2
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 2
+			keyword = ""
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 4
+		case isIdentStart(c):
+			start := i
+			for i < len(query) && isIdentPart(query[i]) {
+				i++
+			}
+			ident := query[start:i]
+			if i < len(query) && query[i] == '\'' {
+				end, value := stringLiteral(name, query, i)
+				if name == dialect.MSSQL && strings.EqualFold(ident, "N") {
+					// Unicode strings of MSSQL are passed as nvarchar parameters
+					placeholder(value)
+				} else {
+					b.WriteString(query[start:end])
+				}
+				i = end
+				keyword = ""
+				continue
+			}
+			b.WriteString(ident)
+			keyword = strings.ToUpper(ident)
+			if name == dialect.MSSQL && keyword == "OPTION" && strings.HasPrefix(strings.TrimLeft(query[i:], " "), "(") {
+				// The query hints of MSSQL take constants only
+				b.WriteString(query[i:])
+				i = len(query)
+			}
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(query) && (query[i] >= '0' && query[i] <= '9' || query[i] == '.') {
+				i++
+			}
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				i++
+				if i < len(query) && (query[i] == '+' || query[i] == '-') {
+					i++
+				}
+				for i < len(query) && query[i] >= '0' && query[i] <= '9' {
+					i++
+				}
+			}
+			literal := query[start:i]
+			if keyword == "INTERVAL" || keyword == "BY" {
+				// Numbers of ORDER BY and GROUP BY are column positions
+				b.WriteString(literal)
+			} else if n, err := strconv.ParseInt(literal, 10, 64); err == nil {
+				placeholder(n)
+			} else {
+				// Decimals are passed as text so that they keep their precision
+				placeholder(literal)
+			}
+			keyword = ""
+		case c == '$' || c == '@' || c == ':':
+			// Existing placeholders, variables and casts are kept with the identifier or number they prefix
+			start := i
+			i++
+			for i < len(query) && isIdentPart(query[i]) {
+				i++
+			}
+			b.WriteString(query[start:i])
+		default:
+			b.WriteByte(c)
+			i++
+			if c != ' ' && c != '\n' && c != '\t' {
+				keyword = ""
+			}
+		}
+	}
+	return b.String(), args
+}
+
+// stringLiteral returns the end and the value of the string literal starting at the quote at start
+func stringLiteral(name dialect.Name, query string, start int) (int, string) {
+	var value strings.Builder
+	for i := start + 1; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\\' && name == dialect.MySQL && i+1 < len(query):
+			i++
+			value.WriteByte(query[i])
+		case c == '\'' && i+1 < len(query) && query[i+1] == '\'':
+			i++
+			value.WriteByte('\'')
+		case c == '\'':
+			return i + 1, value.String()
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return len(query), value.String()
+}
+
+// isIdentStart reports whether a byte starts an identifier or a keyword
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// isIdentPart reports whether a byte continues an identifier or a keyword
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9' || c == '$'
+}
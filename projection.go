@@ -0,0 +1,155 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/search"
+	"github.com/uptrace/bun"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrSortNotSelected is returned when the query is sorted on a column it doesn't select
+var ErrSortNotSelected = errors.New("sort field is not among the selected columns")
+
+// keysetKey is the context key marking the application of a BunQL to a page of cursor pagination
+type keysetKey struct{}
+
+// plainSortField matches the sort fields that are a column, optionally qualified with a table name or alias
+var plainSortField = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithAddSortColumns sets whether the columns of the sort fields missing from the columns selected by the query, e.g.
+// by a sparse fieldset, are added to them. Otherwise sorting on them fails with ErrSortNotSelected where it can't work:
+// on SELECT DISTINCT queries, which Postgres and MSSQL reject with confusing errors, and with cursor pagination,
+// whose cursors are read from the selected columns
+func (q *BunQL) WithAddSortColumns(add bool) *BunQL {
+	q.AddSortColumns = add
+	return q
+}
+
+// checkSortSelected checks that the query selects the columns of the sort fields, adding the missing ones if
+// AddSortColumns is set or failing DISTINCT and cursor-paginated queries with ErrSortNotSelected otherwise
+// Queries selecting every column, and sorts on expressions, are not checked
+func (q *BunQL) checkSortSelected(ctx context.Context, query *bun.SelectQuery, sortFields []dto.SortField) *bun.SelectQuery {
+	if !slices.ContainsFunc(sortFields, isPlainSortField) {
+		return query
+	}
+	keyset := q.Cursor != nil || ctx.Value(keysetKey{}) != nil
+	if !q.AddSortColumns && !keyset && !isDistinct(query) {
+		return query
+	}
+	selected, ok := selectedColumns(querySQL(query))
+	if !ok {
+		return query
+	}
+
+	for _, sort := range sortFields {
+		if !isPlainSortField(sort) {
+			continue
+		}
+		column := sort.Field[strings.LastIndex(sort.Field, ".")+1:]
+		if selected[column] {
+			continue
+		}
+		if !q.AddSortColumns {
+			return query.Err(fmt.Errorf("cannot sort on '%s': %w", sort.Field, ErrSortNotSelected))
+		}
+		query = query.ColumnExpr("?", bun.Ident(sort.Field))
+		selected[column] = true
+	}
+	return query
+}
+
+// isPlainSortField reports whether a sort field is a column, as opposed to the search relevance or an expression
+func isPlainSortField(sort dto.SortField) bool {
+	return sort.Field != search.RelevanceField && plainSortField.MatchString(sort.Field)
+}
+
+// isDistinct reports whether a query is a SELECT DISTINCT from the DISTINCT clause bun keeps on it, so the query
+// doesn't have to be rendered, falling back to rendering it if bun doesn't keep the clause where expected
+func isDistinct(query *bun.SelectQuery) bool {
+	if distinct := reflect.ValueOf(query).Elem().FieldByName("distinctOn"); distinct.Kind() == reflect.Slice {
+		return !distinct.IsNil()
+	}
+	return strings.HasPrefix(querySQL(query), "SELECT DISTINCT ")
+}
+
+// selectedColumns returns the names of the columns selected by a SELECT statement, their aliases for expressions,
+// and false if the statement selects every column or its select list can't be read
+func selectedColumns(sql string) (map[string]bool, bool) {
+	list, ok := strings.CutPrefix(sql, "SELECT ")
+	if !ok {
+		return nil, false
+	}
+	if rest, ok := strings.CutPrefix(list, "DISTINCT ON "); ok {
+		end := closingParen(rest)
+		if end < 0 {
+			return nil, false
+		}
+		list = strings.TrimSpace(rest[end+1:])
+	} else {
+		list = strings.TrimPrefix(list, "DISTINCT ")
+	}
+
+	selected := map[string]bool{}
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && c == ',':
+			if !addSelected(selected, list[start:i]) {
+				return nil, false
+			}
+			start = i + 1
+		case depth == 0 && strings.HasPrefix(list[i:], " FROM "):
+			return selected, addSelected(selected, list[start:i])
+		}
+	}
+	return selected, addSelected(selected, list[start:])
+}
+
+// addSelected adds the name of an item of a select list, returning false if the item selects every column
+func addSelected(selected map[string]bool, item string) bool {
+	item = strings.TrimSpace(item)
+	if strings.HasSuffix(item, "*") {
+		return false
+	}
+	if i := strings.LastIndex(strings.ToUpper(item), " AS "); i >= 0 {
+		item = item[i+4:]
+	}
+	item = item[strings.LastIndex(item, ".")+1:]
+	selected[strings.Trim(item, "\"`[] ")] = true
+	return true
+}
+
+// closingParen returns the index of the parenthesis closing the one s starts with, or -1
+func closingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,101 @@
+package bunql
+
+import (
+	"errors"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
+	"github.com/uptrace/bun"
+	"reflect"
+)
+
+// Redacted replaces the values of sensitive fields in logs and audit entries
+const Redacted = "[REDACTED]"
+
+// RedactedFilters returns a copy of the filters where the values of the sensitive fields are replaced by Redacted,
+// suitable for logging
+func (q *BunQL) RedactedFilters() dto.FilterGroup {
+	return redactFilterGroup(q.Filters, q.Fields)
+}
+
+// redactFilterGroup returns a copy of a filter group where the values of the sensitive fields are redacted
+func redactFilterGroup(group dto.FilterGroup, fields map[string]dto.FieldConfig) dto.FilterGroup {
+	redacted := dto.FilterGroup{
+		Logic:   group.Logic,
+		Filters: make([]dto.Filter, len(group.Filters)),
+		Groups:  make([]dto.FilterGroup, len(group.Groups)),
+		Scope:   group.Scope,
+	}
+
+	for i, filter := range group.Filters {
+		redacted.Filters[i] = filter
+		if fields[filter.Field].Sensitive && filter.Value != nil {
+			redacted.Filters[i].Value = Redacted
+		}
+	}
+	for i, nestedGroup := range group.Groups {
+		redacted.Groups[i] = redactFilterGroup(nestedGroup, fields)
+	}
+	return redacted
+}
+
+// redactedError is the error of a query filtering sensitive fields, passed to the audit hook without its message, as
+// database errors may quote the values of the filters. errors.Is still matches the errors it wraps
+type redactedError struct {
+	err error
+}
+
+// Error implements error
+func (e redactedError) Error() string {
+	return "query failed: error message " + Redacted
+}
+
+// Is reports whether the redacted error matches target
+func (e redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// redactError returns the error of a query, redacted if the filters hold values of sensitive fields
+func (q *BunQL) redactError(err error) error {
+	if err == nil || !hasSensitiveValues(q.Filters, q.Fields) {
+		return err
+	}
+	return redactedError{err: err}
+}
+
+// hasSensitiveValues reports whether a filter group holds values of sensitive fields
+func hasSensitiveValues(group dto.FilterGroup, fields map[string]dto.FieldConfig) bool {
+	for _, filter := range group.Filters {
+		if fields[filter.Field].Sensitive && filter.Value != nil {
+			return true
+		}
+	}
+	for _, nestedGroup := range group.Groups {
+		if hasSensitiveValues(nestedGroup, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedSQL returns the SQL of a query where the values bound to the sensitive fields are replaced by Redacted
+// The filters mark these values as they bind them, so they are redacted however the dialect renders them
+func redactedSQL(query *bun.SelectQuery) string {
+	sql, err := query.AppendQuery(filter.RedactingFormatter(query.DB().Formatter(), Redacted), nil)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return string(sql)
+}
+
+// flattenValue returns the elements of a list value, or the value itself
+func flattenValue(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Interface()
+		}
+		return values
+	}
+	return []interface{}{value}
+}
@@ -0,0 +1,169 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// ErrNoPolicy is returned when no query policy is registered for a model
+var ErrNoPolicy = errors.New("no query policy registered")
+
+// Policy is the query policy of a model: what clients may filter and sort on, and how
+type Policy struct {
+	FilterFields       []string                                      // Fields that can be filtered on; any field if empty
+	SortFields         []string                                      // Fields that can be sorted on; any field if empty
+	Fields             map[string]dto.FieldConfig                    // Per-field configuration, such as the field type and the allowed operators
+	DefaultSort        []dto.SortField                               // Sort used when the request has none
+	MaxPageSize        int                                           // Largest page size a request can ask for; unlimited if zero
+	MaxRows            int                                           // Largest number of rows a query returns, paginated or not; unlimited if zero
+	ClampPagination    bool                                          // Clamp out of range pages and page sizes instead of rejecting them
+	FilterParamLogic   string                                        // Logic ("and" or "or") combining repeated filter parameters; "and" if empty
+	IndexedFields      []string                                      // Fields backed by an index; filtering or sorting on another field raises a warning
+	DeniedFieldHook    func(ctx context.Context, denied DeniedField) // Notified of every field rejected by the allowed or denied fields
+	DateLocale         filter.DateLocale                             // How date strings in filter values are read; month first (01/31/2024) if zero
+	ContextFilters     []ContextFilter                               // Filters whose values are read from the request context, e.g. the organization ID
+	FilterScopes       map[string][]string                           // Fields allowed in the filter groups declaring each scope, see BunQL.WithFilterScope
+	DeniedFilterFields []string                                      // Fields that can't be filtered on, even if allowed by FilterFields
+	DeniedSortFields   []string                                      // Fields that can't be sorted on, even if allowed by SortFields
+}
+
+// registry holds the query policies of the registered models
+var registry = struct {
+	sync.RWMutex
+	policies map[reflect.Type]Policy
+}{policies: map[reflect.Type]Policy{}}
+
+// Register registers the query policy of the model T, replacing any previous one
+// It is typically called from an init function next to the model definition
+func Register[T any](policy Policy) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.policies[modelType[T]()] = policy
+}
+
+// PolicyFor returns the query policy registered for the model T
+func PolicyFor[T any]() (Policy, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	policy, ok := registry.policies[modelType[T]()]
+	return policy, ok
+}
+
+// NewFor creates a new BunQL instance configured with the query policy registered for the model T
+func NewFor[T any]() (*BunQL, error) {
+	policy, ok := PolicyFor[T]()
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoPolicy, modelType[T]())
+	}
+	return NewWithPolicy(policy), nil
+}
+
+// NewWithPolicy creates a new BunQL instance configured with a query policy
+func NewWithPolicy(policy Policy) *BunQL {
+	ql := NewWithAllowedFields(append([]string{}, policy.FilterFields...), append([]string{}, policy.SortFields...))
+	ql.Sort = append(ql.Sort, policy.DefaultSort...)
+	ql.MaxPageSize = policy.MaxPageSize
+	ql.MaxRows = policy.MaxRows
+	ql.ClampPagination = policy.ClampPagination
+	ql.FilterParamLogic = policy.FilterParamLogic
+	ql.DeniedFieldHook = policy.DeniedFieldHook
+	ql.WithDeniedFields(append([]string{}, policy.DeniedFilterFields...), append([]string{}, policy.DeniedSortFields...))
+	ql.FilterOptions.DateLocale = policy.DateLocale
+	ql.IndexedFields = append(ql.IndexedFields, policy.IndexedFields...)
+	ql.ContextFilters = append(ql.ContextFilters, policy.ContextFilters...)
+	for field, cfg := range policy.Fields {
+		ql.WithFieldConfig(field, cfg)
+	}
+	for scope, fields := range policy.FilterScopes {
+		ql.WithFilterScope(scope, fields...)
+	}
+	return ql
+}
+
+// ParseFromRequest parses the filter, sort, page and pageSize query parameters of a request
+// The filter parameter can be repeated, the filters being combined with AND
+func ParseFromRequest(r *http.Request) (*BunQL, error) {
+	ql := New()
+	if err := ql.parseRequest(r); err != nil {
+		return nil, err
+	}
+	return ql, nil
+}
+
+// ParseFromRequestWithPolicy parses the filter, sort, page and pageSize query parameters of a request,
+// and validates them against a query policy
+func ParseFromRequestWithPolicy(r *http.Request, policy Policy) (*BunQL, error) {
+	ql := NewWithPolicy(policy)
+	if err := ql.parseRequest(r); err != nil {
+		return nil, err
+	}
+	return ql, nil
+}
+
+// ParseFromRequestFor parses the filter, sort, page and pageSize query parameters of a request,
+// and validates them against the query policy registered for the model T
+func ParseFromRequestFor[T any](r *http.Request) (*BunQL, error) {
+	ql, err := NewFor[T]()
+	if err != nil {
+		return nil, err
+	}
+	if err := ql.parseRequest(r); err != nil {
+		return nil, err
+	}
+	return ql, nil
+}
+
+// ParseRequest parses the filter, sort, page and pageSize query parameters of a request into a Clone of the BunQL,
+// validated against its allowed fields, so that a configured BunQL can serve as the template of concurrent requests
+func (q *BunQL) ParseRequest(r *http.Request) (*BunQL, error) {
+	ql := q.Clone()
+	if err := ql.parseRequest(r); err != nil {
+		return nil, err
+	}
+	return ql, nil
+}
+
+// parseRequest parses the query parameters of a request into the BunQL
+func (q *BunQL) parseRequest(r *http.Request) error {
+	params := r.URL.Query()
+
+	page, err := q.pageParam(params.Get("page"), "page", ErrInvalidPage)
+	if err != nil {
+		return err
+	}
+	pageSize, err := q.pageParam(params.Get("pageSize"), "pageSize", ErrInvalidPageSize)
+	if err != nil {
+		return err
+	}
+
+	return q.parseParams(r.Context(), params["filter"], params.Get("sort"), page, pageSize)
+}
+
+// pageParam parses an optional page or page size query parameter, which must be a positive integer when given
+// Values below 1 are left to be clamped when ClampPagination is set
+func (q *BunQL) pageParam(value, name string, invalid error) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || (n < 1 && !q.ClampPagination) {
+		return 0, fmt.Errorf("invalid %s parameter '%s': %w", name, value, invalid)
+	}
+	return n, nil
+}
+
+// modelType returns the type of the model T, dereferencing pointers
+func modelType[T any]() reflect.Type {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,22 @@
+package bunql
+
+import (
+	"github.com/uptrace/bun"
+)
+
+// WithReplica routes the read-only queries executed through the BunQL to a read replica: the main and count
+// queries of ExecutePage, ExecuteCursorPage, ExecuteUnion and Iterate, percentiles and time buckets. The queries
+// are still built from the primary, which is left untouched. Queries running on a transaction or a dedicated
+// connection stay there, as they may need to see writes the replica doesn't have yet
+func (q *BunQL) WithReplica(replica *bun.DB) *BunQL {
+	q.Replica = replica
+	return q
+}
+
+// onReplica routes a query to the read replica, if any
+func (q *BunQL) onReplica(query *bun.SelectQuery) *bun.SelectQuery {
+	if q.Replica == nil || isSingleConn(query) {
+		return query
+	}
+	return query.Conn(q.Replica)
+}
@@ -0,0 +1,129 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"sort"
+	"sync"
+)
+
+// ErrSavedSearchNotFound is returned by saved search stores when a user has no saved search with the given ID
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch is the serializable state of a query saved by a user, e.g. for a "save this view" feature
+// It holds what the user chose (filters, sort, search and page size) but not the configuration of the BunQL
+type SavedSearch struct {
+	ID       string          `json:"id"`                 // Identifier of the saved search, unique per user
+	Name     string          `json:"name"`               // Name given by the user
+	Filters  dto.FilterGroup `json:"filters"`            // Filters of the query
+	Sort     []dto.SortField `json:"sort"`               // Sort of the query
+	Search   *dto.Search     `json:"search,omitempty"`   // Free-text search, if any
+	PageSize int             `json:"pageSize,omitempty"` // Page size, 0 if not paginated
+}
+
+// SavedSearchStore persists the saved searches of users
+type SavedSearchStore interface {
+	// Save creates or replaces a saved search of a user
+	Save(ctx context.Context, userID string, search SavedSearch) error
+	// Load returns a saved search of a user, or an error wrapping ErrSavedSearchNotFound
+	Load(ctx context.Context, userID, id string) (SavedSearch, error)
+	// List returns the saved searches of a user
+	List(ctx context.Context, userID string) ([]SavedSearch, error)
+}
+
+// SavedSearch returns the state of the query as a saved search with the given ID and name
+func (q *BunQL) SavedSearch(id, name string) SavedSearch {
+	saved := SavedSearch{
+		ID:      id,
+		Name:    name,
+		Filters: q.Filters,
+		Sort:    append([]dto.SortField{}, q.Sort...),
+	}
+	if q.Search != nil {
+		search := *q.Search
+		saved.Search = &search
+	}
+	if q.Pagination != nil {
+		saved.PageSize = q.Pagination.PageSize
+	}
+	return saved
+}
+
+// RestoreSavedSearch sets the filters, sort, search and page size of the query from a saved search, starting
+// from the first page. The filters and sort are validated against the allowed fields and the field configuration,
+// which may have changed since the search was saved
+func (q *BunQL) RestoreSavedSearch(saved SavedSearch) error {
+	if err := q.validateFilters(saved.Filters); err != nil {
+		return err
+	}
+	if err := q.validateSort(saved.Sort); err != nil {
+		return err
+	}
+
+	q.WithFilters(saved.Filters)
+	if len(saved.Sort) > 0 {
+		q.WithSort(saved.Sort)
+	}
+	q.Search = saved.Search
+	if saved.PageSize > 0 {
+		paging := dto.Pagination{Page: 1, PageSize: saved.PageSize}.Normalize(dto.Pagination{}, q.MaxPageSize)
+		q.WithPagination(&paging)
+	}
+	return nil
+}
+
+// MemorySavedSearchStore is a SavedSearchStore keeping the saved searches in memory, for tests and prototypes
+type MemorySavedSearchStore struct {
+	mu       sync.RWMutex
+	searches map[string]map[string]SavedSearch
+}
+
+// NewMemorySavedSearchStore creates an empty in-memory saved search store
+func NewMemorySavedSearchStore() *MemorySavedSearchStore {
+	return &MemorySavedSearchStore{searches: map[string]map[string]SavedSearch{}}
+}
+
+// Save creates or replaces a saved search of a user
+func (s *MemorySavedSearchStore) Save(_ context.Context, userID string, search SavedSearch) error {
+	if search.ID == "" {
+		return errors.New("saved search has no id")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.searches[userID] == nil {
+		s.searches[userID] = map[string]SavedSearch{}
+	}
+	s.searches[userID][search.ID] = search
+	return nil
+}
+
+// Load returns a saved search of a user
+func (s *MemorySavedSearchStore) Load(_ context.Context, userID, id string) (SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	search, ok := s.searches[userID][id]
+	if !ok {
+		return SavedSearch{}, fmt.Errorf("%w: '%s'", ErrSavedSearchNotFound, id)
+	}
+	return search, nil
+}
+
+// List returns the saved searches of a user, ordered by name
+func (s *MemorySavedSearchStore) List(_ context.Context, userID string) ([]SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	searches := make([]SavedSearch, 0, len(s.searches[userID]))
+	for _, search := range s.searches[userID] {
+		searches = append(searches, search)
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		if searches[i].Name != searches[j].Name {
+			return searches[i].Name < searches[j].Name
+		}
+		return searches[i].ID < searches[j].ID
+	})
+	return searches, nil
+}
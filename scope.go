@@ -0,0 +1,72 @@
+package bunql
+
+import (
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"maps"
+	"slices"
+)
+
+// WithFilterScope restricts the filter groups declaring the scope, and the groups nested in them, to the fields,
+// e.g. the OR group built from a public search box to name and email while the endpoint allows more fields
+// Scopes only narrow the allowed fields: the fields of a scope, which may be patterns like the allowed fields, must
+// also be allowed by the endpoint, a scope nested in another one is restricted to the fields of both, and unscoped
+// groups are still checked against the allowed fields. Groups declaring a scope that isn't configured are rejected
+func (q *BunQL) WithFilterScope(scope string, fields ...string) *BunQL {
+	scopes := maps.Clone(q.FilterScopes)
+	if scopes == nil {
+		scopes = map[string][]string{}
+	}
+	scopes[scope] = fields
+	q.FilterScopes = scopes
+	return q
+}
+
+// scopedField is a filter field denied by the scope of its group
+type scopedField struct {
+	filter dto.Filter
+	scope  string
+}
+
+// validateFilterScopes validates that the filters of the scoped groups only use the fields of their scopes
+func (q *BunQL) validateFilterScopes(group dto.FilterGroup) error {
+	denied, err := q.scopeDenials(group, "", nil)
+	if err != nil {
+		return err
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf("filter field '%s' is not allowed in scope '%s'", denied[0].filter.Field, denied[0].scope)
+	}
+	return nil
+}
+
+// scopeDenials returns the filters of a group and its nested groups on fields their scopes don't allow, the group
+// being in the scopes with the given lists of fields, besides its own if it declares one. Each list may hold patterns
+func (q *BunQL) scopeDenials(group dto.FilterGroup, scope string, fields [][]string) ([]scopedField, error) {
+	if group.Scope != "" {
+		scopeFields, ok := q.FilterScopes[group.Scope]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter group scope '%s'", group.Scope)
+		}
+		fields = append(slices.Clip(fields), scopeFields)
+		scope = group.Scope
+	}
+
+	var denied []scopedField
+	for _, filter := range group.Filters {
+		for _, scopeFields := range fields {
+			if !allowsField(scopeFields, filter.Field) {
+				denied = append(denied, scopedField{filter: filter, scope: scope})
+				break
+			}
+		}
+	}
+	for _, nestedGroup := range group.Groups {
+		nestedDenied, err := q.scopeDenials(nestedGroup, scope, fields)
+		if err != nil {
+			return nil, err
+		}
+		denied = append(denied, nestedDenied...)
+	}
+	return denied, nil
+}
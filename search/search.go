@@ -0,0 +1,70 @@
+package search
+
+import (
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/expr"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strings"
+)
+
+// RelevanceField is the virtual sort field ordering the results by how well they match the search term
+const RelevanceField = "relevance"
+
+// ApplySearch restricts the query to the rows where any of the search fields contains the term, ignoring case
+func ApplySearch(query *bun.SelectQuery, s *dto.Search) *bun.SelectQuery {
+	if s == nil || s.Term == "" || len(s.Fields) == 0 {
+		return query
+	}
+
+	pattern := "%" + expr.EscapeLike(s.Term) + "%"
+	return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		for _, field := range s.Fields {
+			q = q.WhereOr("LOWER(?) LIKE LOWER(?) ESCAPE '!'", bun.Ident(field), pattern)
+		}
+		return q
+	})
+}
+
+// ApplyRelevanceSort orders the query by the relevance of each row for the search term
+// On Postgres the relevance is the ts_rank of the search fields against the term; on other dialects each field
+// scores 3 for an exact match, 2 for a prefix match and 1 for any other match, and the scores are summed
+func ApplyRelevanceSort(query *bun.SelectQuery, s *dto.Search, direction string) *bun.SelectQuery {
+	if s == nil || s.Term == "" || len(s.Fields) == 0 {
+		return query
+	}
+
+	direction = strings.ToUpper(direction)
+	if direction != "ASC" && direction != "DESC" {
+		direction = "DESC"
+	}
+
+	if query.Dialect().Name() == dialect.PG {
+		args := make([]interface{}, 0, len(s.Fields)+1)
+		for _, field := range s.Fields {
+			args = append(args, bun.Ident(field))
+		}
+		args = append(args, s.Term)
+
+		placeholders := strings.Repeat(", ?", len(s.Fields))[2:]
+		expr := fmt.Sprintf("ts_rank(to_tsvector('simple', concat_ws(' ', %s)), plainto_tsquery('simple', ?)) %s", placeholders, direction)
+		return query.OrderExpr(expr, args...)
+	}
+
+	escaped := expr.EscapeLike(s.Term)
+	scores := make([]string, 0, len(s.Fields))
+	args := make([]interface{}, 0, 4*len(s.Fields))
+	for _, field := range s.Fields {
+		scores = append(scores, "CASE WHEN LOWER(?) = LOWER(?) THEN 3 "+
+			"WHEN LOWER(?) LIKE LOWER(?) ESCAPE '!' THEN 2 "+
+			"WHEN LOWER(?) LIKE LOWER(?) ESCAPE '!' THEN 1 ELSE 0 END")
+		args = append(args,
+			bun.Ident(field), s.Term,
+			bun.Ident(field), escaped+"%",
+			bun.Ident(field), "%"+escaped+"%",
+		)
+	}
+
+	return query.OrderExpr(fmt.Sprintf("(%s) %s", strings.Join(scores, " + "), direction), args...)
+}
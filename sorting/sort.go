@@ -4,35 +4,181 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/expr"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+	"reflect"
 	"strings"
+	"unicode"
 )
 
+// Options configures how sort fields are translated to SQL
+type Options struct {
+	// Dialect forces the SQL dialect the sort is generated for; by default the dialect of the query is used
+	Dialect dialect.Name
+	// Fields holds the per-field configuration, such as collation and accent folding
+	Fields map[string]dto.FieldConfig
+	// Expressions holds the SQL expressions of the computed fields, e.g. "SUM(price)" for total, sorted on when the
+	// field is configured or its NULLs are replaced, where its alias can't be used
+	Expressions map[string]string
+}
+
+// dialectName returns the dialect the sort is generated for
+func (o Options) dialectName(query *bun.SelectQuery) dialect.Name {
+	if o.Dialect != dialect.Invalid {
+		return o.Dialect
+	}
+	return query.Dialect().Name()
+}
+
+// ParseSort parses the sort fields from a JSON array, or from the compact "age:desc,name:asc" syntax
+// where the direction of each field is optional
 func ParseSort(jsonStr string) ([]dto.SortField, error) {
 	var sortFields []dto.SortField
-	err := json.Unmarshal([]byte(jsonStr), &sortFields)
-	if err != nil {
-		return nil, err
+	if trimmed := strings.TrimSpace(jsonStr); strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal([]byte(jsonStr), &sortFields); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if sortFields, err = parseCompactSort(jsonStr); err != nil {
+			return nil, err
+		}
 	}
 
-	// Validate and normalize directions
+	// Normalize directions, defaulting to ascending
 	for i := range sortFields {
-		dir := strings.ToLower(sortFields[i].Direction)
-		if dir != "asc" && dir != "desc" {
-			sortFields[i].Direction = "asc" // Default to ascending
-		} else {
-			sortFields[i].Direction = dir
+		sortFields[i].Direction = sortFields[i].Direction.Normalized()
+		if err := ValidateNullsAs(sortFields[i]); err != nil {
+			return nil, err
 		}
 	}
 
 	return sortFields, nil
 }
 
+// parseCompactSort parses comma-separated field:direction pairs
+func parseCompactSort(str string) ([]dto.SortField, error) {
+	var sortFields []dto.SortField
+	for _, part := range strings.Split(str, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		field = strings.TrimSpace(field)
+		if !IsFieldName(field) {
+			return nil, fmt.Errorf("invalid sort field '%s'", field)
+		}
+		sortFields = append(sortFields, dto.SortField{Field: field, Direction: dto.Direction(dir)})
+	}
+	return sortFields, nil
+}
+
+// ValidateNullsAs checks that the value NULLs of a sort field are sorted as is a string, a number or a boolean, as
+// COALESCE can't compare a column with objects or lists
+func ValidateNullsAs(sort dto.SortField) error {
+	if sort.NullsAs == nil {
+		return nil
+	}
+	switch reflect.ValueOf(sort.NullsAs).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	}
+	return fmt.Errorf("nullsAs of sort field '%s' must be a string, a number or a boolean", sort.Field)
+}
+
+// IsFieldName reports whether s is a non-empty, possibly table-qualified, column name
+func IsFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandAliases replaces the sort aliases with the ordered columns they stand for, e.g. "name" with last_name ASC,
+// first_name ASC. A descending alias inverts the direction of all its columns, and the NULL replacement of the alias
+// applies to the columns without one
+func ExpandAliases(sortFields []dto.SortField, aliases map[string][]dto.SortField) []dto.SortField {
+	if len(aliases) == 0 {
+		return sortFields
+	}
+
+	expanded := make([]dto.SortField, 0, len(sortFields))
+	for _, sort := range sortFields {
+		columns, ok := aliases[sort.Field]
+		if !ok {
+			expanded = append(expanded, sort)
+			continue
+		}
+		for _, column := range columns {
+			// Columns are ascending unless configured otherwise
+			column.Direction = column.Direction.Normalized()
+			if sort.Direction.IsDesc() {
+				column.Direction = column.Direction.Inverted()
+			}
+			if column.NullsAs == nil {
+				column.NullsAs = sort.NullsAs
+			}
+			expanded = append(expanded, column)
+		}
+	}
+	return expanded
+}
+
+// Column returns the expression the rows are sorted on for a sort field: the column, or the expression of a computed
+// field, collated or unaccented as configured and with its NULLs replaced by nullsAs
+func (o Options) Column(query *bun.SelectQuery, sort dto.SortField) schema.QueryAppender {
+	cfg := o.Fields[sort.Field]
+	column := expr.Column(o.dialectName(query), sort.Field, cfg)
+	if expression, ok := o.Expressions[sort.Field]; ok {
+		column = expr.ColumnExpr(o.dialectName(query), bun.Safe("("+expression+")"), cfg)
+	}
+
+	// Replace NULLs so they land where the replacement value sorts, without dialect-specific NULLS FIRST/LAST
+	if sort.NullsAs != nil {
+		return schema.SafeQuery("COALESCE(?, ?)", []interface{}{column, sort.NullsAs})
+	}
+	return column
+}
+
+// Value returns the value compared with the Column of a sort field, folded like the column
+func (o Options) Value(query *bun.SelectQuery, sort dto.SortField, value interface{}) interface{} {
+	return expr.Value(o.dialectName(query), o.Fields[sort.Field], value)
+}
+
 // ApplySort applies sorting to the query
 func ApplySort(query *bun.SelectQuery, sortFields []dto.SortField) *bun.SelectQuery {
+	return ApplySortWithOptions(query, sortFields, Options{})
+}
+
+// ApplySortWithOptions applies sorting to the query using the given options
+func ApplySortWithOptions(query *bun.SelectQuery, sortFields []dto.SortField, opts Options) *bun.SelectQuery {
 	for _, sort := range sortFields {
-		orderExpr := fmt.Sprintf("%s %s", sort.Field, strings.ToUpper(sort.Direction))
+		direction := sort.Direction.SQL()
+
+		// Sort configured fields on their collated or unaccented expression
+		if !expr.IsPlain(opts.Fields[sort.Field]) || sort.NullsAs != nil {
+			query = query.OrderExpr(fmt.Sprintf("? %s", direction), opts.Column(query, sort))
+			continue
+		}
+
+		orderExpr := fmt.Sprintf("%s %s", sort.Field, direction)
 		query = query.OrderExpr(orderExpr)
 	}
 	return query
 }
+
+// ApplyPinned sorts the pinned rows before all the others
+// It must be applied before ApplySort so that the requested sort only orders rows within each partition
+func ApplyPinned(query *bun.SelectQuery, pinned *dto.PinnedRows) *bun.SelectQuery {
+	if pinned == nil || len(pinned.IDs) == 0 {
+		return query
+	}
+	return query.OrderExpr("CASE WHEN ? IN (?) THEN 0 ELSE 1 END", bun.Ident(pinned.Column), bun.In(pinned.IDs))
+}
@@ -0,0 +1,116 @@
+package sorting
+
+import (
+	"database/sql"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"testing"
+)
+
+// newTestDB returns a database used to compile queries
+func newTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { sqldb.Close() })
+	return bun.NewDB(sqldb, sqlitedialect.New())
+}
+
+func TestParseSort(t *testing.T) {
+	sortFields, err := ParseSort(`[{"field": "age", "dir": "DESC"}, {"field": "name", "dir": "sideways"}, {"field": "score", "dir": "asc", "nullsAs": 0}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []dto.SortField{
+		{Field: "age", Direction: "desc"},
+		{Field: "name", Direction: "asc"},
+		{Field: "score", Direction: "asc", NullsAs: float64(0)},
+	}, sortFields)
+
+	_, err = ParseSort(`{"field": "age"}`)
+	assert.Error(t, err)
+
+	// NULLs are sorted as scalars only
+	sortFields, err = ParseSort(`[{"field": "name", "nullsAs": "zzz"}, {"field": "active", "nullsAs": false}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []dto.SortField{{Field: "name", Direction: "asc", NullsAs: "zzz"}, {Field: "active", Direction: "asc", NullsAs: false}}, sortFields)
+	_, err = ParseSort(`[{"field": "name", "nullsAs": {"a": 1}}]`)
+	assert.EqualError(t, err, "nullsAs of sort field 'name' must be a string, a number or a boolean")
+	_, err = ParseSort(`[{"field": "name", "nullsAs": [1]}]`)
+	assert.Error(t, err)
+}
+
+func TestParseSortCompact(t *testing.T) {
+	sortFields, err := ParseSort("age:DESC, name:asc,id")
+	require.NoError(t, err)
+	assert.Equal(t, []dto.SortField{
+		{Field: "age", Direction: "desc"},
+		{Field: "name", Direction: "asc"},
+		{Field: "id", Direction: "asc"},
+	}, sortFields)
+
+	_, err = ParseSort("age:desc,,name")
+	assert.EqualError(t, err, "invalid sort field ''")
+
+	_, err = ParseSort("age; DROP TABLE users:desc")
+	assert.EqualError(t, err, "invalid sort field 'age; DROP TABLE users'")
+}
+
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string][]dto.SortField{
+		"name":   {{Field: "last_name"}, {Field: "first_name", Direction: "asc"}},
+		"newest": {{Field: "created_at", Direction: "desc"}, {Field: "id", Direction: "desc"}},
+	}
+
+	assert.Equal(t, []dto.SortField{
+		{Field: "last_name", Direction: "asc"},
+		{Field: "first_name", Direction: "asc"},
+		{Field: "age", Direction: "desc"},
+	}, ExpandAliases([]dto.SortField{{Field: "name", Direction: "asc"}, {Field: "age", Direction: "desc"}}, aliases))
+
+	// Descending aliases invert all their columns
+	assert.Equal(t, []dto.SortField{
+		{Field: "last_name", Direction: "desc", NullsAs: ""},
+		{Field: "first_name", Direction: "desc", NullsAs: ""},
+		{Field: "created_at", Direction: "asc"},
+		{Field: "id", Direction: "asc"},
+	}, ExpandAliases([]dto.SortField{{Field: "name", Direction: "desc", NullsAs: ""}, {Field: "newest", Direction: "desc"}}, aliases))
+
+	sortFields := []dto.SortField{{Field: "name", Direction: "asc"}}
+	assert.Equal(t, sortFields, ExpandAliases(sortFields, nil))
+}
+
+func TestApplySortWithOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		sortFields []dto.SortField
+		opts       Options
+		expected   string
+	}{
+		{
+			name:       "Plain fields",
+			sortFields: []dto.SortField{{Field: "age", Direction: "desc"}, {Field: "name", Direction: "asc"}},
+			expected:   `SELECT * FROM "users" ORDER BY age DESC, name ASC`,
+		},
+		{
+			name:       "Null replacement",
+			sortFields: []dto.SortField{{Field: "deleted_at", Direction: "asc", NullsAs: "1970-01-01"}},
+			expected:   `SELECT * FROM "users" ORDER BY COALESCE("deleted_at", '1970-01-01') ASC`,
+		},
+		{
+			name:       "Configured field",
+			sortFields: []dto.SortField{{Field: "name", Direction: "asc"}},
+			opts:       Options{Fields: map[string]dto.FieldConfig{"name": {Collation: "NOCASE"}}},
+			expected:   `SELECT * FROM "users" ORDER BY "name" COLLATE NOCASE ASC`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			query := ApplySortWithOptions(db.NewSelect().TableExpr(`"users"`), tt.sortFields, tt.opts)
+			assert.Equal(t, tt.expected, query.String())
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bunql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/mssqldialect"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// QuerySpec is the parsed state of a query: its filters, sort, pagination, search and selected fields, without the
+// configuration of the BunQL nor anything of bun, so that it can be serialized and passed between services, e.g.
+// over a message queue, and applied at the data layer
+type QuerySpec struct {
+	Filters    dto.FilterGroup `json:"filters"`              // Filters of the query
+	Sort       []dto.SortField `json:"sort,omitempty"`       // Sort of the query
+	Pagination *dto.Pagination `json:"pagination,omitempty"` // Page of the query, nil if not paginated
+	Search     *dto.Search     `json:"search,omitempty"`     // Free-text search, if any
+	Fields     []string        `json:"fields,omitempty"`     // Columns selected by the query; every column if empty
+}
+
+// WithSelectedFields selects the columns of the fields instead of every column of the model
+func (q *BunQL) WithSelectedFields(fields ...string) *BunQL {
+	q.SelectedFields = fields
+	return q
+}
+
+// Spec returns the parsed state of the query as a QuerySpec
+func (q *BunQL) Spec() QuerySpec {
+	spec := QuerySpec{Filters: q.Filters}
+	if len(q.Sort) > 0 {
+		spec.Sort = append([]dto.SortField{}, q.Sort...)
+	}
+	if len(q.SelectedFields) > 0 {
+		spec.Fields = append([]string{}, q.SelectedFields...)
+	}
+	if q.Pagination != nil {
+		pagination := *q.Pagination
+		spec.Pagination = &pagination
+	}
+	if q.Search != nil {
+		search := *q.Search
+		spec.Search = &search
+	}
+	return spec
+}
+
+// WithSpec sets the filters, sort, pagination, search and selected fields of the query from a spec, validating the
+// filters, sort and selected fields against the allowed fields and the field configuration, e.g. for specs received
+// from clients
+func (q *BunQL) WithSpec(spec QuerySpec) error {
+	if err := q.validateFilters(spec.Filters); err != nil {
+		return err
+	}
+	if err := q.validateSort(spec.Sort); err != nil {
+		return err
+	}
+	for _, field := range spec.Fields {
+		if !plainSortField.MatchString(field) {
+			return fmt.Errorf("selected field '%s' is not a column", field)
+		}
+		if !q.allowsSelectedField(field) {
+			return fmt.Errorf("selected field '%s' is not allowed", field)
+		}
+	}
+
+	q.setSpec(spec)
+	return nil
+}
+
+// setSpec sets the state of the query from a spec
+func (q *BunQL) setSpec(spec QuerySpec) {
+	q.WithFilters(spec.Filters)
+	q.WithSort(append([]dto.SortField{}, spec.Sort...))
+	q.WithSelectedFields(append([]string{}, spec.Fields...)...)
+	q.Pagination, q.Search = nil, nil
+	if spec.Pagination != nil {
+		pagination := *spec.Pagination
+		q.WithPagination(&pagination)
+	}
+	if spec.Search != nil {
+		search := *spec.Search
+		q.Search = &search
+	}
+}
+
+// Apply applies the spec to the query, as a BunQL without configuration would
+// The spec is trusted: validate the specs of untrusted origin with WithSpec on a configured BunQL instead
+func (s QuerySpec) Apply(ctx context.Context, query *bun.SelectQuery) *bun.SelectQuery {
+	ql := New()
+	ql.setSpec(s)
+	return ql.Apply(ctx, query)
+}
+
+// Compile returns the parameterized SELECT statement of the spec on a table in the dialect, and its arguments, for
+// data layers that don't use bun
+func (s QuerySpec) Compile(d dialect.Name, table string) (string, []interface{}, error) {
+	var sd schema.Dialect
+	switch d {
+	case dialect.PG:
+		sd = pgdialect.New()
+	case dialect.MySQL:
+		sd = mysqldialect.New()
+	case dialect.MSSQL:
+		sd = mssqldialect.New()
+	case dialect.SQLite:
+		sd = sqlitedialect.New()
+	default:
+		return "", nil, fmt.Errorf("unsupported dialect '%s'", d)
+	}
+
+	db := bun.NewDB(nil, offlineDialect{sd})
+	query := s.Apply(context.Background(), db.NewSelect().TableExpr("?", bun.Ident(table)))
+	return Parameterized(query)
+}
+
+// offlineDialect is a dialect that doesn't query the database for its version, to render SQL without a database
+type offlineDialect struct {
+	schema.Dialect
+}
+
+// Init does nothing, as there is no database
+func (offlineDialect) Init(*sql.DB) {}
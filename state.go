@@ -0,0 +1,151 @@
+package bunql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fxnoob/bunql/cursor"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/filter"
+	"time"
+)
+
+// stateVersion is the version of the serialized form of the state of a BunQL
+const stateVersion = 1
+
+// state is the serialized form of the state of a BunQL
+type state struct {
+	Version             int                        `json:"version"`
+	Spec                QuerySpec                  `json:"spec"`
+	AllowedFilterFields []string                   `json:"allowedFilterFields,omitempty"`
+	AllowedSortFields   []string                   `json:"allowedSortFields,omitempty"`
+	DeniedFilterFields  []string                   `json:"deniedFilterFields,omitempty"`
+	DeniedSortFields    []string                   `json:"deniedSortFields,omitempty"`
+	Timeout             time.Duration              `json:"timeout,omitempty"`
+	Cursor              *cursor.Cursor             `json:"cursor,omitempty"`
+	Pinned              *dto.PinnedRows            `json:"pinned,omitempty"`
+	FilterOptions       filter.Options             `json:"filterOptions"`
+	Fields              map[string]dto.FieldConfig `json:"fields,omitempty"`
+	MaxPageSize         int                        `json:"maxPageSize,omitempty"`
+	MaxRows             int                        `json:"maxRows,omitempty"`
+	ClampPagination     bool                       `json:"clampPagination,omitempty"`
+	FilterParamLogic    string                     `json:"filterParamLogic,omitempty"`
+	IndexedFields       []string                   `json:"indexedFields,omitempty"`
+	Hint                *Hint                      `json:"hint,omitempty"`
+	WindowColumns       []WindowColumn             `json:"windowColumns,omitempty"`
+	SortAliases         map[string][]dto.SortField `json:"sortAliases,omitempty"`
+	FilterScopes        map[string][]string        `json:"filterScopes,omitempty"`
+	AddSortColumns      bool                       `json:"addSortColumns,omitempty"`
+	CountStrategy       CountStrategy              `json:"countStrategy,omitempty"`
+	ComputedFields      map[string]ComputedField   `json:"computedFields,omitempty"`
+}
+
+// MarshalBinary serializes the state of the BunQL, e.g. for an API node to enqueue an export job that a worker runs
+// later with the exact same query: the parsed filters, sort, pagination, search, cursor and selected fields, and
+// the configuration they are applied with. Hooks, middleware, context filters, plugins, the replica, the caches and
+// the circuit breaker can't be serialized and are left out: the worker sets them up itself
+func (q *BunQL) MarshalBinary() ([]byte, error) {
+	data, err := json.Marshal(state{
+		Version:             stateVersion,
+		Spec:                q.Spec(),
+		AllowedFilterFields: q.AllowedFilterFields,
+		AllowedSortFields:   q.AllowedSortFields,
+		DeniedFilterFields:  q.DeniedFilterFields,
+		DeniedSortFields:    q.DeniedSortFields,
+		Timeout:             q.Timeout,
+		Cursor:              q.Cursor,
+		Pinned:              q.Pinned,
+		FilterOptions:       q.FilterOptions,
+		Fields:              q.Fields,
+		MaxPageSize:         q.MaxPageSize,
+		MaxRows:             q.MaxRows,
+		ClampPagination:     q.ClampPagination,
+		FilterParamLogic:    q.FilterParamLogic,
+		IndexedFields:       q.IndexedFields,
+		Hint:                q.Hint,
+		WindowColumns:       q.WindowColumns,
+		SortAliases:         q.SortAliases,
+		FilterScopes:        q.FilterScopes,
+		AddSortColumns:      q.AddSortColumns,
+		CountStrategy:       q.CountStrategy,
+		ComputedFields:      q.ComputedFields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize query state: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary restores the state serialized by MarshalBinary, replacing the state of the BunQL and keeping the
+// hooks, middleware and other parts that aren't serialized
+func (q *BunQL) UnmarshalBinary(data []byte) error {
+	// Decode numbers as json.Number so that the cursor values, pinned IDs and NULL sort values keep their precision
+	var s state
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&s); err != nil {
+		return fmt.Errorf("failed to deserialize query state: %w", err)
+	}
+	if s.Version != stateVersion {
+		return fmt.Errorf("unsupported query state version %d", s.Version)
+	}
+	stateSortNumbers(s.Spec.Sort)
+	if s.Cursor != nil {
+		s.Cursor.Values = stateNumbers(s.Cursor.Values)
+		stateSortNumbers(s.Cursor.Sort)
+	}
+	if s.Pinned != nil {
+		s.Pinned.IDs = stateNumbers(s.Pinned.IDs)
+	}
+	for _, sortFields := range s.SortAliases {
+		stateSortNumbers(sortFields)
+	}
+	for _, column := range s.WindowColumns {
+		stateSortNumbers(column.OrderBy)
+	}
+
+	q.setSpec(s.Spec)
+	q.AllowedFilterFields = s.AllowedFilterFields
+	q.AllowedSortFields = s.AllowedSortFields
+	q.DeniedFilterFields = s.DeniedFilterFields
+	q.DeniedSortFields = s.DeniedSortFields
+	q.Timeout = s.Timeout
+	q.Cursor = s.Cursor
+	q.Pinned = s.Pinned
+	q.FilterOptions = s.FilterOptions
+	q.Fields = s.Fields
+	q.MaxPageSize = s.MaxPageSize
+	q.MaxRows = s.MaxRows
+	q.ClampPagination = s.ClampPagination
+	q.FilterParamLogic = s.FilterParamLogic
+	q.IndexedFields = s.IndexedFields
+	q.Hint = s.Hint
+	q.WindowColumns = s.WindowColumns
+	q.SortAliases = s.SortAliases
+	q.FilterScopes = s.FilterScopes
+	q.AddSortColumns = s.AddSortColumns
+	q.CountStrategy = s.CountStrategy
+	q.ComputedFields = s.ComputedFields
+	return nil
+}
+
+// stateNumbers converts the json.Number values to int64, or float64 if they aren't integers
+func stateNumbers(values []interface{}) []interface{} {
+	for i, value := range values {
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := number.Float64(); err == nil {
+				values[i] = f
+			}
+		}
+	}
+	return values
+}
+
+// stateSortNumbers converts the json.Number values NULLs are sorted as like stateNumbers
+func stateSortNumbers(sortFields []dto.SortField) {
+	for i, sort := range sortFields {
+		sortFields[i].NullsAs = stateNumbers([]interface{}{sort.NullsAs})[0]
+	}
+}
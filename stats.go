@@ -0,0 +1,129 @@
+package bunql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"math"
+)
+
+// ErrNoValues is returned when the percentiles of a field are requested over rows that have no value for it
+var ErrNoValues = errors.New("no values to compute percentiles of")
+
+// Median returns the median of a numeric field over the rows matched by the filters and search of the BunQL
+func (q *BunQL) Median(ctx context.Context, query *bun.SelectQuery, field string) (float64, error) {
+	values, err := q.Percentiles(ctx, query, field, 0.5)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// Percentiles returns the continuous percentiles of a numeric field, e.g. 0.5 and 0.95 for p50 and p95, over the rows
+// matched by the filters and search of the BunQL, ignoring NULL values. The field is configured by the server and
+// must never come from user input. Postgres computes them with percentile_cont; the other dialects, which lack it as
+// an aggregate, read the sorted values around each percentile and interpolate between them like percentile_cont
+func (q *BunQL) Percentiles(ctx context.Context, query *bun.SelectQuery, field string, percentiles ...float64) ([]float64, error) {
+	for _, p := range percentiles {
+		if p < 0 || p > 1 || math.IsNaN(p) {
+			return nil, fmt.Errorf("percentile %v is out of range [0, 1]", p)
+		}
+	}
+	if len(percentiles) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := q.deadline(ctx)
+	defer cancel()
+
+	// Only the filters apply, through the middleware like for a count query
+	values := q.chain(applyCount)(context.WithValue(ctx, countKey{}, true), q, query).
+		ColumnExpr("? AS stats_value", bun.Ident(field)).
+		Where("? IS NOT NULL", bun.Ident(field))
+	newStats := func() *bun.SelectQuery {
+		return q.onReplica(query.DB().NewSelect().Conn(query.GetConn())).TableExpr("(?) AS stats", values)
+	}
+
+	if q.dialectName(query) == dialect.PG {
+		return pgPercentiles(ctx, newStats(), percentiles)
+	}
+
+	var count int
+	err := budgeted(ctx, func(ctx context.Context) error {
+		return newStats().ColumnExpr("COUNT(*)").Scan(ctx, &count)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count values: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrNoValues
+	}
+
+	results := make([]float64, len(percentiles))
+	for i, p := range percentiles {
+		rank := p * float64(count-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+
+		var bounds []float64
+		err := budgeted(ctx, func(ctx context.Context) (err error) {
+			bounds, err = scanFloats(ctx, newStats().Column("stats_value").OrderExpr("stats_value ASC").
+				Limit(upper-lower+1).Offset(lower))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values: %w", err)
+		}
+		if len(bounds) == 0 {
+			return nil, ErrNoValues
+		}
+		results[i] = bounds[0] + (rank-float64(lower))*(bounds[len(bounds)-1]-bounds[0])
+	}
+	return results, nil
+}
+
+// pgPercentiles computes the percentiles in a single query with percentile_cont
+func pgPercentiles(ctx context.Context, query *bun.SelectQuery, percentiles []float64) ([]float64, error) {
+	values := make([]sql.NullFloat64, len(percentiles))
+	dest := make([]interface{}, len(percentiles))
+	for i, p := range percentiles {
+		query = query.ColumnExpr("percentile_cont(?) WITHIN GROUP (ORDER BY stats_value)", p)
+		dest[i] = &values[i]
+	}
+	err := budgeted(ctx, func(ctx context.Context) error { return query.Scan(ctx, dest...) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute percentiles: %w", err)
+	}
+
+	results := make([]float64, len(percentiles))
+	for i, value := range values {
+		if !value.Valid {
+			return nil, ErrNoValues
+		}
+		results[i] = value.Float64
+	}
+	return results, nil
+}
+
+// scanFloats reads the numbers of the single column of a query through database/sql, which converts the integers of
+// integer columns to float64 where bun does not
+func scanFloats(ctx context.Context, query *bun.SelectQuery) ([]float64, error) {
+	rows, err := query.Rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []float64
+	for rows.Next() {
+		var value float64
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
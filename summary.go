@@ -0,0 +1,36 @@
+package bunql
+
+import (
+	"context"
+	"github.com/fxnoob/bunql/dto"
+)
+
+// summaryKey is the context key of the summary of the BunQL a query was built with
+type summaryKey struct{}
+
+// QuerySummary is the summary of the filters of a query, attached to the context of its execution so that bun query
+// hooks, such as bundebug or telemetry hooks, can tell which API filters produced which SQL
+type QuerySummary struct {
+	Filters     dto.FilterGroup // Normalized filters: lowercase logic and operators, sensitive values redacted
+	Hash        string          // Hash of the filters, the same for equivalent filters, to correlate logs across requests
+	Description string          // Human-readable description of the filters, sort and page, as returned by Describe
+}
+
+// ContextWithSummary returns a context carrying the summary of the BunQL, read by query hooks with
+// SummaryFromContext. ExecutePage, ExecuteCursorPage, Iterate and IterateKeyset execute their queries with it; queries
+// applied with Apply are executed with it by passing it to Scan or Count
+func (q *BunQL) ContextWithSummary(ctx context.Context) context.Context {
+	filters := normalizeFilterGroup(q.RedactedFilters())
+	return context.WithValue(ctx, summaryKey{}, QuerySummary{
+		Filters:     filters,
+		Hash:        filters.Hash(),
+		Description: q.Describe(),
+	})
+}
+
+// SummaryFromContext returns the summary of the BunQL the query executed with the context was built with, and false
+// if the context doesn't carry one
+func SummaryFromContext(ctx context.Context) (QuerySummary, bool) {
+	summary, ok := ctx.Value(summaryKey{}).(QuerySummary)
+	return summary, ok
+}
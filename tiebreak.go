@@ -0,0 +1,79 @@
+package bunql
+
+import (
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/search"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+	"strings"
+)
+
+// tieBreak appends the primary key of the model of the query to sort fields that may be equal on several rows, so
+// that such rows keep the same order from one page to the next instead of straddling page boundaries
+// The sort fields identify a row when they cover the primary key, or a unique constraint on NOT NULL columns, as
+// declared by the bun tags of the model. The primary key is sorted in the direction of the last sort field
+func tieBreak(query *bun.SelectQuery, sortFields []dto.SortField) []dto.SortField {
+	if len(sortFields) == 0 {
+		return sortFields
+	}
+	model, ok := query.GetModel().(bun.TableModel)
+	if !ok || len(model.Table().PKs) == 0 {
+		return sortFields
+	}
+	table := model.Table()
+
+	sorted := map[string]bool{}
+	for _, sort := range sortFields {
+		if sort.Field == search.RelevanceField {
+			continue
+		}
+		if column, ok := columnOf(table, sort.Field); ok {
+			sorted[column] = true
+		}
+	}
+
+	covers := func(fields []*schema.Field) bool {
+		for _, field := range fields {
+			if !sorted[field.Name] {
+				return false
+			}
+		}
+		return true
+	}
+	if covers(table.PKs) {
+		return sortFields
+	}
+	for _, fields := range table.Unique {
+		notNull := true
+		for _, field := range fields {
+			notNull = notNull && field.NotNull
+		}
+		if notNull && covers(fields) {
+			return sortFields
+		}
+	}
+
+	direction := sortFields[len(sortFields)-1].Direction.Normalized()
+	broken := append([]dto.SortField{}, sortFields...)
+	for _, pk := range table.PKs {
+		if !sorted[pk.Name] {
+			broken = append(broken, dto.SortField{Field: pk.Name, Direction: direction})
+		}
+	}
+	return broken
+}
+
+// columnOf returns the column of the table a sort field refers to, unqualified or qualified with the table name or alias
+func columnOf(table *schema.Table, field string) (string, bool) {
+	if qualifier, column, ok := strings.Cut(field, "."); ok {
+		if qualifier != table.Name && qualifier != table.Alias {
+			return "", false
+		}
+		field = column
+	}
+	column, ok := table.FieldMap[field]
+	if !ok {
+		return "", false
+	}
+	return column.Name, true
+}
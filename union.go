@@ -0,0 +1,93 @@
+package bunql
+
+import (
+	"context"
+	"errors"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/pagination"
+	"github.com/fxnoob/bunql/search"
+	"github.com/fxnoob/bunql/sorting"
+	"github.com/uptrace/bun"
+	"strings"
+)
+
+// ErrEmptyUnion is returned for a union of no queries
+var ErrEmptyUnion = errors.New("union has no queries")
+
+// Union is the UNION ALL of the queries of several models filtered by the same BunQL, sorted and paginated as a whole,
+// e.g. a combined activity feed of posts and comments
+type Union struct {
+	ql      *BunQL
+	queries []*bun.SelectQuery
+}
+
+// Union applies the filters and search of the BunQL to each query, through the middleware like for a count query,
+// and combines one or more queries with UNION ALL. The queries must select the same columns, in the same order and
+// with compatible types, e.g. with ColumnExpr("'post' AS kind, id, title, created_at"), and must not be sorted or
+// paginated. The filtered fields must exist in every query, while the sort fields are the columns they select
+// It fails with ErrEmptyUnion if no queries are given
+func (q *BunQL) Union(ctx context.Context, queries ...*bun.SelectQuery) (*Union, error) {
+	if len(queries) == 0 {
+		return nil, ErrEmptyUnion
+	}
+
+	ctx = context.WithValue(ctx, countKey{}, true)
+	filtered := make([]*bun.SelectQuery, len(queries))
+	for i, query := range queries {
+		filtered[i] = q.chain(applyCount)(ctx, q, query)
+	}
+	return &Union{ql: q, queries: filtered}, nil
+}
+
+// Query returns the sorted and paginated union of the queries
+func (u *Union) Query() *bun.SelectQuery {
+	query := u.newSelect()
+
+	// The relevance of the search is computed per query, so it cannot sort the union
+	var sortFields []dto.SortField
+	for _, sort := range sorting.ExpandAliases(u.ql.Sort, u.ql.SortAliases) {
+		if sort.Field != search.RelevanceField {
+			sortFields = append(sortFields, sort)
+		}
+	}
+	query = u.ql.applySort(query, sortFields)
+
+	if u.ql.Pagination != nil {
+		query = pagination.ApplyPagination(query, u.ql.Pagination)
+	}
+	if u.ql.MaxRows > 0 && (u.ql.Pagination == nil || u.ql.Pagination.PageSize <= 0 || u.ql.Pagination.PageSize > u.ql.MaxRows) {
+		query = query.Limit(u.ql.MaxRows)
+	}
+	return query
+}
+
+// CountQuery returns the query counting the rows of the union across all the queries, for the total of the pagination
+func (u *Union) CountQuery() *bun.SelectQuery {
+	return u.newSelect()
+}
+
+// newSelect returns a query selecting from the union of the queries
+func (u *Union) newSelect() *bun.SelectQuery {
+	args := make([]interface{}, 0, len(u.queries)+1)
+	for _, query := range u.queries {
+		args = append(args, query)
+	}
+	args = append(args, bun.Ident("union_rows"))
+
+	first := u.queries[0]
+	union := "(" + strings.Repeat("? UNION ALL ", len(u.queries)-1) + "?) AS ?"
+	return first.DB().NewSelect().Conn(first.GetConn()).TableExpr(union, args...)
+}
+
+// ExecuteUnion executes the sorted and paginated union and its count query, and returns the rows of the page,
+// scanned into T, and the total count across all the queries
+func ExecuteUnion[T any](ctx context.Context, u *Union) ([]T, int, error) {
+	if len(u.queries) == 0 {
+		return nil, 0, ErrEmptyUnion
+	}
+
+	ctx, cancel := u.ql.deadline(ctx)
+	defer cancel()
+
+	return ExecuteWithCount[T](ctx, u.ql.onReplica(u.Query()), u.ql.onReplica(u.CountQuery()))
+}
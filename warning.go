@@ -0,0 +1,84 @@
+package bunql
+
+import (
+	"context"
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/search"
+	"log/slog"
+)
+
+// WarningUnindexedField is the code of the warnings raised when filtering or sorting on a field without an index
+const WarningUnindexedField = "unindexed_field"
+
+// Warning is a potential problem detected while applying a query, such as a filter that scans the whole table
+type Warning struct {
+	Code    string // Kind of problem, e.g. WarningUnindexedField
+	Field   string // Field the warning is about
+	Message string // Human-readable description
+}
+
+// WithIndexedFields declares the fields backed by an index
+// Filtering or sorting on any other field raises a WarningUnindexedField warning
+func (q *BunQL) WithIndexedFields(fields ...string) *BunQL {
+	q.IndexedFields = fields
+	return q
+}
+
+// WithWarningHook sets the function receiving the warnings raised while applying the query,
+// e.g. to log them (see LogWarnings) or to count them in metrics
+func (q *BunQL) WithWarningHook(hook func(ctx context.Context, warning Warning)) *BunQL {
+	q.WarningHook = hook
+	return q
+}
+
+// LogWarnings returns a warning hook logging the warnings with the given logger
+func LogWarnings(logger *slog.Logger) func(ctx context.Context, warning Warning) {
+	return func(ctx context.Context, warning Warning) {
+		logger.WarnContext(ctx, warning.Message, "code", warning.Code, "field", warning.Field)
+	}
+}
+
+// warn passes a warning to the warning hook, if any
+func (q *BunQL) warn(ctx context.Context, warning Warning) {
+	if q.WarningHook != nil {
+		q.WarningHook(ctx, warning)
+	}
+}
+
+// warnUnindexedFields raises a warning for every filtered or sorted field that is not indexed
+func (q *BunQL) warnUnindexedFields(ctx context.Context) {
+	if q.WarningHook == nil || len(q.IndexedFields) == 0 {
+		return
+	}
+
+	reported := map[string]bool{}
+	report := func(field, usage string) {
+		if reported[field] || contains(q.IndexedFields, field) {
+			return
+		}
+		reported[field] = true
+		q.warn(ctx, Warning{
+			Code:    WarningUnindexedField,
+			Field:   field,
+			Message: fmt.Sprintf("%s on field '%s', which has no index", usage, field),
+		})
+	}
+
+	var walk func(group dto.FilterGroup)
+	walk = func(group dto.FilterGroup) {
+		for _, filter := range group.Filters {
+			report(filter.Field, "filtering")
+		}
+		for _, nestedGroup := range group.Groups {
+			walk(nestedGroup)
+		}
+	}
+	walk(q.Filters)
+
+	for _, sort := range q.Sort {
+		if sort.Field != search.RelevanceField {
+			report(sort.Field, "sorting")
+		}
+	}
+}
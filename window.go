@@ -0,0 +1,130 @@
+package bunql
+
+import (
+	"fmt"
+	"github.com/fxnoob/bunql/dto"
+	"github.com/fxnoob/bunql/search"
+	"github.com/fxnoob/bunql/sorting"
+	"github.com/uptrace/bun"
+	"slices"
+	"strings"
+)
+
+// WindowFunction is a window function computing a WindowColumn
+type WindowFunction string
+
+const (
+	WindowRowNumber WindowFunction = "row_number" // Position of the row in its partition, from 1
+	WindowRank      WindowFunction = "rank"       // Rank of the row in its partition, with gaps after ties
+	WindowDenseRank WindowFunction = "dense_rank" // Rank of the row in its partition, without gaps after ties
+	WindowLag       WindowFunction = "lag"        // Value of the field Offset rows before the row
+	WindowLead      WindowFunction = "lead"       // Value of the field Offset rows after the row
+)
+
+// WindowColumn is a column computed over the filtered rows with a window function, e.g. the rank of each item within
+// its category. Columns and fields are configured by the server and must never be built from user input
+type WindowColumn struct {
+	Alias       string          // Name of the column in the results, e.g. "category_rank"
+	Function    WindowFunction  // Window function computing the column
+	Field       string          // Field whose value lag and lead return
+	Offset      int             // Number of rows lag and lead look back or ahead; 1 if zero
+	PartitionBy []string        // Fields partitioning the rows; all the rows form a single partition if empty
+	OrderBy     []dto.SortField // Order of the rows in the partitions; the sort of the BunQL if empty
+}
+
+// WithWindowColumns adds columns computed with window functions to the select list, so that ranked listings come from
+// the same filtered query. The selected fields, or else the columns of the model or *, are selected along with them;
+// results are scanned into a model with matching scanonly fields, e.g. `bun:"category_rank,scanonly"`
+func (q *BunQL) WithWindowColumns(columns ...WindowColumn) *BunQL {
+	q.WindowColumns = append(slices.Clip(q.WindowColumns), columns...)
+	return q
+}
+
+// applyWindowColumns adds the window columns to the select list
+func (q *BunQL) applyWindowColumns(query *bun.SelectQuery) *bun.SelectQuery {
+	if len(q.WindowColumns) == 0 {
+		return query
+	}
+
+	// The selected fields are already in the select list
+	switch {
+	case len(q.SelectedFields) > 0:
+	case query.GetModel() != nil:
+		query = query.ColumnExpr("?TableColumns")
+	default:
+		query = query.ColumnExpr("*")
+	}
+
+	for _, column := range q.WindowColumns {
+		call, args, ok := windowCall(column)
+		if !ok {
+			continue
+		}
+		over, overArgs := q.windowOver(column)
+		query = query.ColumnExpr(call+" OVER ("+over+") AS ?", append(append(args, overArgs...), bun.Ident(column.Alias))...)
+	}
+	return query
+}
+
+// validateWindowColumns validates the functions of the window columns, so that a misconfigured column fails the
+// query instead of being left out of the results
+func (q *BunQL) validateWindowColumns() error {
+	for _, column := range q.WindowColumns {
+		if _, _, ok := windowCall(column); !ok {
+			return fmt.Errorf("invalid window function '%s' of column '%s'", column.Function, column.Alias)
+		}
+		if (column.Function == WindowLag || column.Function == WindowLead) && column.Field == "" {
+			return fmt.Errorf("window function '%s' of column '%s' requires a field", column.Function, column.Alias)
+		}
+	}
+	return nil
+}
+
+// windowCall returns the call of the window function of a column, reporting false for unknown functions
+func windowCall(column WindowColumn) (string, []interface{}, bool) {
+	switch column.Function {
+	case WindowRowNumber, WindowRank, WindowDenseRank:
+		return strings.ToUpper(string(column.Function)) + "()", nil, true
+	case WindowLag, WindowLead:
+		offset := column.Offset
+		if offset <= 0 {
+			offset = 1
+		}
+		return strings.ToUpper(string(column.Function)) + "(?, ?)", []interface{}{bun.Ident(column.Field), offset}, true
+	default:
+		return "", nil, false
+	}
+}
+
+// windowOver returns the window definition of a column: its partitions and the order of their rows
+func (q *BunQL) windowOver(column WindowColumn) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if len(column.PartitionBy) > 0 {
+		clauses = append(clauses, "PARTITION BY "+strings.Repeat(", ?", len(column.PartitionBy))[2:])
+		for _, field := range column.PartitionBy {
+			args = append(args, bun.Ident(field))
+		}
+	}
+
+	orderBy := column.OrderBy
+	if len(orderBy) == 0 {
+		// The relevance of the search is not a column
+		for _, sort := range sorting.ExpandAliases(q.Sort, q.SortAliases) {
+			if sort.Field != search.RelevanceField {
+				orderBy = append(orderBy, sort)
+			}
+		}
+	}
+	if len(orderBy) > 0 {
+		terms := make([]string, len(orderBy))
+		for i, sort := range orderBy {
+			terms[i] = "? " + sort.Direction.SQL()
+			args = append(args, bun.Ident(sort.Field))
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(terms, ", "))
+	}
+
+	return strings.Join(clauses, " "), args
+}